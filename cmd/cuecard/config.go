@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/grantcarthew/cuecard/internal/config"
+)
+
+const configUsage = `Usage: cuecard config <command>
+
+Commands:
+  check [flags] [path]  Validate the config file against the schema
+  schema                Print the config schema
+`
+
+const configCheckUsage = `Usage: cuecard config check [flags] [path]
+
+Validates the config file at path, or the one given by --config, against the
+schema. With --profile the profile is checked too.
+
+Flags:
+`
+
+// runConfig handles "cuecard config" and returns the exit code. profile is
+// the one given before the command, if any.
+func runConfig(args []string, profile string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "check":
+		return runConfigCheck(args[1:], profile)
+	case "schema":
+		fmt.Print(config.Schema())
+		return 0
+	case "help", "-h", "--help":
+		fmt.Print(configUsage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown config command: %s\n\n%s", args[0], configUsage)
+		return 2
+	}
+}
+
+func runConfigCheck(args []string, profile string) int {
+	fs := flag.NewFlagSet("config check", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), configCheckUsage)
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "", "config file path (overrides $"+config.EnvConfig+")")
+	profileName := fs.String("profile", profile, "config profile to check")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	var path string
+	switch {
+	case fs.NArg() > 1 || (fs.NArg() == 1 && *configPath != ""):
+		fs.Usage()
+		return 2
+	case fs.NArg() == 1:
+		path = fs.Arg(0)
+	default:
+		if *configPath != "" {
+			if err := config.SetConfigPath(*configPath); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+		}
+		p, err := config.ConfigPath()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		path = p
+	}
+
+	cfg, err := config.LoadFromPath(path)
+	if err == nil && *profileName != "" {
+		_, err = cfg.WithProfile(*profileName)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+
+	fmt.Printf("%s: OK\n", path)
+	return 0
+}
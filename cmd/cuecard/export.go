@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grantcarthew/cuecard/internal/bundle"
+	"github.com/grantcarthew/cuecard/internal/config"
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+const exportUsage = `Usage: cuecard export [flags] <bundle.zip|bundle.json> [prompt...]
+
+Exports prompts to a bundle. Prompts are named by file name or title; with
+no prompts or groups the whole library is exported.
+
+Flags:
+`
+
+// stringList is a flag that can be given more than once
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// runExport handles "cuecard export" and returns the exit code. profile is
+// the one given before the command, if any.
+func runExport(args []string, profile string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), exportUsage)
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "", "config file path (overrides $"+config.EnvConfig+")")
+	profileName := fs.String("profile", profile, "config profile to export from")
+	var groups stringList
+	fs.Var(&groups, "group", "export the prompts in a group (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	out, names := fs.Arg(0), fs.Args()[1:]
+
+	cfg, err := loadProfileConfig(*configPath, *profileName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	prompts, err := prompt.LoadDirectory(cfg.PromptsDir, cfg.GroupFolders)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	selected := bundle.Select(prompts, groups, names)
+	if len(selected) == 0 {
+		fmt.Fprintln(os.Stderr, "no prompts matched")
+		return 1
+	}
+
+	m, err := bundle.ExportFile(out, selected, cfg.PromptsDir, cfg.Profile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Exported %d prompts to %s\n", len(m.Prompts), out)
+	return 0
+}
+
+// loadProfileConfig loads the config at configPath, or the one chosen before
+// the command, with the named profile or the config's own profile applied
+func loadProfileConfig(configPath, profile string) (*config.Config, error) {
+	if configPath != "" {
+		if err := config.SetConfigPath(configPath); err != nil {
+			return nil, err
+		}
+	}
+	base, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if profile == "" {
+		profile = base.Profile
+	}
+	return base.WithProfile(profile)
+}
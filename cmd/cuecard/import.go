@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/grantcarthew/cuecard/internal/config"
+	"github.com/grantcarthew/cuecard/internal/formats"
+	"github.com/grantcarthew/cuecard/internal/importer"
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+const importUsage = `Usage: cuecard import [flags] <file|folder>
+
+Imports prompts into the library, listing what happens to each one. The
+source's format is detected unless --format is given. Actions are skip,
+overwrite, keep-both and merge-tags.
+
+Formats:
+`
+
+// runImport handles "cuecard import" and returns the exit code. profile is
+// the one given before the command, if any.
+func runImport(args []string, profile string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), importUsage)
+		for _, imp := range formats.All() {
+			fmt.Fprintf(fs.Output(), "  %-9s %s\n", imp.ID(), imp.Name())
+		}
+		fmt.Fprint(fs.Output(), "\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "", "config file path (overrides $"+config.EnvConfig+")")
+	profileName := fs.String("profile", profile, "config profile to import into")
+	format := fs.String("format", "", "format of the source (detected by default)")
+	dryRun := fs.Bool("dry-run", false, "show what would be imported without changing anything")
+	onChanged := fs.String("changed", "overwrite", "action for prompts that differ from the library's")
+	onClash := fs.String("clash", "keep-both", "action for prompts whose title clashes")
+	onIdentical := fs.String("identical", "skip", "action for prompts already in the library")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	actions := map[importer.Status]string{
+		importer.Changed:    *onChanged,
+		importer.TitleClash: *onClash,
+		importer.Identical:  *onIdentical,
+	}
+
+	cfg, err := loadProfileConfig(*configPath, *profileName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	library, err := prompt.LoadDirectory(cfg.PromptsDir, cfg.GroupFolders)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	imp, err := importer.Format(fs.Arg(0), *format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	incoming, skipped, err := imp.Import(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Importing %s\n\n", imp.Name())
+
+	plan := importer.NewPlan(incoming, library, cfg.PromptsDir)
+	for status, name := range actions {
+		action, err := importer.ParseAction(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		plan.SetActions(status, action)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tACTION\tTITLE\tSOURCE")
+	for _, item := range plan.Items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", item.Status, item.Action, item.Prompt.Title, item.Source)
+	}
+	tw.Flush()
+	for _, reason := range skipped {
+		fmt.Printf("skipped: %s\n", reason)
+	}
+
+	if *dryRun {
+		fmt.Printf("\n%d new, %d changed, %d title clashes, %d identical (dry run, nothing imported)\n",
+			plan.Count(importer.New), plan.Count(importer.Changed),
+			plan.Count(importer.TitleClash), plan.Count(importer.Identical))
+		return 0
+	}
+
+	result, err := importer.Apply(plan, cfg.PromptsDir, cfg.GroupFolders)
+	created := len(result.Created())
+	fmt.Printf("\n%d created, %d updated, %d skipped\n", created, len(result.Changes)-created, result.Skipped)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -1,13 +1,64 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
+	"os"
 
+	"github.com/grantcarthew/cuecard/internal/config"
 	"github.com/grantcarthew/cuecard/internal/ui"
 )
 
+const usage = `Usage: cuecard [flags] [command]
+
+With no command the app starts. Flags given before a command apply to it.
+
+Commands:
+  config  Check the config file or print its schema
+  export  Export prompts to a bundle
+  import  Import prompts into the library
+
+Flags:
+`
+
 func main() {
-	app := ui.New()
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	configPath := flag.String("config", "", "config file path (overrides $"+config.EnvConfig+")")
+	profile := flag.String("profile", "", "config profile to use")
+	flag.Parse()
+
+	if err := config.SetConfigPath(*configPath); err != nil {
+		log.Fatal(err)
+	}
+
+	// Commands run without starting the GUI
+	if flag.NArg() > 0 {
+		args := flag.Args()[1:]
+		switch flag.Arg(0) {
+		case "config":
+			os.Exit(runConfig(args, *profile))
+		case "export":
+			os.Exit(runExport(args, *profile))
+		case "import":
+			os.Exit(runImport(args, *profile))
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", flag.Arg(0))
+			flag.Usage()
+			os.Exit(2)
+		}
+	}
+
+	if moved, err := config.Migrate(); err != nil {
+		log.Printf("warning: %v", err)
+	} else if moved != "" {
+		log.Printf("moved config to %s", moved)
+	}
+
+	app := ui.New(ui.Options{Profile: *profile})
 	if err := app.Run(); err != nil {
 		log.Fatal(err)
 	}
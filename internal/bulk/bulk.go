@@ -0,0 +1,151 @@
+package bulk
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+// Edit changes a prompt's fields and reports whether anything changed. It
+// must not modify slices it shares with other prompts.
+type Edit func(p *prompt.Prompt) bool
+
+// SetGroup moves prompts to group, or out of any group if it is empty
+func SetGroup(group string) Edit {
+	group = strings.TrimSpace(group)
+	return func(p *prompt.Prompt) bool {
+		if p.Group == group {
+			return false
+		}
+		p.Group = group
+		return true
+	}
+}
+
+// AddTags adds the tags a prompt lacks, ignoring case
+func AddTags(tags []string) Edit {
+	return func(p *prompt.Prompt) bool {
+		merged := slices.Clone(p.Tags)
+		for _, tag := range tags {
+			if !hasTag(merged, tag) {
+				merged = append(merged, tag)
+			}
+		}
+		if len(merged) == len(p.Tags) {
+			return false
+		}
+		p.Tags = merged
+		return true
+	}
+}
+
+// RemoveTags removes the tags, ignoring case
+func RemoveTags(tags []string) Edit {
+	return func(p *prompt.Prompt) bool {
+		kept := slices.DeleteFunc(slices.Clone(p.Tags), func(t string) bool {
+			return hasTag(tags, t)
+		})
+		if len(kept) == len(p.Tags) {
+			return false
+		}
+		p.Tags = kept
+		return true
+	}
+}
+
+// RenameTag renames the tag from to, ignoring case. A prompt that already
+// has to keeps just the one, which merges the two tags.
+func RenameTag(from, to string) Edit {
+	to = strings.TrimSpace(to)
+	return func(p *prompt.Prompt) bool {
+		if !hasTag(p.Tags, from) {
+			return false
+		}
+		var renamed []string
+		for _, tag := range p.Tags {
+			if strings.EqualFold(tag, from) {
+				tag = to
+			}
+			if !hasTag(renamed, tag) {
+				renamed = append(renamed, tag)
+			}
+		}
+		if slices.Equal(renamed, p.Tags) {
+			return false
+		}
+		p.Tags = renamed
+		return true
+	}
+}
+
+// SetFavorite pins or unpins prompts
+func SetFavorite(favorite bool) Edit {
+	return func(p *prompt.Prompt) bool {
+		if p.Favorite == favorite {
+			return false
+		}
+		p.Favorite = favorite
+		return true
+	}
+}
+
+// SetOrder numbers prompts 1, 2, 3... in the order given, for manual
+// sorting. Prompts not among them are left alone.
+func SetOrder(prompts []*prompt.Prompt) Edit {
+	orders := make(map[string]int, len(prompts))
+	for i, p := range prompts {
+		orders[p.FilePath] = i + 1
+	}
+	return func(p *prompt.Prompt) bool {
+		order, ok := orders[p.FilePath]
+		if !ok || p.Order == order {
+			return false
+		}
+		p.Order = order
+		return true
+	}
+}
+
+// Apply makes edit to each prompt's file as it is now, so edits made
+// elsewhere are kept, and returns the prompts it changed. Prompts the edit
+// leaves alone aren't written. It carries on past failures and returns them
+// together.
+func Apply(prompts []*prompt.Prompt, edit Edit) ([]*prompt.Prompt, error) {
+	var changed []*prompt.Prompt
+	var errs []error
+	for _, p := range prompts {
+		if err := p.Reload(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.FileName, err))
+			continue
+		}
+		edited := *p
+		if !edit(&edited) {
+			continue
+		}
+		if err := p.Update(&edited); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.FileName, err))
+			continue
+		}
+		changed = append(changed, p)
+	}
+	return changed, errors.Join(errs...)
+}
+
+// ParseTags splits a comma separated list of tags, dropping empty ones
+func ParseTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" && !hasTag(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// hasTag reports whether tags includes tag, ignoring case
+func hasTag(tags []string, tag string) bool {
+	return slices.ContainsFunc(tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
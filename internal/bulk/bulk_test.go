@@ -0,0 +1,152 @@
+package bulk
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+func loadPrompts(t *testing.T, files map[string]string) []*prompt.Prompt {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prompts, err := prompt.LoadDirectory(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prompts
+}
+
+func TestEdits(t *testing.T) {
+	tests := []struct {
+		name     string
+		edit     Edit
+		in       prompt.Prompt
+		want     prompt.Prompt
+		wantEdit bool
+	}{
+		{"set group", SetGroup(" Coding "), prompt.Prompt{}, prompt.Prompt{Group: "Coding"}, true},
+		{"same group", SetGroup("Coding"), prompt.Prompt{Group: "Coding"}, prompt.Prompt{Group: "Coding"}, false},
+		{"ungroup", SetGroup(""), prompt.Prompt{Group: "Coding"}, prompt.Prompt{}, true},
+		{"add tags", AddTags([]string{"Go", "new"}), prompt.Prompt{Tags: []string{"go"}}, prompt.Prompt{Tags: []string{"go", "new"}}, true},
+		{"add present tags", AddTags([]string{"GO"}), prompt.Prompt{Tags: []string{"go"}}, prompt.Prompt{Tags: []string{"go"}}, false},
+		{"remove tags", RemoveTags([]string{"GO"}), prompt.Prompt{Tags: []string{"go", "x"}}, prompt.Prompt{Tags: []string{"x"}}, true},
+		{"remove absent tags", RemoveTags([]string{"y"}), prompt.Prompt{Tags: []string{"x"}}, prompt.Prompt{Tags: []string{"x"}}, false},
+		{"rename tag", RenameTag("GO", "golang"), prompt.Prompt{Tags: []string{"x", "go"}}, prompt.Prompt{Tags: []string{"x", "golang"}}, true},
+		{"merge tags", RenameTag("go", "Golang"), prompt.Prompt{Tags: []string{"golang", "go"}}, prompt.Prompt{Tags: []string{"golang"}}, true},
+		{"rename case", RenameTag("go", "Go"), prompt.Prompt{Tags: []string{"go"}}, prompt.Prompt{Tags: []string{"Go"}}, true},
+		{"rename absent tag", RenameTag("y", "z"), prompt.Prompt{Tags: []string{"x"}}, prompt.Prompt{Tags: []string{"x"}}, false},
+		{"favorite", SetFavorite(true), prompt.Prompt{}, prompt.Prompt{Favorite: true}, true},
+		{"already favorite", SetFavorite(true), prompt.Prompt{Favorite: true}, prompt.Prompt{Favorite: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := slices.Clone(tt.in.Tags)
+			p := tt.in
+			if got := tt.edit(&p); got != tt.wantEdit {
+				t.Errorf("edit = %v, want %v", got, tt.wantEdit)
+			}
+			if p.Group != tt.want.Group || p.Favorite != tt.want.Favorite || !slices.Equal(p.Tags, tt.want.Tags) {
+				t.Errorf("edited = %+v, want %+v", p, tt.want)
+			}
+			if !slices.Equal(tt.in.Tags, original) {
+				t.Errorf("edit changed the shared tags to %v", tt.in.Tags)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	prompts := loadPrompts(t, map[string]string{
+		"a.md": "---\ntitle: A\n---\n\nBody A",
+		"b.md": "---\ntitle: B\ngroup: Coding\n---\n\nBody B",
+		"c.md": "---\ntitle: C\n---\n\nBody C",
+	})
+
+	// An edit made elsewhere is kept
+	if err := os.WriteFile(prompts[2].FilePath, []byte("---\ntitle: C\n---\n\nEdited elsewhere"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := Apply(prompts, SetGroup("Coding"))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	var titles []string
+	for _, p := range changed {
+		titles = append(titles, p.Title)
+	}
+	if !slices.Equal(titles, []string{"A", "C"}) {
+		t.Errorf("changed = %v, want A and C", titles)
+	}
+
+	c, err := prompt.LoadFile(prompts[2].FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Group != "Coding" || c.Content != "Edited elsewhere" {
+		t.Errorf("c = %+v, want the group set on the edited file", c)
+	}
+}
+
+func TestApply_CarriesOn(t *testing.T) {
+	prompts := loadPrompts(t, map[string]string{
+		"a.md": "---\ntitle: A\n---\n\nBody A",
+		"b.md": "---\ntitle: B\n---\n\nBody B",
+	})
+	if err := os.Remove(prompts[0].FilePath); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := Apply(prompts, AddTags([]string{"x"}))
+	if err == nil || !strings.Contains(err.Error(), "a.md") || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Apply() error = %v, want a.md not found", err)
+	}
+	if len(changed) != 1 || changed[0].Title != "B" {
+		t.Errorf("changed = %v, want B", changed)
+	}
+}
+
+func TestSetOrder(t *testing.T) {
+	prompts := loadPrompts(t, map[string]string{
+		"a.md": "---\ntitle: A\norder: 2\n---\n\nBody A",
+		"b.md": "---\ntitle: B\n---\n\nBody B",
+		"c.md": "---\ntitle: C\n---\n\nBody C",
+	})
+	a, b, c := prompts[0], prompts[1], prompts[2]
+
+	changed, err := Apply(prompts, SetOrder([]*prompt.Prompt{c, a}))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(changed) != 1 || changed[0] != c {
+		t.Errorf("changed = %v, want only C", changed)
+	}
+	for _, tt := range []struct {
+		p    *prompt.Prompt
+		want int
+	}{{a, 2}, {b, 0}, {c, 1}} {
+		loaded, err := prompt.LoadFile(tt.p.FilePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded.Order != tt.want {
+			t.Errorf("%s order = %d, want %d", loaded.Title, loaded.Order, tt.want)
+		}
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	if got := ParseTags(" go, review,,Go , docs "); !slices.Equal(got, []string{"go", "review", "docs"}) {
+		t.Errorf("ParseTags() = %v", got)
+	}
+}
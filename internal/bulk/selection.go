@@ -0,0 +1,105 @@
+package bulk
+
+import (
+	"slices"
+
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+// Selection is a set of selected prompts. Prompts are kept by file path, so
+// a selection survives the library reloading.
+type Selection struct {
+	paths  map[string]bool
+	anchor string // the last prompt clicked, where a range starts
+}
+
+// Toggle selects or deselects the prompt at path, as a Ctrl-click does
+func (s *Selection) Toggle(path string) {
+	if s.paths == nil {
+		s.paths = make(map[string]bool)
+	}
+	if s.paths[path] {
+		delete(s.paths, path)
+	} else {
+		s.paths[path] = true
+	}
+	s.anchor = path
+}
+
+// Extend selects every prompt in order from the last one clicked to path,
+// as a Shift-click does. order is the paths in the order they are shown.
+// Without an earlier click only path is selected.
+func (s *Selection) Extend(order []string, path string) {
+	from := slices.Index(order, s.anchor)
+	to := slices.Index(order, path)
+	if from < 0 || to < 0 {
+		s.Toggle(path)
+		return
+	}
+	if from > to {
+		from, to = to, from
+	}
+	if s.paths == nil {
+		s.paths = make(map[string]bool)
+	}
+	for _, p := range order[from : to+1] {
+		s.paths[p] = true
+	}
+}
+
+// Set replaces the selection with paths
+func (s *Selection) Set(paths []string) {
+	s.paths = make(map[string]bool, len(paths))
+	for _, p := range paths {
+		s.paths[p] = true
+	}
+	s.anchor = ""
+}
+
+// Clear deselects everything
+func (s *Selection) Clear() {
+	s.Set(nil)
+}
+
+// Rename keeps the prompt at oldPath selected after its file moves to
+// newPath
+func (s *Selection) Rename(oldPath, newPath string) {
+	if s.anchor == oldPath {
+		s.anchor = newPath
+	}
+	if !s.paths[oldPath] {
+		return
+	}
+	delete(s.paths, oldPath)
+	s.paths[newPath] = true
+}
+
+// Has reports whether the prompt at path is selected
+func (s *Selection) Has(path string) bool {
+	return s.paths[path]
+}
+
+// Len returns the number of selected prompts
+func (s *Selection) Len() int {
+	return len(s.paths)
+}
+
+// Prompts returns the selected prompts among prompts, in their order.
+// Selected paths that are no longer among them are dropped, such as
+// prompts deleted outside Cuecard.
+func (s *Selection) Prompts(prompts []*prompt.Prompt) []*prompt.Prompt {
+	var selected []*prompt.Prompt
+	present := make(map[string]bool, len(s.paths))
+	for _, p := range prompts {
+		if s.paths[p.FilePath] {
+			selected = append(selected, p)
+			present[p.FilePath] = true
+		}
+	}
+	for path := range s.paths {
+		if !present[path] {
+			delete(s.paths, path)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,70 @@
+package bulk
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+func TestSelection(t *testing.T) {
+	order := []string{"a", "b", "c", "d", "e"}
+	var s Selection
+
+	s.Toggle("b")
+	s.Extend(order, "d")
+	if s.Len() != 3 || !s.Has("b") || !s.Has("c") || !s.Has("d") {
+		t.Errorf("after Shift-click: %v", s.paths)
+	}
+
+	// A range can run backwards from the last click
+	s.Toggle("e")
+	s.Extend(order, "a")
+	if s.Len() != 5 {
+		t.Errorf("backwards range: %v", s.paths)
+	}
+
+	s.Toggle("c")
+	if s.Has("c") || s.Len() != 4 {
+		t.Errorf("Ctrl-click did not deselect: %v", s.paths)
+	}
+
+	s.Clear()
+	if s.Len() != 0 {
+		t.Errorf("Clear() left %v", s.paths)
+	}
+
+	// A moved prompt stays selected, and ranges still start from it
+	s.Toggle("b")
+	s.Rename("b", "moved/b")
+	s.Rename("x", "moved/x")
+	s.Extend([]string{"moved/b", "c"}, "c")
+	if s.Len() != 2 || !s.Has("moved/b") || s.Has("b") || s.Has("moved/x") {
+		t.Errorf("after Rename(): %v", s.paths)
+	}
+
+	s.Clear()
+
+	// Without an earlier click only the Shift-clicked prompt is selected
+	s.Extend(order, "c")
+	if s.Len() != 1 || !s.Has("c") {
+		t.Errorf("Shift-click without anchor: %v", s.paths)
+	}
+}
+
+func TestSelection_Prompts(t *testing.T) {
+	prompts := []*prompt.Prompt{{Title: "A", FilePath: "a"}, {Title: "B", FilePath: "b"}, {Title: "C", FilePath: "c"}}
+	var s Selection
+	s.Set([]string{"c", "a", "gone"})
+
+	var titles []string
+	for _, p := range s.Prompts(prompts) {
+		titles = append(titles, p.Title)
+	}
+	if !slices.Equal(titles, []string{"A", "C"}) {
+		t.Errorf("Prompts() = %v, want A and C", titles)
+	}
+	if s.Has("gone") || s.Len() != 2 {
+		t.Errorf("missing prompt still selected: %v", s.paths)
+	}
+}
@@ -0,0 +1,309 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+// FormatName identifies a cuecard bundle in its manifest
+const FormatName = "cuecard-bundle"
+
+// Version is the bundle format version written by Export
+const Version = 1
+
+// manifestName is the manifest's name in a zip bundle
+const manifestName = "manifest.json"
+
+// promptsPrefix is the folder holding the prompt files in a zip bundle
+const promptsPrefix = "prompts/"
+
+// ErrUnknownFormat is returned for a bundle path with an unknown extension
+var ErrUnknownFormat = errors.New("unknown bundle format (use .zip or .json)")
+
+// Format is a bundle file format
+type Format int
+
+const (
+	// Zip is a zip archive of prompt files with a manifest.json
+	Zip Format = iota
+	// JSON is a single JSON document holding the manifest and the prompts
+	JSON
+)
+
+// FormatForPath returns the format for a bundle file by its extension
+func FormatForPath(p string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".zip":
+		return Zip, nil
+	case ".json":
+		return JSON, nil
+	default:
+		return 0, ErrUnknownFormat
+	}
+}
+
+// Manifest describes a bundle and its prompts
+type Manifest struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Profile   string    `json:"profile,omitempty"`
+	Prompts   []Entry   `json:"prompts"`
+}
+
+// Entry is a prompt in a bundle. Path is slash-separated and relative to
+// the library root, so group folders are kept. Content is the prompt file;
+// zip bundles store it as a file rather than in the manifest.
+type Entry struct {
+	Path    string `json:"path"`
+	Title   string `json:"title"`
+	Group   string `json:"group,omitempty"`
+	SHA256  string `json:"sha256"`
+	Content string `json:"content,omitempty"`
+}
+
+// Select returns the prompts in any of groups or with any of names, a file
+// name or title, in library order. With no groups or names every prompt is
+// selected.
+func Select(prompts []*prompt.Prompt, groups, names []string) []*prompt.Prompt {
+	if len(groups) == 0 && len(names) == 0 {
+		return prompts
+	}
+	var selected []*prompt.Prompt
+	for _, p := range prompts {
+		if matchAny(p.Group, groups) || matchAny(p.FileName, names) ||
+			matchAny(strings.TrimSuffix(p.FileName, ".md"), names) || matchAny(p.Title, names) {
+			selected = append(selected, p)
+		}
+	}
+	return selected
+}
+
+func matchAny(s string, values []string) bool {
+	for _, v := range values {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Export writes prompts from the library at root to w as a bundle. The
+// favorite flag is personal, so it is cleared as it is on import.
+func Export(w io.Writer, format Format, prompts []*prompt.Prompt, root, profile string) (Manifest, error) {
+	m := Manifest{
+		Format:    FormatName,
+		Version:   Version,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		Profile:   profile,
+	}
+	seen := make(map[string]bool)
+	for _, p := range prompts {
+		exported := *p
+		exported.Favorite = false
+		content := exported.ToMarkdown()
+
+		entry := Entry{
+			Path:    entryPath(p, root),
+			Title:   p.Title,
+			Group:   p.Group,
+			SHA256:  checksum(content),
+			Content: content,
+		}
+		if seen[entry.Path] {
+			return Manifest{}, fmt.Errorf("failed to export bundle: duplicate path %s", entry.Path)
+		}
+		seen[entry.Path] = true
+		m.Prompts = append(m.Prompts, entry)
+	}
+	sort.Slice(m.Prompts, func(i, j int) bool {
+		return m.Prompts[i].Path < m.Prompts[j].Path
+	})
+
+	var err error
+	if format == JSON {
+		err = writeJSON(w, m)
+	} else {
+		err = writeZip(w, m)
+	}
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to export bundle: %w", err)
+	}
+	return m, nil
+}
+
+// ExportFile writes a bundle to path, choosing the format by extension
+func ExportFile(p string, prompts []*prompt.Prompt, root, profile string) (Manifest, error) {
+	format, err := FormatForPath(p)
+	if err != nil {
+		return Manifest{}, err
+	}
+	var buf bytes.Buffer
+	m, err := Export(&buf, format, prompts, root, profile)
+	if err != nil {
+		return Manifest{}, err
+	}
+	if err := prompt.WriteFileAtomic(p, buf.Bytes(), 0644); err != nil {
+		return Manifest{}, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return m, nil
+}
+
+func writeJSON(w io.Writer, m Manifest) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// writeZip writes m's prompts as files beside a manifest without content
+func writeZip(w io.Writer, m Manifest) error {
+	zw := zip.NewWriter(w)
+	manifest := m
+	manifest.Prompts = make([]Entry, len(m.Prompts))
+	for i, e := range m.Prompts {
+		e.Content = ""
+		manifest.Prompts[i] = e
+	}
+	f, err := zw.Create(manifestName)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(f, manifest); err != nil {
+		return err
+	}
+
+	for _, e := range m.Prompts {
+		f, err := zw.Create(promptsPrefix + e.Path)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, e.Content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// Read reads a bundle and verifies its prompts against the manifest
+// checksums. Each returned entry has its Content set.
+func Read(p string) (Manifest, error) {
+	format, err := FormatForPath(p)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	var m Manifest
+	if format == JSON {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if err := json.Unmarshal(data, &m); err != nil {
+			return Manifest{}, fmt.Errorf("failed to parse bundle: %w", err)
+		}
+	} else if m, err = readZip(p); err != nil {
+		return Manifest{}, err
+	}
+
+	if m.Format != FormatName {
+		return Manifest{}, fmt.Errorf("not a cuecard bundle: %s", filepath.Base(p))
+	}
+	if m.Version > Version {
+		return Manifest{}, fmt.Errorf("bundle version %d is newer than supported version %d", m.Version, Version)
+	}
+	for _, e := range m.Prompts {
+		if !validPath(e.Path) {
+			return Manifest{}, fmt.Errorf("invalid prompt path in bundle: %q", e.Path)
+		}
+		if checksum(e.Content) != e.SHA256 {
+			return Manifest{}, fmt.Errorf("checksum mismatch for %s", e.Path)
+		}
+	}
+	return m, nil
+}
+
+func readZip(p string) (Manifest, error) {
+	zr, err := zip.OpenReader(p)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer zr.Close()
+
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	mf, ok := files[manifestName]
+	if !ok {
+		return Manifest{}, fmt.Errorf("not a cuecard bundle: %s has no %s", filepath.Base(p), manifestName)
+	}
+	data, err := readZipFile(mf)
+	if err != nil {
+		return Manifest{}, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Manifest{}, fmt.Errorf("failed to parse bundle manifest: %w", err)
+	}
+
+	for i, e := range m.Prompts {
+		f, ok := files[promptsPrefix+e.Path]
+		if !ok {
+			return Manifest{}, fmt.Errorf("bundle is missing %s", e.Path)
+		}
+		data, err := readZipFile(f)
+		if err != nil {
+			return Manifest{}, err
+		}
+		m.Prompts[i].Content = string(data)
+	}
+	return m, nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from bundle: %w", f.Name, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from bundle: %w", f.Name, err)
+	}
+	return data, nil
+}
+
+// entryPath returns p's slash-separated path relative to root, or its file
+// name if it lies outside root
+func entryPath(p *prompt.Prompt, root string) string {
+	rel, err := filepath.Rel(root, p.FilePath)
+	if err != nil || !validPath(filepath.ToSlash(rel)) {
+		return p.FileName
+	}
+	return filepath.ToSlash(rel)
+}
+
+// validPath reports whether a bundle path stays within the library
+func validPath(p string) bool {
+	return p != "" && !path.IsAbs(p) && !strings.Contains(p, "\\") &&
+		path.Clean(p) == p && p != ".." && !strings.HasPrefix(p, "../")
+}
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
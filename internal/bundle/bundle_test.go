@@ -0,0 +1,162 @@
+package bundle
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+func testPrompts(root string) []*prompt.Prompt {
+	return []*prompt.Prompt{
+		{
+			Title:    "Code Review",
+			Group:    "Coding",
+			Tags:     []string{"review"},
+			Favorite: true,
+			Content:  "Review this code.",
+			FilePath: filepath.Join(root, "coding", "code-review.md"),
+			FileName: "code-review.md",
+		},
+		{
+			Title:    "Summarize",
+			Content:  "Summarize ${INPUT}.",
+			FilePath: filepath.Join(root, "summarize.md"),
+			FileName: "summarize.md",
+		},
+	}
+}
+
+func TestExportAndRead(t *testing.T) {
+	root := t.TempDir()
+	for _, ext := range []string{".zip", ".json"} {
+		t.Run(ext, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bundle"+ext)
+			m, err := ExportFile(path, testPrompts(root), root, "work")
+			if err != nil {
+				t.Fatalf("ExportFile() error = %v", err)
+			}
+			if m.Format != FormatName || m.Version != Version || m.Profile != "work" || len(m.Prompts) != 2 {
+				t.Errorf("manifest = %+v", m)
+			}
+
+			read, err := Read(path)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if len(read.Prompts) != 2 {
+				t.Fatalf("Read() prompts = %+v", read.Prompts)
+			}
+			review := read.Prompts[0]
+			if review.Path != "coding/code-review.md" || review.Title != "Code Review" || review.Group != "Coding" {
+				t.Errorf("entry = %+v", review)
+			}
+			if strings.Contains(review.Content, "favorite") {
+				t.Errorf("exported content keeps favorite:\n%s", review.Content)
+			}
+			p, err := prompt.Parse(review.Content)
+			if err != nil || p.Title != "Code Review" || p.Content != "Review this code." {
+				t.Errorf("exported prompt = %+v, %v", p, err)
+			}
+		})
+	}
+}
+
+func TestExport_ZipManifestHasNoContent(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(t.TempDir(), "bundle.zip")
+	if _, err := ExportFile(path, testPrompts(root), root, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		if f.Name == manifestName {
+			data, _ := readZipFile(f)
+			if strings.Contains(string(data), `"content"`) {
+				t.Errorf("zip manifest includes content:\n%s", data)
+			}
+		}
+	}
+	want := []string{"manifest.json", "prompts/coding/code-review.md", "prompts/summarize.md"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("zip files = %v, want %v", names, want)
+	}
+}
+
+func TestRead_ChecksumMismatch(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	if _, err := ExportFile(path, testPrompts(root), root, ""); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(data), "Review this code.", "Tampered.", 1)
+	if err := os.WriteFile(path, []byte(tampered), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Read(path); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("Read() error = %v, want checksum mismatch", err)
+	}
+}
+
+func TestRead_InvalidPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	content := "x"
+	bundle := `{"format": "cuecard-bundle", "version": 1, "prompts": [` +
+		`{"path": "../escape.md", "title": "X", "sha256": "` + checksum(content) + `", "content": "x"}]}`
+	if err := os.WriteFile(path, []byte(bundle), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(path); err == nil || !strings.Contains(err.Error(), "invalid prompt path") {
+		t.Errorf("Read() error = %v, want invalid path", err)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	prompts := testPrompts(t.TempDir())
+	tests := []struct {
+		name          string
+		groups, names []string
+		want          int
+	}{
+		{"all", nil, nil, 2},
+		{"group", []string{"coding"}, nil, 1},
+		{"file name", nil, []string{"summarize.md"}, 1},
+		{"slug", nil, []string{"summarize"}, 1},
+		{"title", nil, []string{"Code Review"}, 1},
+		{"none", []string{"missing"}, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Select(prompts, tt.groups, tt.names); len(got) != tt.want {
+				t.Errorf("Select() = %d prompts, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatForPath(t *testing.T) {
+	if f, err := FormatForPath("x.ZIP"); err != nil || f != Zip {
+		t.Errorf("FormatForPath(x.ZIP) = %v, %v", f, err)
+	}
+	if f, err := FormatForPath("x.json"); err != nil || f != JSON {
+		t.Errorf("FormatForPath(x.json) = %v, %v", f, err)
+	}
+	if _, err := FormatForPath("x.tar"); err != ErrUnknownFormat {
+		t.Errorf("FormatForPath(x.tar) error = %v, want ErrUnknownFormat", err)
+	}
+}
@@ -2,18 +2,42 @@ package config
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
+	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/cuecontext"
+
+	"github.com/grantcarthew/cuecard/internal/editor"
 )
 
 // Config represents the application configuration
 type Config struct {
 	PromptsDir string       `json:"prompts_dir"`
-	Editor     string       `json:"editor"`
+	Editor     string       `json:"editor"`   // command template, e.g. "code --wait {file}"
+	Terminal   string       `json:"terminal"` // wrapper for terminal editors, e.g. "alacritty -e"
 	Theme      string       `json:"theme"`
+	Hotkey     string       `json:"hotkey"` // e.g. "Ctrl+Shift+Space"
 	Window     WindowConfig `json:"window"`
+	Watch      WatchConfig  `json:"watch"`
+
+	// GroupFolders keeps each prompt in a subfolder named after its group
+	GroupFolders bool `json:"group_folders"`
+
+	// HistoryExternal also records changes made outside Cuecard in prompt
+	// history
+	HistoryExternal bool `json:"history_external"`
+
+	// Variables are custom ${NAME} values substituted into prompts
+	Variables map[string]string `json:"variables"`
+
+	// Profile names the profile applied at startup
+	Profile  string             `json:"profile"`
+	Profiles map[string]Profile `json:"profiles"`
 }
 
 // WindowConfig represents window settings
@@ -23,38 +47,34 @@ type WindowConfig struct {
 	Position string `json:"position"`
 }
 
+// WatchConfig represents prompt directory watching settings
+type WatchConfig struct {
+	Mode         string   `json:"mode"`          // "notify" or "poll"
+	PollInterval string   `json:"poll_interval"` // Go duration, e.g. "2s"
+	Ignore       []string `json:"ignore"`        // Extra file name patterns to ignore
+}
+
+// DefaultPollInterval is used when polling without a configured interval
+const DefaultPollInterval = 2 * time.Second
+
 // DefaultConfig returns a Config with default values
 func DefaultConfig() Config {
 	return Config{
 		PromptsDir: "",
-		Editor:     "code",
+		Editor:     "",
 		Theme:      "system",
 		Window: WindowConfig{
 			Width:    1024,
 			Height:   768,
 			Position: "center",
 		},
+		Watch: WatchConfig{
+			Mode:         "notify",
+			PollInterval: "2s",
+		},
 	}
 }
 
-// ConfigDir returns the path to the config directory
-func ConfigDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
-	}
-	return filepath.Join(home, ".config", "cuecard"), nil
-}
-
-// ConfigPath returns the path to the config file
-func ConfigPath() (string, error) {
-	dir, err := ConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(dir, "config.cue"), nil
-}
-
 // Load reads and parses the configuration file
 func Load() (*Config, error) {
 	path, err := ConfigPath()
@@ -70,20 +90,43 @@ func LoadFromPath(path string) (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
-	return Parse(string(data))
+	cfg, err := parse(path, string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// Relative prompt directories follow the config file, so a portable
+	// setup keeps working wherever it is mounted
+	cfg.resolveRelative(filepath.Dir(path))
+	return cfg, nil
 }
 
 // Parse parses CUE configuration content
 func Parse(content string) (*Config, error) {
+	return parse("config.cue", content)
+}
+
+// parse compiles content, checks it against the #Config schema and decodes
+// it. filename is used in error positions.
+func parse(filename, content string) (*Config, error) {
 	ctx := cuecontext.New()
-	value := ctx.CompileString(content)
+	def, err := schemaDef(ctx)
+	if err != nil {
+		return nil, err
+	}
+	value := ctx.CompileString(content, cue.Filename(filename))
 	if err := value.Err(); err != nil {
-		return nil, fmt.Errorf("failed to parse CUE config: %w", err)
+		return nil, fmt.Errorf("failed to parse CUE config:\n%s", formatCUEError(err, value, def, filename))
+	}
+
+	unified := def.Unify(value)
+	if err := unified.Validate(cue.Concrete(true)); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%s", formatCUEError(err, value, def, filename))
 	}
 
 	cfg := DefaultConfig()
-	if err := value.Decode(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to decode config: %w", err)
+	if err := unified.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode config:\n%s", formatCUEError(err, value, def, filename))
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -93,6 +136,13 @@ func Parse(content string) (*Config, error) {
 	return &cfg, nil
 }
 
+// Check loads the config file at path and reports any problems. It returns
+// nil if the config is valid.
+func Check(path string) error {
+	_, err := LoadFromPath(path)
+	return err
+}
+
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
 	if c.PromptsDir == "" {
@@ -100,13 +150,11 @@ func (c *Config) Validate() error {
 	}
 
 	// Expand home directory if needed
-	if len(c.PromptsDir) > 0 && c.PromptsDir[0] == '~' {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return fmt.Errorf("failed to expand home directory: %w", err)
-		}
-		c.PromptsDir = filepath.Join(home, c.PromptsDir[1:])
+	dir, err := expandHome(c.PromptsDir)
+	if err != nil {
+		return err
 	}
+	c.PromptsDir = dir
 
 	// Validate theme
 	switch c.Theme {
@@ -116,6 +164,16 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid theme: %s (must be light, dark, or system)", c.Theme)
 	}
 
+	if err := validateEditor(c.Editor, c.Terminal); err != nil {
+		return err
+	}
+
+	if c.Hotkey != "" {
+		if _, err := ParseHotkey(c.Hotkey); err != nil {
+			return err
+		}
+	}
+
 	// Validate window position
 	switch c.Window.Position {
 	case "remember", "center", "":
@@ -124,9 +182,103 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid window position: %s (must be remember or center)", c.Window.Position)
 	}
 
+	// Validate watch settings
+	if err := c.Watch.validate(); err != nil {
+		return err
+	}
+
+	for name := range c.Variables {
+		if !validVariableName(name) {
+			return fmt.Errorf("invalid variable name: %s (must be uppercase letters and underscores)", name)
+		}
+	}
+
+	// Validate profiles
+	for name, p := range c.Profiles {
+		if strings.EqualFold(name, BaseProfileName) {
+			return fmt.Errorf("profile %s: the name is reserved for the base config", name)
+		}
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+		c.Profiles[name] = p
+	}
+	if c.Profile != "" {
+		if _, ok := c.Profiles[c.Profile]; !ok {
+			return fmt.Errorf("unknown profile: %s", c.Profile)
+		}
+	}
+
 	return nil
 }
 
+// validate checks the watch mode, poll interval and ignore patterns
+func (w *WatchConfig) validate() error {
+	switch w.Mode {
+	case "notify", "poll", "":
+		// valid
+	default:
+		return fmt.Errorf("invalid watch mode: %s (must be notify or poll)", w.Mode)
+	}
+	if w.PollInterval != "" {
+		d, err := time.ParseDuration(w.PollInterval)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid watch poll_interval: %s (must be a positive duration like \"2s\")", w.PollInterval)
+		}
+	}
+	for _, pattern := range w.Ignore {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid watch ignore pattern: %q", pattern)
+		}
+	}
+	return nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) (string, error) {
+	if len(path) == 0 || path[0] != '~' {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to expand home directory: %w", err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// validateEditor checks that the editor and terminal commands parse
+func validateEditor(editorCmd, terminal string) error {
+	if err := editor.Validate(editorCmd); err != nil {
+		return err
+	}
+	if err := editor.Validate(terminal); err != nil {
+		return fmt.Errorf("invalid terminal: %w", err)
+	}
+	return nil
+}
+
+// validVariableName reports whether name can be used as ${NAME}
+func validVariableName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'A' || r > 'Z') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// PollInterval returns the parsed watch poll interval
+func (c *Config) PollInterval() time.Duration {
+	d, err := time.ParseDuration(c.Watch.PollInterval)
+	if err != nil || d <= 0 {
+		return DefaultPollInterval
+	}
+	return d
+}
+
 // Exists checks if the config file exists
 func Exists() (bool, error) {
 	path, err := ConfigPath()
@@ -152,15 +304,46 @@ func (c *Config) Save() error {
 	return c.SaveToPath(path)
 }
 
-// SaveToPath writes the configuration to a specific path
+// SaveToPath writes the configuration to a specific path. An existing file
+// is updated in place, keeping comments and fields Cuecard doesn't manage.
 func (c *Config) SaveToPath(path string) error {
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	content := c.ToCUE()
-	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+	content := []byte(c.ToCUE())
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		content, err = c.mergeCUE(path, existing)
+		if err != nil {
+			return fmt.Errorf("failed to update config file: %w", err)
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Write to a temp file and rename so the config watcher never sees a
+	// partial file
+	tmp, err := os.CreateTemp(dir, ".config-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 	return nil
@@ -178,8 +361,79 @@ func (c *Config) ToCUE() string {
 `, c.Window.Width, c.Window.Height, c.Window.Position)
 	}
 
+	var watchSection string
+	if c.Watch.Mode != "" || c.Watch.PollInterval != "" || len(c.Watch.Ignore) > 0 {
+		var ignoreLine string
+		if len(c.Watch.Ignore) > 0 {
+			quoted := make([]string, len(c.Watch.Ignore))
+			for i, pattern := range c.Watch.Ignore {
+				quoted[i] = fmt.Sprintf("%q", pattern)
+			}
+			ignoreLine = fmt.Sprintf("\tignore: [%s]\n", strings.Join(quoted, ", "))
+		}
+		watchSection = fmt.Sprintf(`watch: {
+	mode:          %q
+	poll_interval: %q
+%s}
+`, c.Watch.Mode, c.Watch.PollInterval, ignoreLine)
+	}
+
+	var terminalLine string
+	if c.Terminal != "" {
+		terminalLine = fmt.Sprintf("terminal:    %q\n", c.Terminal)
+	}
+
+	var hotkeyLine string
+	if c.Hotkey != "" {
+		hotkeyLine = fmt.Sprintf("hotkey:      %q\n", c.Hotkey)
+	}
+
+	var groupFoldersLine string
+	if c.GroupFolders {
+		groupFoldersLine = "group_folders: true\n"
+	}
+
+	var historyLine string
+	if c.HistoryExternal {
+		historyLine = "history_external: true\n"
+	}
+
+	var profileLine string
+	if c.Profile != "" {
+		profileLine = fmt.Sprintf("profile:     %q\n", c.Profile)
+	}
+
+	var profilesSection string
+	if len(c.Profiles) > 0 {
+		var sb strings.Builder
+		sb.WriteString("profiles: {\n")
+		for _, name := range c.ProfileNames() {
+			sb.WriteString(fmt.Sprintf("\t%q: {\n", name))
+			sb.WriteString(c.Profiles[name].toCUE("\t\t"))
+			sb.WriteString("\t}\n")
+		}
+		sb.WriteString("}\n")
+		profilesSection = sb.String()
+	}
+
 	return fmt.Sprintf(`prompts_dir: %q
 editor:      %q
-theme:       %q
-%s`, c.PromptsDir, c.Editor, c.Theme, windowSection)
+%stheme:       %q
+%s%s%s%s%s%s%s%s`, c.PromptsDir, c.Editor, terminalLine, c.Theme, hotkeyLine, groupFoldersLine, historyLine, profileLine, windowSection, watchSection,
+		variablesToCUE(c.Variables, ""), profilesSection)
+}
+
+// variablesToCUE formats a variables block at the given indent, or returns
+// an empty string if there are no variables
+func variablesToCUE(vars map[string]string, indent string) string {
+	if len(vars) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(indent + "variables: {\n")
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		sb.WriteString(fmt.Sprintf("%s\t%s: %q\n", indent, name, vars[name]))
+	}
+	sb.WriteString(indent + "}\n")
+	return sb.String()
 }
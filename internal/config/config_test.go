@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestParse(t *testing.T) {
@@ -114,8 +115,9 @@ window: {
 func TestDefaultConfig(t *testing.T) {
 	cfg := DefaultConfig()
 
-	if cfg.Editor != "code" {
-		t.Errorf("default Editor = %v, want code", cfg.Editor)
+	// An empty editor falls back to $VISUAL or $EDITOR
+	if cfg.Editor != "" {
+		t.Errorf("default Editor = %q, want empty", cfg.Editor)
 	}
 	if cfg.Theme != "system" {
 		t.Errorf("default Theme = %v, want system", cfg.Theme)
@@ -272,6 +274,75 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid poll watch mode",
+			cfg: Config{
+				PromptsDir: "/home/user/prompts",
+				Watch: WatchConfig{
+					Mode:         "poll",
+					PollInterval: "500ms",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid watch mode",
+			cfg: Config{
+				PromptsDir: "/home/user/prompts",
+				Watch: WatchConfig{
+					Mode: "inotify",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid poll interval",
+			cfg: Config{
+				PromptsDir: "/home/user/prompts",
+				Watch: WatchConfig{
+					Mode:         "poll",
+					PollInterval: "often",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "editor template with terminal",
+			cfg: Config{
+				PromptsDir: "/home/user/prompts",
+				Editor:     "nvim +{line} {file}",
+				Terminal:   "alacritty -e",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unparsable editor command",
+			cfg: Config{
+				PromptsDir: "/home/user/prompts",
+				Editor:     `code "{file}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid ignore pattern",
+			cfg: Config{
+				PromptsDir: "/home/user/prompts",
+				Watch: WatchConfig{
+					Ignore: []string{"[unclosed"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative poll interval",
+			cfg: Config{
+				PromptsDir: "/home/user/prompts",
+				Watch: WatchConfig{
+					PollInterval: "-1s",
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -283,3 +354,54 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestPollInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval string
+		want     time.Duration
+	}{
+		{"configured", "500ms", 500 * time.Millisecond},
+		{"empty uses default", "", DefaultPollInterval},
+		{"invalid uses default", "often", DefaultPollInterval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Watch: WatchConfig{PollInterval: tt.interval}}
+			if got := cfg.PollInterval(); got != tt.want {
+				t.Errorf("PollInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Watch(t *testing.T) {
+	cfg, err := Parse(`prompts_dir: "/home/user/prompts"
+watch: {
+	mode: "poll"
+	poll_interval: "5s"
+	ignore: ["draft-*", "*.bak"]
+}`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if cfg.Watch.Mode != "poll" {
+		t.Errorf("Watch.Mode = %v, want poll", cfg.Watch.Mode)
+	}
+	if cfg.PollInterval() != 5*time.Second {
+		t.Errorf("PollInterval() = %v, want 5s", cfg.PollInterval())
+	}
+
+	// Round-trip through ToCUE
+	parsed, err := Parse(cfg.ToCUE())
+	if err != nil {
+		t.Fatalf("failed to parse generated CUE: %v", err)
+	}
+	if parsed.Watch.Mode != cfg.Watch.Mode || parsed.Watch.PollInterval != cfg.Watch.PollInterval {
+		t.Errorf("round-trip Watch = %+v, want %+v", parsed.Watch, cfg.Watch)
+	}
+	if len(parsed.Watch.Ignore) != 2 || parsed.Watch.Ignore[0] != "draft-*" {
+		t.Errorf("round-trip Watch.Ignore = %v, want [draft-* *.bak]", parsed.Watch.Ignore)
+	}
+}
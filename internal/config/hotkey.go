@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Hotkey is a parsed key combination such as "Ctrl+Shift+Space"
+type Hotkey struct {
+	Ctrl  bool
+	Alt   bool
+	Shift bool
+	Super bool
+	Key   string // "A"-"Z", "0"-"9", "F1"-"F12" or "Space"
+}
+
+// ParseHotkey parses a "+" separated key combination. Modifiers are Ctrl,
+// Alt, Shift and Super (Cmd and Meta are accepted for Super), matched case
+// insensitively, and at least one is required.
+func ParseHotkey(s string) (Hotkey, error) {
+	var hk Hotkey
+	parts := strings.Split(s, "+")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if i == len(parts)-1 {
+			key, ok := normalizeKey(part)
+			if !ok {
+				return Hotkey{}, fmt.Errorf("invalid hotkey %q: unknown key %q", s, part)
+			}
+			hk.Key = key
+			break
+		}
+
+		switch strings.ToLower(part) {
+		case "ctrl", "control":
+			hk.Ctrl = true
+		case "alt", "option":
+			hk.Alt = true
+		case "shift":
+			hk.Shift = true
+		case "super", "cmd", "command", "meta":
+			hk.Super = true
+		default:
+			return Hotkey{}, fmt.Errorf("invalid hotkey %q: unknown modifier %q", s, part)
+		}
+	}
+
+	if !hk.Ctrl && !hk.Alt && !hk.Shift && !hk.Super {
+		return Hotkey{}, fmt.Errorf("invalid hotkey %q: needs at least one modifier", s)
+	}
+	return hk, nil
+}
+
+// String formats the hotkey in canonical form
+func (hk Hotkey) String() string {
+	var parts []string
+	if hk.Ctrl {
+		parts = append(parts, "Ctrl")
+	}
+	if hk.Alt {
+		parts = append(parts, "Alt")
+	}
+	if hk.Shift {
+		parts = append(parts, "Shift")
+	}
+	if hk.Super {
+		parts = append(parts, "Super")
+	}
+	return strings.Join(append(parts, hk.Key), "+")
+}
+
+func normalizeKey(key string) (string, bool) {
+	upper := strings.ToUpper(key)
+	switch {
+	case len(upper) == 1 && (upper[0] >= 'A' && upper[0] <= 'Z' || upper[0] >= '0' && upper[0] <= '9'):
+		return upper, true
+	case upper == "SPACE":
+		return "Space", true
+	case len(upper) >= 2 && upper[0] == 'F' && upper[1] != '0':
+		if n, err := strconv.Atoi(upper[1:]); err == nil && n >= 1 && n <= 12 {
+			return upper, true
+		}
+	}
+	return "", false
+}
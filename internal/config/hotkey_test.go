@@ -0,0 +1,34 @@
+package config
+
+import "testing"
+
+func TestParseHotkey(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "Ctrl+Shift+Space", want: "Ctrl+Shift+Space"},
+		{input: "cmd+alt+p", want: "Alt+Super+P"},
+		{input: "Shift + F12", want: "Shift+F12"},
+		{input: "Control+1", want: "Ctrl+1"},
+		{input: "Space", wantErr: true},
+		{input: "Ctrl+", wantErr: true},
+		{input: "Hyper+A", wantErr: true},
+		{input: "Ctrl+F13", wantErr: true},
+		{input: "Ctrl+F01", wantErr: true},
+		{input: "Ctrl+Enter", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseHotkey(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHotkey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("ParseHotkey() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+)
+
+// mergeCUE updates an existing config file's source with the values in c.
+// Only fields whose value changed are touched, so comments, formatting and
+// fields Cuecard doesn't manage are kept.
+func (c *Config) mergeCUE(filename string, src []byte) ([]byte, error) {
+	f, err := parser.ParseFile(filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CUE config: %w", err)
+	}
+
+	// Compare against the file's current values. If it doesn't load,
+	// every managed field is written.
+	old, err := parse(filename, string(src))
+	all := err != nil
+	if all {
+		old = &Config{}
+	} else {
+		old.resolveRelative(filepath.Dir(filename))
+	}
+
+	m := &merger{decls: &f.Decls, all: all}
+
+	m.str(c.PromptsDir != old.PromptsDir, c.PromptsDir, "prompts_dir")
+	m.str(c.Editor != old.Editor, c.Editor, "editor")
+	m.optStr(c.Terminal != old.Terminal, c.Terminal, "terminal")
+	m.str(c.Theme != old.Theme, c.Theme, "theme")
+	m.optStr(c.Hotkey != old.Hotkey, c.Hotkey, "hotkey")
+	m.optBool(c.GroupFolders != old.GroupFolders, c.GroupFolders, "group_folders")
+	m.optBool(c.HistoryExternal != old.HistoryExternal, c.HistoryExternal, "history_external")
+	m.optStr(c.Profile != old.Profile, c.Profile, "profile")
+
+	m.int(c.Window.Width != old.Window.Width, c.Window.Width, "window", "width")
+	m.int(c.Window.Height != old.Window.Height, c.Window.Height, "window", "height")
+	m.str(c.Window.Position != old.Window.Position, c.Window.Position, "window", "position")
+
+	m.str(c.Watch.Mode != old.Watch.Mode, c.Watch.Mode, "watch", "mode")
+	m.optStr(c.Watch.PollInterval != old.Watch.PollInterval, c.Watch.PollInterval, "watch", "poll_interval")
+	if all || !slices.Equal(c.Watch.Ignore, old.Watch.Ignore) {
+		if len(c.Watch.Ignore) == 0 {
+			m.delete("watch", "ignore")
+		} else {
+			m.set(stringList(c.Watch.Ignore), "watch", "ignore")
+		}
+	}
+
+	for name := range old.Variables {
+		if _, ok := c.Variables[name]; !ok {
+			m.delete("variables", name)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Variables)) {
+		value := c.Variables[name]
+		oldValue, ok := old.Variables[name]
+		m.str(!ok || value != oldValue, value, "variables", name)
+	}
+
+	if all || !reflect.DeepEqual(c.Profiles, old.Profiles) {
+		if len(c.Profiles) == 0 {
+			m.delete("profiles")
+		} else {
+			expr, err := c.profilesExpr()
+			if err != nil {
+				return nil, err
+			}
+			m.set(func() ast.Expr { return expr }, "profiles")
+		}
+	}
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	out, err := format.Node(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format CUE config: %w", err)
+	}
+	return out, nil
+}
+
+// profilesExpr builds the profiles struct from its generated CUE
+func (c *Config) profilesExpr() (ast.Expr, error) {
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	for _, name := range c.ProfileNames() {
+		fmt.Fprintf(&sb, "\t%q: {\n%s\t}\n", name, c.Profiles[name].toCUE("\t\t"))
+	}
+	sb.WriteString("}\n")
+
+	expr, err := parser.ParseExpr("profiles", sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate profiles: %w", err)
+	}
+	return expr, nil
+}
+
+// merger applies field updates to a list of declarations, keeping the first
+// error
+type merger struct {
+	decls *[]ast.Decl
+	all   bool
+	err   error
+}
+
+func (m *merger) str(changed bool, value string, path ...string) {
+	if m.all || changed {
+		m.set(func() ast.Expr { return ast.NewString(value) }, path...)
+	}
+}
+
+// optStr sets an optional string field, removing it when value is empty
+func (m *merger) optStr(changed bool, value string, path ...string) {
+	if !m.all && !changed {
+		return
+	}
+	if value == "" {
+		m.delete(path...)
+		return
+	}
+	m.set(func() ast.Expr { return ast.NewString(value) }, path...)
+}
+
+// optBool sets a boolean field that defaults to false, removing it when false
+func (m *merger) optBool(changed bool, value bool, path ...string) {
+	if !m.all && !changed {
+		return
+	}
+	if !value {
+		m.delete(path...)
+		return
+	}
+	m.set(func() ast.Expr { return ast.NewBool(value) }, path...)
+}
+
+func (m *merger) int(changed bool, value int, path ...string) {
+	if m.all || changed {
+		m.set(func() ast.Expr { return ast.NewLit(token.INT, strconv.Itoa(value)) }, path...)
+	}
+}
+
+func (m *merger) set(value func() ast.Expr, path ...string) {
+	if m.err == nil {
+		m.err = setField(m.decls, path, value)
+	}
+}
+
+func (m *merger) delete(path ...string) {
+	deleteField(m.decls, path)
+}
+
+func stringList(values []string) func() ast.Expr {
+	return func() ast.Expr {
+		exprs := make([]ast.Expr, len(values))
+		for i, v := range values {
+			exprs[i] = ast.NewString(v)
+		}
+		return ast.NewList(exprs...)
+	}
+}
+
+// findFields returns every field in decls with the given label. CUE allows
+// a field to be declared more than once.
+func findFields(decls []ast.Decl, name string) []*ast.Field {
+	var fields []*ast.Field
+	for _, decl := range decls {
+		field, ok := decl.(*ast.Field)
+		if !ok {
+			continue
+		}
+		if label, _, err := ast.LabelName(field.Label); err == nil && label == name {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// hasField reports whether the field at path is declared in decls
+func hasField(decls []ast.Decl, path []string) bool {
+	for _, field := range findFields(decls, path[0]) {
+		if len(path) == 1 {
+			return true
+		}
+		if s, ok := field.Value.(*ast.StructLit); ok && hasField(s.Elts, path[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// setField sets the field at path in decls. Every existing declaration of
+// the field is updated, keeping its comments; a missing field is added to
+// the first enclosing struct.
+func setField(decls *[]ast.Decl, path []string, value func() ast.Expr) error {
+	fields := findFields(*decls, path[0])
+
+	if len(path) == 1 {
+		if len(fields) == 0 {
+			*decls = append(*decls, &ast.Field{Label: ast.NewStringLabel(path[0]), Value: value()})
+			return nil
+		}
+		for _, field := range fields {
+			v := value()
+			ast.SetComments(v, ast.Comments(field.Value))
+			field.Value = v
+		}
+		return nil
+	}
+
+	var structs []*ast.StructLit
+	for _, field := range fields {
+		if s, ok := field.Value.(*ast.StructLit); ok {
+			structs = append(structs, s)
+		}
+	}
+
+	updated := false
+	for _, s := range structs {
+		if hasField(s.Elts, path[1:]) {
+			if err := setField(&s.Elts, path[1:], value); err != nil {
+				return err
+			}
+			updated = true
+		}
+	}
+	switch {
+	case updated:
+		return nil
+	case len(structs) > 0:
+		s := structs[0]
+		// "window: width: 800" has no braces; add them to fit another field
+		if !s.Lbrace.IsValid() {
+			s.Lbrace = token.Blank.Pos()
+			s.Rbrace = token.Newline.Pos()
+			for _, elt := range s.Elts {
+				ast.SetRelPos(elt, token.Newline)
+			}
+		}
+		return setField(&s.Elts, path[1:], value)
+	case len(fields) > 0:
+		return fmt.Errorf("cannot update %s: not a struct", path[0])
+	}
+
+	s := &ast.StructLit{}
+	if err := setField(&s.Elts, path[1:], value); err != nil {
+		return err
+	}
+	*decls = append(*decls, &ast.Field{Label: ast.NewStringLabel(path[0]), Value: s})
+	return nil
+}
+
+// deleteField removes every declaration of the field at path
+func deleteField(decls *[]ast.Decl, path []string) {
+	if len(path) == 1 {
+		*decls = slices.DeleteFunc(*decls, func(decl ast.Decl) bool {
+			field, ok := decl.(*ast.Field)
+			if !ok {
+				return false
+			}
+			label, _, err := ast.LabelName(field.Label)
+			return err == nil && label == path[0]
+		})
+		return
+	}
+
+	for _, field := range findFields(*decls, path[0]) {
+		if s, ok := field.Value.(*ast.StructLit); ok {
+			deleteField(&s.Elts, path[1:])
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveToPath_PreservesComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.cue")
+	writeFile(t, path, `// My cuecard config
+prompts_dir: "~/prompts" // synced with Dropbox
+
+// Editor launched from cards
+editor: "code"
+window: width: 800
+variables: {
+	// Used in signatures
+	AUTHOR: "Jane"
+	OLD:    "remove me"
+}
+`)
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	cfg.Editor = "nvim"
+	cfg.Theme = "dark"
+	cfg.Hotkey = "Ctrl+Shift+Space"
+	cfg.GroupFolders = true
+	cfg.HistoryExternal = true
+	cfg.Window.Height = 600
+	cfg.Variables = map[string]string{"AUTHOR": "Bob", "TEAM": "Platform"}
+
+	if err := cfg.SaveToPath(path); err != nil {
+		t.Fatalf("SaveToPath() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+
+	for _, want := range []string{
+		"// My cuecard config",
+		`prompts_dir: "~/prompts" // synced with Dropbox`,
+		"// Editor launched from cards",
+		`editor: "nvim"`,
+		"// Used in signatures",
+		`theme:`,
+		`"Ctrl+Shift+Space"`,
+		`group_folders:`,
+		`history_external: true`,
+		`TEAM:`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("saved config missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "OLD") {
+		t.Errorf("removed variable still in saved config:\n%s", got)
+	}
+
+	loaded, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("saved config does not load: %v\n%s", err, got)
+	}
+	if loaded.Window.Width != 800 || loaded.Window.Height != 600 {
+		t.Errorf("Window = %+v, want 800x600", loaded.Window)
+	}
+	if loaded.Variables["AUTHOR"] != "Bob" || loaded.Variables["TEAM"] != "Platform" {
+		t.Errorf("Variables = %v", loaded.Variables)
+	}
+	if loaded.Hotkey != "Ctrl+Shift+Space" {
+		t.Errorf("Hotkey = %q, want Ctrl+Shift+Space", loaded.Hotkey)
+	}
+	if !loaded.GroupFolders {
+		t.Error("GroupFolders = false, want true")
+	}
+	if !loaded.HistoryExternal {
+		t.Error("HistoryExternal = false, want true")
+	}
+}
+
+func TestSaveToPath_Unchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.cue")
+	content := `// Only the prompts directory
+prompts_dir: "prompts"
+`
+	writeFile(t, path, content)
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SaveToPath(path); err != nil {
+		t.Fatalf("SaveToPath() error = %v", err)
+	}
+
+	// Defaults and resolved paths are not written back
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Errorf("unchanged config rewritten:\n%s\nwant:\n%s", data, content)
+	}
+}
+
+func TestSaveToPath_RemovesOptionalFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.cue")
+	writeFile(t, path, `prompts_dir: "/home/user/prompts"
+hotkey: "Ctrl+Space"
+`)
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Hotkey = ""
+	if err := cfg.SaveToPath(path); err != nil {
+		t.Fatalf("SaveToPath() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hotkey") {
+		t.Errorf("cleared hotkey still in saved config:\n%s", data)
+	}
+}
+
+func TestSaveToPath_NotAStruct(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.cue")
+	writeFile(t, path, `prompts_dir: "/home/user/prompts"
+_size: {width: 800}
+window: _size
+`)
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Window.Height = 600
+	if err := cfg.SaveToPath(path); err == nil {
+		t.Error("SaveToPath() should fail when window is not a struct literal")
+	}
+}
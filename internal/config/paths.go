@@ -0,0 +1,205 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/grantcarthew/cuecard/internal/fileutil"
+)
+
+// EnvConfig names the environment variable holding an explicit config file
+// path. Data and state are then kept next to the config file, so the whole
+// setup can live on a USB drive.
+const EnvConfig = "CUECARD_CONFIG"
+
+const appName = "cuecard"
+
+var (
+	overrideMu   sync.RWMutex
+	overridePath string
+)
+
+// SetConfigPath sets an explicit config file path, as given by the --config
+// flag. It takes precedence over CUECARD_CONFIG. An empty path clears it.
+func SetConfigPath(path string) error {
+	if path != "" {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve config path: %w", err)
+		}
+		path = abs
+	}
+
+	overrideMu.Lock()
+	defer overrideMu.Unlock()
+	overridePath = path
+	return nil
+}
+
+// portablePath returns the explicit config file path from the --config flag
+// or CUECARD_CONFIG, or "" if neither is set
+func portablePath() (string, error) {
+	overrideMu.RLock()
+	path := overridePath
+	overrideMu.RUnlock()
+	if path != "" {
+		return path, nil
+	}
+
+	path = os.Getenv(EnvConfig)
+	if path == "" {
+		return "", nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", EnvConfig, err)
+	}
+	return abs, nil
+}
+
+// IsPortable reports whether an explicit config path is in use
+func IsPortable() bool {
+	path, err := portablePath()
+	return err == nil && path != ""
+}
+
+// ConfigDir returns the path to the config directory: the directory of an
+// explicit config path, $XDG_CONFIG_HOME/cuecard, or ~/.config/cuecard
+func ConfigDir() (string, error) {
+	path, err := portablePath()
+	if err != nil {
+		return "", err
+	}
+	if path != "" {
+		return filepath.Dir(path), nil
+	}
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// ConfigPath returns the path to the config file
+func ConfigPath() (string, error) {
+	path, err := portablePath()
+	if err != nil {
+		return "", err
+	}
+	if path != "" {
+		return path, nil
+	}
+
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.cue"), nil
+}
+
+// DataDir returns the directory for user data that should be kept, such as
+// the trash: $XDG_DATA_HOME/cuecard or ~/.local/share/cuecard. With an
+// explicit config path it is the "data" directory beside the config file.
+func DataDir() (string, error) {
+	return portableOrXDG("data", "XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// StateDir returns the directory for state that can be lost without harm,
+// such as window geometry and prompt history: $XDG_STATE_HOME/cuecard or
+// ~/.local/state/cuecard. With an explicit config path it is the "state"
+// directory beside the config file.
+func StateDir() (string, error) {
+	return portableOrXDG("state", "XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+func portableOrXDG(name, env, fallback string) (string, error) {
+	path, err := portablePath()
+	if err != nil {
+		return "", err
+	}
+	if path != "" {
+		return filepath.Join(filepath.Dir(path), name), nil
+	}
+	return xdgDir(env, fallback)
+}
+
+// xdgDir returns the cuecard directory under the base directory named by env,
+// or under fallback in the home directory. Relative values are ignored, as
+// the XDG spec requires.
+func xdgDir(env, fallback string) (string, error) {
+	if base := os.Getenv(env); filepath.IsAbs(base) {
+		return filepath.Join(base, appName), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, fallback, appName), nil
+}
+
+// legacyConfigDir returns the config directory used before XDG support
+func legacyConfigDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", appName), nil
+}
+
+// Migrate moves a config file from the legacy ~/.config/cuecard location to
+// the XDG config directory when XDG_CONFIG_HOME points elsewhere. It does
+// nothing with an explicit config path or if the new file already exists,
+// and returns the new path if a file was moved.
+func Migrate() (string, error) {
+	if IsPortable() {
+		return "", nil
+	}
+
+	legacyDir, err := legacyConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if filepath.Clean(legacyDir) == filepath.Clean(dir) {
+		return "", nil
+	}
+
+	from := filepath.Join(legacyDir, "config.cue")
+	to := filepath.Join(dir, "config.cue")
+	if _, err := os.Stat(from); err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to check legacy config: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	err = fileutil.MoveFile(from, to)
+	if errors.Is(err, fileutil.ErrFileExists) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to migrate config to %s: %w", to, err)
+	}
+
+	// Leave the legacy directory alone if anything else lives there
+	_ = os.Remove(legacyDir)
+	return to, nil
+}
+
+// resolveRelative makes relative prompt directories relative to dir
+func (c *Config) resolveRelative(dir string) {
+	if c.PromptsDir != "" && !filepath.IsAbs(c.PromptsDir) {
+		c.PromptsDir = filepath.Join(dir, c.PromptsDir)
+	}
+	for name, p := range c.Profiles {
+		if p.PromptsDir != "" && !filepath.IsAbs(p.PromptsDir) {
+			p.PromptsDir = filepath.Join(dir, p.PromptsDir)
+			c.Profiles[name] = p
+		}
+	}
+}
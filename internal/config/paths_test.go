@@ -0,0 +1,255 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupPathEnv isolates path resolution from the real environment
+func setupPathEnv(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv(EnvConfig, "")
+	t.Cleanup(func() { SetConfigPath("") })
+	return home
+}
+
+func TestPaths(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		flag       string
+		wantConfig string
+		wantData   string
+		wantState  string
+	}{
+		{
+			name:       "home defaults",
+			wantConfig: "HOME/.config/cuecard/config.cue",
+			wantData:   "HOME/.local/share/cuecard",
+			wantState:  "HOME/.local/state/cuecard",
+		},
+		{
+			name: "XDG base directories",
+			env: map[string]string{
+				"XDG_CONFIG_HOME": "/xdg/config",
+				"XDG_DATA_HOME":   "/xdg/data",
+				"XDG_STATE_HOME":  "/xdg/state",
+			},
+			wantConfig: "/xdg/config/cuecard/config.cue",
+			wantData:   "/xdg/data/cuecard",
+			wantState:  "/xdg/state/cuecard",
+		},
+		{
+			name: "relative XDG values are ignored",
+			env: map[string]string{
+				"XDG_CONFIG_HOME": "relative/config",
+				"XDG_DATA_HOME":   "relative/data",
+				"XDG_STATE_HOME":  "relative/state",
+			},
+			wantConfig: "HOME/.config/cuecard/config.cue",
+			wantData:   "HOME/.local/share/cuecard",
+			wantState:  "HOME/.local/state/cuecard",
+		},
+		{
+			name: "CUECARD_CONFIG overrides XDG",
+			env: map[string]string{
+				EnvConfig:         "/usb/cuecard/config.cue",
+				"XDG_CONFIG_HOME": "/xdg/config",
+				"XDG_DATA_HOME":   "/xdg/data",
+			},
+			wantConfig: "/usb/cuecard/config.cue",
+			wantData:   "/usb/cuecard/data",
+			wantState:  "/usb/cuecard/state",
+		},
+		{
+			name:       "flag overrides CUECARD_CONFIG",
+			env:        map[string]string{EnvConfig: "/usb/cuecard/config.cue"},
+			flag:       "/mnt/portable/my.cue",
+			wantConfig: "/mnt/portable/my.cue",
+			wantData:   "/mnt/portable/data",
+			wantState:  "/mnt/portable/state",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := setupPathEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			if tt.flag != "" {
+				if err := SetConfigPath(tt.flag); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			expand := func(p string) string {
+				if rest, ok := strings.CutPrefix(p, "HOME"); ok {
+					return filepath.Join(home, rest)
+				}
+				return p
+			}
+
+			check := func(name string, fn func() (string, error), want string) {
+				got, err := fn()
+				if err != nil {
+					t.Fatalf("%s() error = %v", name, err)
+				}
+				if got != expand(want) {
+					t.Errorf("%s() = %q, want %q", name, got, expand(want))
+				}
+			}
+			check("ConfigPath", ConfigPath, tt.wantConfig)
+			check("ConfigDir", ConfigDir, filepath.Dir(tt.wantConfig))
+			check("DataDir", DataDir, tt.wantData)
+			check("StateDir", StateDir, tt.wantState)
+		})
+	}
+}
+
+func TestSetConfigPath_Relative(t *testing.T) {
+	setupPathEnv(t)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetConfigPath("portable/config.cue"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(wd, "portable", "config.cue"); got != want {
+		t.Errorf("ConfigPath() = %q, want %q", got, want)
+	}
+	if !IsPortable() {
+		t.Error("IsPortable() = false, want true")
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	home := setupPathEnv(t)
+	xdg := filepath.Join(home, "xdg")
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	legacy := filepath.Join(home, ".config", "cuecard", "config.cue")
+	if err := os.MkdirAll(filepath.Dir(legacy), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := `prompts_dir: "/home/user/prompts"`
+	if err := os.WriteFile(legacy, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	moved, err := Migrate()
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	want := filepath.Join(xdg, "cuecard", "config.cue")
+	if moved != want {
+		t.Errorf("Migrate() = %q, want %q", moved, want)
+	}
+
+	data, err := os.ReadFile(want)
+	if err != nil {
+		t.Fatalf("migrated config not found: %v", err)
+	}
+	if string(data) != content {
+		t.Errorf("migrated content = %q, want %q", data, content)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Error("legacy config still exists after migration")
+	}
+
+	// Running again is a no-op
+	moved, err = Migrate()
+	if err != nil || moved != "" {
+		t.Errorf("second Migrate() = %q, %v, want no-op", moved, err)
+	}
+}
+
+func TestMigrate_Skipped(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, home string)
+	}{
+		{
+			name: "default location unchanged",
+			setup: func(t *testing.T, home string) {
+				t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+			},
+		},
+		{
+			name: "new config already exists",
+			setup: func(t *testing.T, home string) {
+				xdg := filepath.Join(home, "xdg")
+				t.Setenv("XDG_CONFIG_HOME", xdg)
+				writeFile(t, filepath.Join(xdg, "cuecard", "config.cue"), "new")
+			},
+		},
+		{
+			name: "portable config",
+			setup: func(t *testing.T, home string) {
+				t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+				t.Setenv(EnvConfig, filepath.Join(home, "usb", "config.cue"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := setupPathEnv(t)
+			legacy := filepath.Join(home, ".config", "cuecard", "config.cue")
+			writeFile(t, legacy, "legacy")
+			tt.setup(t, home)
+
+			moved, err := Migrate()
+			if err != nil {
+				t.Fatalf("Migrate() error = %v", err)
+			}
+			if moved != "" {
+				t.Errorf("Migrate() moved config to %q, want no-op", moved)
+			}
+			if _, err := os.Stat(legacy); err != nil {
+				t.Errorf("legacy config was touched: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadFromPath_RelativePromptsDir(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.cue")
+	writeFile(t, path, `prompts_dir: "prompts"
+profiles: work: prompts_dir: "work/prompts"`)
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if want := filepath.Join(dir, "prompts"); cfg.PromptsDir != want {
+		t.Errorf("PromptsDir = %q, want %q", cfg.PromptsDir, want)
+	}
+	if want := filepath.Join(dir, "work", "prompts"); cfg.Profiles["work"].PromptsDir != want {
+		t.Errorf("profile PromptsDir = %q, want %q", cfg.Profiles["work"].PromptsDir, want)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// BaseProfileName is how profile switchers show the base config, so no
+// profile may be named it
+const BaseProfileName = "Default"
+
+// Profile overrides base config fields for a work context, such as a
+// client project with its own prompts, variables and editor. Empty fields
+// keep the base value; variables are merged over the base variables.
+type Profile struct {
+	PromptsDir string            `json:"prompts_dir"`
+	Editor     string            `json:"editor"`
+	Terminal   string            `json:"terminal"`
+	Theme      string            `json:"theme"`
+	Watch      WatchConfig       `json:"watch"`
+	Variables  map[string]string `json:"variables"`
+}
+
+// validate checks the profile's fields and expands its prompts directory
+func (p *Profile) validate() error {
+	dir, err := expandHome(p.PromptsDir)
+	if err != nil {
+		return err
+	}
+	p.PromptsDir = dir
+
+	switch p.Theme {
+	case "light", "dark", "system", "":
+		// valid
+	default:
+		return fmt.Errorf("invalid theme: %s (must be light, dark, or system)", p.Theme)
+	}
+
+	if err := validateEditor(p.Editor, p.Terminal); err != nil {
+		return err
+	}
+
+	if err := p.Watch.validate(); err != nil {
+		return err
+	}
+
+	for name := range p.Variables {
+		if !validVariableName(name) {
+			return fmt.Errorf("invalid variable name: %s (must be uppercase letters and underscores)", name)
+		}
+	}
+	return nil
+}
+
+// toCUE formats the profile's overridden fields at the given indent
+func (p Profile) toCUE(indent string) string {
+	var sb strings.Builder
+	field := func(name, value string) {
+		if value != "" {
+			sb.WriteString(fmt.Sprintf("%s%s: %q\n", indent, name, value))
+		}
+	}
+	field("prompts_dir", p.PromptsDir)
+	field("editor", p.Editor)
+	field("terminal", p.Terminal)
+	field("theme", p.Theme)
+	if p.Watch.Mode != "" || p.Watch.PollInterval != "" || len(p.Watch.Ignore) > 0 {
+		sb.WriteString(indent + "watch: {\n")
+		inner := indent + "\t"
+		if p.Watch.Mode != "" {
+			sb.WriteString(fmt.Sprintf("%smode: %q\n", inner, p.Watch.Mode))
+		}
+		if p.Watch.PollInterval != "" {
+			sb.WriteString(fmt.Sprintf("%spoll_interval: %q\n", inner, p.Watch.PollInterval))
+		}
+		if len(p.Watch.Ignore) > 0 {
+			quoted := make([]string, len(p.Watch.Ignore))
+			for i, pattern := range p.Watch.Ignore {
+				quoted[i] = fmt.Sprintf("%q", pattern)
+			}
+			sb.WriteString(fmt.Sprintf("%signore: [%s]\n", inner, strings.Join(quoted, ", ")))
+		}
+		sb.WriteString(indent + "}\n")
+	}
+	sb.WriteString(variablesToCUE(p.Variables, indent))
+	return sb.String()
+}
+
+// ProfileNames returns the configured profile names sorted alphabetically
+func (c *Config) ProfileNames() []string {
+	return slices.Sorted(maps.Keys(c.Profiles))
+}
+
+// WithProfile returns a copy of the config with the named profile applied.
+// An empty name returns a copy of the base config.
+func (c *Config) WithProfile(name string) (*Config, error) {
+	cfg := *c
+	cfg.Variables = maps.Clone(c.Variables)
+	cfg.Watch.Ignore = slices.Clone(c.Watch.Ignore)
+	cfg.Profile = name
+
+	if name == "" {
+		return &cfg, nil
+	}
+
+	p, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile: %s", name)
+	}
+
+	if p.PromptsDir != "" {
+		cfg.PromptsDir = p.PromptsDir
+	}
+	if p.Editor != "" {
+		cfg.Editor = p.Editor
+	}
+	if p.Terminal != "" {
+		cfg.Terminal = p.Terminal
+	}
+	if p.Theme != "" {
+		cfg.Theme = p.Theme
+	}
+	if p.Watch.Mode != "" {
+		cfg.Watch.Mode = p.Watch.Mode
+	}
+	if p.Watch.PollInterval != "" {
+		cfg.Watch.PollInterval = p.Watch.PollInterval
+	}
+	if len(p.Watch.Ignore) > 0 {
+		cfg.Watch.Ignore = append(cfg.Watch.Ignore, p.Watch.Ignore...)
+	}
+	if len(p.Variables) > 0 {
+		if cfg.Variables == nil {
+			cfg.Variables = make(map[string]string, len(p.Variables))
+		}
+		maps.Copy(cfg.Variables, p.Variables)
+	}
+
+	return &cfg, nil
+}
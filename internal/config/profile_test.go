@@ -0,0 +1,198 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const profilesConfig = `prompts_dir: "/home/user/prompts"
+editor: "code"
+theme: "light"
+variables: {
+	COMPANY: "Acme"
+	TEAM: "Platform"
+}
+profile: "client-a"
+profiles: {
+	"client-a": {
+		prompts_dir: "/work/client-a/prompts"
+		editor: "nvim"
+		variables: {
+			COMPANY: "Client A"
+		}
+	}
+	"client-b": {
+		theme: "dark"
+		watch: {
+			mode: "poll"
+			poll_interval: "10s"
+		}
+	}
+}`
+
+func TestParse_Profiles(t *testing.T) {
+	cfg, err := Parse(profilesConfig)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if cfg.Profile != "client-a" {
+		t.Errorf("Profile = %q, want client-a", cfg.Profile)
+	}
+	names := cfg.ProfileNames()
+	if len(names) != 2 || names[0] != "client-a" || names[1] != "client-b" {
+		t.Errorf("ProfileNames() = %v, want [client-a client-b]", names)
+	}
+	if cfg.Variables["TEAM"] != "Platform" {
+		t.Errorf("Variables[TEAM] = %q, want Platform", cfg.Variables["TEAM"])
+	}
+}
+
+func TestWithProfile(t *testing.T) {
+	base, err := Parse(profilesConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		profile    string
+		promptsDir string
+		editor     string
+		theme      string
+		watchMode  string
+		company    string
+	}{
+		{"base", "", "/home/user/prompts", "code", "light", "notify", "Acme"},
+		{"overrides dir, editor and variable", "client-a", "/work/client-a/prompts", "nvim", "light", "notify", "Client A"},
+		{"overrides theme and watch", "client-b", "/home/user/prompts", "code", "dark", "poll", "Acme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := base.WithProfile(tt.profile)
+			if err != nil {
+				t.Fatalf("WithProfile() error = %v", err)
+			}
+			if cfg.Profile != tt.profile {
+				t.Errorf("Profile = %q, want %q", cfg.Profile, tt.profile)
+			}
+			if cfg.PromptsDir != tt.promptsDir {
+				t.Errorf("PromptsDir = %q, want %q", cfg.PromptsDir, tt.promptsDir)
+			}
+			if cfg.Editor != tt.editor {
+				t.Errorf("Editor = %q, want %q", cfg.Editor, tt.editor)
+			}
+			if cfg.Theme != tt.theme {
+				t.Errorf("Theme = %q, want %q", cfg.Theme, tt.theme)
+			}
+			if cfg.Watch.Mode != tt.watchMode {
+				t.Errorf("Watch.Mode = %q, want %q", cfg.Watch.Mode, tt.watchMode)
+			}
+			if cfg.Variables["COMPANY"] != tt.company {
+				t.Errorf("Variables[COMPANY] = %q, want %q", cfg.Variables["COMPANY"], tt.company)
+			}
+			// Base variables not overridden by the profile are kept
+			if cfg.Variables["TEAM"] != "Platform" {
+				t.Errorf("Variables[TEAM] = %q, want Platform", cfg.Variables["TEAM"])
+			}
+		})
+	}
+
+	// Applying a profile must not modify the base config
+	if base.Variables["COMPANY"] != "Acme" || base.PromptsDir != "/home/user/prompts" {
+		t.Error("WithProfile() modified the base config")
+	}
+
+	if _, err := base.WithProfile("missing"); err == nil {
+		t.Error("WithProfile() with unknown profile should return error")
+	}
+}
+
+func TestProfiles_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "unknown active profile",
+			content: `prompts_dir: "/p"
+profile: "missing"`,
+			wantErr: "unknown profile: missing",
+		},
+		{
+			name: "reserved profile name",
+			content: `prompts_dir: "/p"
+profiles: default: theme: "dark"`,
+			wantErr: "profile default: the name is reserved",
+		},
+		{
+			name: "unknown profile field",
+			content: `prompts_dir: "/p"
+profiles: work: prompt_dir: "/w"`,
+			wantErr: "profiles.work.prompt_dir: field not allowed",
+		},
+		{
+			name: "invalid profile theme",
+			content: `prompts_dir: "/p"
+profiles: work: theme: "blue"`,
+			wantErr: "profiles.work.theme",
+		},
+		{
+			name: "lowercase variable name",
+			content: `prompts_dir: "/p"
+variables: company: "Acme"`,
+			wantErr: "variables.company",
+		},
+		{
+			name: "lowercase profile variable name",
+			content: `prompts_dir: "/p"
+profiles: work: variables: team: "x"`,
+			wantErr: "profiles.work.variables.team",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.content)
+			if err == nil {
+				t.Fatal("Parse() expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %q does not contain %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToCUE_Profiles(t *testing.T) {
+	cfg, err := Parse(profilesConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := Parse(cfg.ToCUE())
+	if err != nil {
+		t.Fatalf("failed to parse generated CUE: %v\n%s", err, cfg.ToCUE())
+	}
+
+	if parsed.Profile != cfg.Profile {
+		t.Errorf("round-trip Profile = %q, want %q", parsed.Profile, cfg.Profile)
+	}
+	if len(parsed.Profiles) != 2 {
+		t.Fatalf("round-trip Profiles = %d, want 2", len(parsed.Profiles))
+	}
+	if parsed.Profiles["client-a"].Editor != "nvim" {
+		t.Errorf("round-trip client-a editor = %q, want nvim", parsed.Profiles["client-a"].Editor)
+	}
+	if parsed.Profiles["client-b"].Watch.PollInterval != "10s" {
+		t.Errorf("round-trip client-b poll_interval = %q, want 10s", parsed.Profiles["client-b"].Watch.PollInterval)
+	}
+	if parsed.Profiles["client-a"].Variables["COMPANY"] != "Client A" {
+		t.Errorf("round-trip client-a COMPANY = %q, want Client A", parsed.Profiles["client-a"].Variables["COMPANY"])
+	}
+	if parsed.Variables["TEAM"] != "Platform" {
+		t.Errorf("round-trip TEAM = %q, want Platform", parsed.Variables["TEAM"])
+	}
+}
@@ -0,0 +1,55 @@
+// Schema for config.cue. User config is unified with #Config, so unknown
+// fields are rejected and omitted fields take the defaults below. Empty
+// strings are accepted for enums and mean "use the default".
+
+#Config: {
+	prompts_dir!: string & !=""
+	// Command template with optional {file} and {line} placeholders; empty
+	// uses $VISUAL or $EDITOR
+	editor:       string | *""
+	terminal?:    string
+	theme:        *"system" | "light" | "dark" | ""
+	hotkey?:      string
+	// Keep each prompt in a subfolder named after its group
+	group_folders: bool | *false
+	// Also keep history of prompt changes made outside Cuecard
+	history_external: bool | *false
+	window:       #Window
+	watch:        #Watch
+	variables?:   #Variables
+	profile?:     string
+	profiles?: [string]: #Profile
+}
+
+// Variable names match the ${NAME} syntax used in prompts
+#Variables: [=~"^[A-Z_]+$"]: string
+
+// Profiles override base fields; omitted fields keep the base value
+#Profile: {
+	prompts_dir?: string & !=""
+	editor?:      string
+	terminal?:    string
+	theme?:       "system" | "light" | "dark"
+	watch?:       #WatchOverride
+	variables?:   #Variables
+}
+
+#WatchOverride: {
+	mode?:          "notify" | "poll"
+	poll_interval?: #Duration
+	ignore?: [...string]
+}
+
+#Duration: =~"^(([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)?$"
+
+#Window: {
+	width:    *1024 | int & >0
+	height:   *768 | int & >0
+	position: *"center" | "remember" | ""
+}
+
+#Watch: {
+	mode:           *"notify" | "poll" | ""
+	poll_interval?: #Duration
+	ignore?: [...string]
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+	cueerrors "cuelang.org/go/cue/errors"
+)
+
+// schema defines #Config, which every config file is unified with
+//
+//go:embed schema.cue
+var schema string
+
+// Schema returns the CUE schema that config files are checked against
+func Schema() string {
+	return schema
+}
+
+// schemaDef returns the #Config definition
+func schemaDef(ctx *cue.Context) (cue.Value, error) {
+	def := ctx.CompileString(schema, cue.Filename("schema.cue")).LookupPath(cue.ParsePath("#Config"))
+	if err := def.Err(); err != nil {
+		return cue.Value{}, fmt.Errorf("invalid config schema: %w", err)
+	}
+	return def, nil
+}
+
+// formatHints describe the expected format of pattern-constrained fields
+var formatHints = map[string]string{
+	"watch.poll_interval": `a duration like "2s" or "500ms"`,
+}
+
+// formatCUEError turns CUE errors into one line per problem, each prefixed
+// with its position in the user's file. The messages for fields limited to
+// a set of values, or to a format, are built from the schema def and the
+// user's value rather than from CUE's wording, so they read the same across
+// CUE versions.
+func formatCUEError(err error, user, def cue.Value, filename string) string {
+	type problem struct {
+		labels []string
+		pos    string
+		msgs   []string
+	}
+
+	var order []string
+	problems := make(map[string]*problem)
+
+	for _, e := range cueerrors.Errors(err) {
+		labels := e.Path()
+		if len(labels) > 0 && labels[0] == "#Config" {
+			labels = labels[1:]
+		}
+		path := strings.Join(labels, ".")
+		p, ok := problems[path]
+		if !ok {
+			p = &problem{labels: labels}
+			problems[path] = p
+			order = append(order, path)
+		}
+
+		if p.pos == "" {
+			p.pos = userPosition(e, filename)
+		}
+		format, args := e.Msg()
+		p.msgs = append(p.msgs, fmt.Sprintf(format, args...))
+	}
+
+	var lines []string
+	for _, path := range order {
+		p := problems[path]
+		given := user.LookupPath(labelPath(p.labels))
+
+		var text string
+		allowed := allowedValues(schemaField(def, p.labels))
+		switch {
+		case given.Exists() && len(allowed) > 0:
+			text = fmt.Sprintf("%s: %v is not allowed (must be one of %s)", path, given, strings.Join(allowed, ", "))
+		case given.Exists() && formatHints[path] != "":
+			text = fmt.Sprintf("%s: %v is not %s", path, given, formatHints[path])
+		case len(p.msgs) > 0:
+			text = strings.Join(p.msgs, "; ")
+			if path != "" {
+				text = path + ": " + text
+			}
+		default:
+			text = strings.TrimSpace(cueerrors.Details(err, nil))
+		}
+		if p.pos != "" {
+			text = p.pos + ": " + text
+		}
+		lines = append(lines, "  "+text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// labelPath returns the path made of labels
+func labelPath(labels []string) cue.Path {
+	selectors := make([]cue.Selector, len(labels))
+	for i, l := range labels {
+		selectors[i] = cue.Str(l)
+	}
+	return cue.MakePath(selectors...)
+}
+
+// schemaField returns the schema for the field at labels, following
+// optional fields and pattern constraints such as a profile's fields
+func schemaField(def cue.Value, labels []string) cue.Value {
+	v := def
+	for _, l := range labels {
+		next := v.LookupPath(cue.MakePath(cue.Str(l)))
+		if !next.Exists() {
+			next = v.LookupPath(cue.MakePath(cue.Str(l).Optional()))
+		}
+		if !next.Exists() {
+			next = v.LookupPath(cue.MakePath(cue.AnyString))
+		}
+		if !next.Exists() {
+			return next
+		}
+		v = next
+	}
+	return v
+}
+
+// allowedValues returns the values field allows if it is a disjunction of
+// concrete values, such as an enum. The empty string, which means "use the
+// default", is left out.
+func allowedValues(field cue.Value) []string {
+	if !field.Exists() {
+		return nil
+	}
+	op, args := field.Expr()
+	if op != cue.OrOp {
+		return nil
+	}
+	var allowed []string
+	for _, arg := range args {
+		if !arg.IsConcrete() {
+			return nil
+		}
+		if s, err := arg.String(); err == nil && s == "" {
+			continue
+		}
+		allowed = append(allowed, fmt.Sprint(arg))
+	}
+	return allowed
+}
+// userPosition returns the first position of e inside the user's file
+func userPosition(e cueerrors.Error, filename string) string {
+	positions := append(e.InputPositions(), e.Position())
+	for _, pos := range positions {
+		if pos.IsValid() && pos.Filename() == filename {
+			return fmt.Sprintf("%s:%d:%d", pos.Filename(), pos.Line(), pos.Column())
+		}
+	}
+	return ""
+}
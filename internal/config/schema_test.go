@@ -0,0 +1,148 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr []string // substrings expected in the error
+	}{
+		{
+			name: "valid full config",
+			content: `prompts_dir: "/home/user/prompts"
+editor: "nvim"
+theme: "dark"
+window: {
+	width: 800
+	height: 600
+	position: "remember"
+}
+watch: {
+	mode: "poll"
+	poll_interval: "1m30s"
+	ignore: ["*.bak"]
+}`,
+		},
+		{
+			name: "unknown top-level field",
+			content: `prompts_dir: "/home/user/prompts"
+prompt_dir: "/home/user/other"`,
+			wantErr: []string{"config.cue:2:1", "prompt_dir: field not allowed"},
+		},
+		{
+			name: "unknown nested field",
+			content: `prompts_dir: "/home/user/prompts"
+window: {
+	widht: 800
+}`,
+			wantErr: []string{"config.cue:3:2", "window.widht: field not allowed"},
+		},
+		{
+			name: "theme enum",
+			content: `prompts_dir: "/home/user/prompts"
+theme: "blue"`,
+			wantErr: []string{"config.cue:2:8", `theme: "blue" is not allowed`, `"dark"`, `"light"`, `"system"`},
+		},
+		{
+			name: "position enum",
+			content: `prompts_dir: "/home/user/prompts"
+window: position: "top"`,
+			wantErr: []string{"config.cue:2:19", `window.position: "top" is not allowed`, `"center"`, `"remember"`},
+		},
+		{
+			name: "watch mode enum",
+			content: `prompts_dir: "/home/user/prompts"
+watch: mode: "inotify"`,
+			wantErr: []string{`watch.mode: "inotify" is not allowed`, `"notify"`, `"poll"`},
+		},
+		{
+			name: "profile theme enum",
+			content: `prompts_dir: "/home/user/prompts"
+profiles: work: theme: "blue"`,
+			wantErr: []string{"config.cue:2:24", `profiles.work.theme: "blue" is not allowed (must be one of "system", "light", "dark")`},
+		},
+		{
+			name: "poll interval format",
+			content: `prompts_dir: "/home/user/prompts"
+watch: poll_interval: "often"`,
+			wantErr: []string{"config.cue:2:23", `"often" is not a duration`},
+		},
+		{
+			name: "wrong type",
+			content: `prompts_dir: "/home/user/prompts"
+window: width: "wide"`,
+			wantErr: []string{"config.cue:2:16", "window.width"},
+		},
+		{
+			name: "non-positive width",
+			content: `prompts_dir: "/home/user/prompts"
+window: width: 0`,
+			wantErr: []string{"window.width"},
+		},
+		{
+			name:    "missing required prompts_dir",
+			content: `editor: "code"`,
+			wantErr: []string{"prompts_dir: field is required"},
+		},
+		{
+			name:    "empty prompts_dir",
+			content: `prompts_dir: ""`,
+			wantErr: []string{"prompts_dir"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.content)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Parse() expected error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err.Error(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.cue")
+	if err := os.WriteFile(valid, []byte(`prompts_dir: "/home/user/prompts"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Check(valid); err != nil {
+		t.Errorf("Check() on valid config error = %v", err)
+	}
+
+	invalid := filepath.Join(dir, "invalid.cue")
+	if err := os.WriteFile(invalid, []byte("prompts_dir: \"/p\"\nthem: \"dark\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := Check(invalid)
+	if err == nil {
+		t.Fatal("Check() on invalid config expected error")
+	}
+	// Positions name the checked file
+	if !strings.Contains(err.Error(), invalid+":2:1") {
+		t.Errorf("error %q does not contain file position", err.Error())
+	}
+
+	if err := Check(filepath.Join(dir, "missing.cue")); err == nil {
+		t.Error("Check() on missing file expected error")
+	}
+}
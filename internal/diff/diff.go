@@ -0,0 +1,142 @@
+package diff
+
+import "strings"
+
+// Op is the kind of change a line represents
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Line is a line of a diff
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Row is a line pair in a side-by-side diff. A row with only one side has an
+// empty Left or Right and the matching HasLeft or HasRight unset.
+type Row struct {
+	Left, Right       string
+	HasLeft, HasRight bool
+	Changed           bool
+}
+
+// Match returns, for each line of a, the index of the matching line of b in
+// their longest common subsequence, or -1
+func Match(a, b []string) []int {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	match := make([]int, len(a))
+	i, j := 0, 0
+	for i < len(a) {
+		switch {
+		case j < len(b) && a[i] == b[j]:
+			match[i] = j
+			i++
+			j++
+		case j < len(b) && lcs[i][j+1] >= lcs[i+1][j]:
+			j++
+		default:
+			match[i] = -1
+			i++
+		}
+	}
+	return match
+}
+
+// Lines returns the line diff that turns a into b
+func Lines(a, b string) []Line {
+	al := splitLines(a)
+	bl := splitLines(b)
+	match := Match(al, bl)
+
+	var lines []Line
+	j := 0
+	for i, text := range al {
+		if match[i] < 0 {
+			lines = append(lines, Line{Op: Delete, Text: text})
+			continue
+		}
+		for ; j < match[i]; j++ {
+			lines = append(lines, Line{Op: Insert, Text: bl[j]})
+		}
+		lines = append(lines, Line{Op: Equal, Text: text})
+		j++
+	}
+	for ; j < len(bl); j++ {
+		lines = append(lines, Line{Op: Insert, Text: bl[j]})
+	}
+	return lines
+}
+
+// SideBySide arranges a line diff in two columns, pairing the deleted and
+// inserted lines of each change so replaced lines sit next to each other
+func SideBySide(lines []Line) []Row {
+	var rows []Row
+	var deleted, inserted []string
+	flush := func() {
+		for k := 0; k < max(len(deleted), len(inserted)); k++ {
+			row := Row{Changed: true}
+			if k < len(deleted) {
+				row.Left, row.HasLeft = deleted[k], true
+			}
+			if k < len(inserted) {
+				row.Right, row.HasRight = inserted[k], true
+			}
+			rows = append(rows, row)
+		}
+		deleted, inserted = nil, nil
+	}
+
+	for _, line := range lines {
+		switch line.Op {
+		case Delete:
+			deleted = append(deleted, line.Text)
+		case Insert:
+			inserted = append(inserted, line.Text)
+		default:
+			flush()
+			rows = append(rows, Row{Left: line.Text, Right: line.Text, HasLeft: true, HasRight: true})
+		}
+	}
+	flush()
+	return rows
+}
+
+// Stats returns the number of inserted and deleted lines
+func Stats(lines []Line) (inserted, deleted int) {
+	for _, line := range lines {
+		switch line.Op {
+		case Insert:
+			inserted++
+		case Delete:
+			deleted++
+		}
+	}
+	return inserted, deleted
+}
+
+// splitLines splits text into lines, ignoring a final newline
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,68 @@
+package diff
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Line
+	}{
+		{
+			name: "equal",
+			a:    "a\nb\n",
+			b:    "a\nb",
+			want: []Line{{Equal, "a"}, {Equal, "b"}},
+		},
+		{
+			name: "replace middle",
+			a:    "a\nb\nc",
+			b:    "a\nB\nc",
+			want: []Line{{Equal, "a"}, {Delete, "b"}, {Insert, "B"}, {Equal, "c"}},
+		},
+		{
+			name: "append and prepend",
+			a:    "b",
+			b:    "a\nb\nc",
+			want: []Line{{Insert, "a"}, {Equal, "b"}, {Insert, "c"}},
+		},
+		{
+			name: "from empty",
+			a:    "",
+			b:    "x",
+			want: []Line{{Insert, "x"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.a, tt.b); !slices.Equal(got, tt.want) {
+				t.Errorf("Lines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSideBySide(t *testing.T) {
+	rows := SideBySide(Lines("a\nb\nc\nd", "a\nB\nC2\nC3\nd"))
+	want := []Row{
+		{Left: "a", Right: "a", HasLeft: true, HasRight: true},
+		{Left: "b", Right: "B", HasLeft: true, HasRight: true, Changed: true},
+		{Left: "c", Right: "C2", HasLeft: true, HasRight: true, Changed: true},
+		{Right: "C3", HasRight: true, Changed: true},
+		{Left: "d", Right: "d", HasLeft: true, HasRight: true},
+	}
+	if !slices.Equal(rows, want) {
+		t.Errorf("SideBySide() = %+v\nwant %+v", rows, want)
+	}
+}
+
+func TestStats(t *testing.T) {
+	ins, del := Stats(Lines("a\nb\nc", "a\nx\ny\nc"))
+	if ins != 2 || del != 1 {
+		t.Errorf("Stats() = +%d -%d, want +2 -1", ins, del)
+	}
+}
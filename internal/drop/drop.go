@@ -0,0 +1,149 @@
+package drop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grantcarthew/cuecard/internal/formats"
+	"github.com/grantcarthew/cuecard/internal/importer"
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+// Import is how a set of dropped paths is imported
+type Import struct {
+	File    string   // a lone markdown or text file, imported on its own
+	Files   []string // several markdown and text files, previewed together
+	Sources []string // folders and files in other formats, each previewed
+	Ignored []string // paths that can't be imported, with the reason
+}
+
+// Sort decides how to import dropped paths, the same way File > Import
+// would. A lone markdown or text file goes through the single file import,
+// which opens the prompt editor for bare text; several are previewed
+// together. Anything else is imported if its format is recognised.
+func Sort(paths []string) Import {
+	var d Import
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		switch {
+		case err != nil:
+			d.Ignored = append(d.Ignored, filepath.Base(path)+": not found")
+		case !info.IsDir() && isPromptFile(path):
+			files = append(files, path)
+		default:
+			if _, err := formats.Detect(path); err != nil {
+				d.Ignored = append(d.Ignored, filepath.Base(path)+": unrecognised format")
+				continue
+			}
+			d.Sources = append(d.Sources, path)
+		}
+	}
+	if len(files) == 1 {
+		d.File = files[0]
+	} else {
+		d.Files = files
+	}
+	return d
+}
+
+// LoadFiles reads several markdown and text files to import, detecting
+// each one's format. Files that fail are skipped with the reason.
+func LoadFiles(paths []string) ([]importer.Incoming, []string) {
+	var incoming []importer.Incoming
+	var skipped []string
+	for _, path := range paths {
+		imp, err := formats.Detect(path)
+		if err != nil {
+			skipped = append(skipped, filepath.Base(path)+": unrecognised format")
+			continue
+		}
+		entries, more, err := imp.Import(path)
+		if err != nil {
+			skipped = append(skipped, filepath.Base(path)+": "+err.Error())
+			continue
+		}
+		incoming = append(incoming, entries...)
+		skipped = append(skipped, more...)
+	}
+	return incoming, skipped
+}
+
+// AssignGroup puts every incoming prompt in group. An empty group leaves
+// them as they are.
+func AssignGroup(incoming []importer.Incoming, group string) {
+	if group == "" {
+		return
+	}
+	for _, in := range incoming {
+		in.Prompt.Group = group
+	}
+}
+
+// FromText turns dropped or pasted text into a prompt. Text with
+// frontmatter is parsed; otherwise it is all content.
+func FromText(text string) *prompt.Prompt {
+	if prompt.HasFrontmatter(text) {
+		if p, err := prompt.Parse(text); err == nil {
+			p.Favorite = false
+			return p
+		}
+	}
+	return &prompt.Prompt{Content: text}
+}
+
+// Rect is an area of the window
+type Rect struct {
+	X, Y, Width, Height float32
+}
+
+// Contains reports whether the point (x, y) is inside r
+func (r Rect) Contains(x, y float32) bool {
+	return x >= r.X && x < r.X+r.Width && y >= r.Y && y < r.Y+r.Height
+}
+
+// Header is a group header in the prompt list and where it is drawn
+type Header struct {
+	Group string
+	Rect
+}
+
+// GroupAt returns the group whose header is under the point (x, y). Headers
+// scrolled out of view can't be dropped on, so the point must also be
+// inside the visible viewport.
+func GroupAt(headers []Header, viewport Rect, x, y float32) (string, bool) {
+	if !viewport.Contains(x, y) {
+		return "", false
+	}
+	for _, h := range headers {
+		if h.Contains(x, y) {
+			return h.Group, true
+		}
+	}
+	return "", false
+}
+
+// CardAt returns the index of the card under the point (x, y), or -1 if
+// there is none in view, such as when a dragged card is let go between
+// cards
+func CardAt(cards []Rect, viewport Rect, x, y float32) int {
+	if !viewport.Contains(x, y) {
+		return -1
+	}
+	for i, r := range cards {
+		if r.Contains(x, y) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isPromptFile reports whether path is a markdown or text file
+func isPromptFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".txt":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package drop
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSort(t *testing.T) {
+	dir := t.TempDir()
+	card := filepath.Join(dir, "card.md")
+	note := filepath.Join(dir, "note.txt")
+	csv := filepath.Join(dir, "prompts.csv")
+	folder := filepath.Join(dir, "library")
+	image := filepath.Join(dir, "photo.png")
+	writeFile(t, card, "---\ntitle: Card\n---\n\nBody")
+	writeFile(t, note, "A note")
+	writeFile(t, csv, "act,prompt\nA,B\n")
+	writeFile(t, filepath.Join(folder, "x.md"), "---\ntitle: X\n---\n\nBody")
+	writeFile(t, image, "png")
+	missing := filepath.Join(dir, "missing.md")
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  Import
+	}{
+		{
+			name:  "one file",
+			paths: []string{note},
+			want:  Import{File: note},
+		},
+		{
+			name:  "several files",
+			paths: []string{card, note},
+			want:  Import{Files: []string{card, note}},
+		},
+		{
+			name:  "sources and ignored",
+			paths: []string{folder, csv, image, missing},
+			want: Import{
+				Sources: []string{folder, csv},
+				Ignored: []string{"photo.png: unrecognised format", "missing.md: not found"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sort(tt.paths)
+			if got.File != tt.want.File || !slices.Equal(got.Files, tt.want.Files) ||
+				!slices.Equal(got.Sources, tt.want.Sources) || !slices.Equal(got.Ignored, tt.want.Ignored) {
+				t.Errorf("Sort() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFiles(t *testing.T) {
+	dir := t.TempDir()
+	card := filepath.Join(dir, "card.md")
+	note := filepath.Join(dir, "code_review.txt")
+	empty := filepath.Join(dir, "empty.txt")
+	writeFile(t, card, "---\ntitle: Card\ngroup: Old\n---\n\nBody")
+	writeFile(t, note, "Review this")
+	writeFile(t, empty, "")
+
+	incoming, skipped := LoadFiles([]string{card, note, empty})
+	var titles []string
+	for _, in := range incoming {
+		titles = append(titles, in.Prompt.Title)
+	}
+	if !slices.Equal(titles, []string{"Card", "Code Review"}) || len(skipped) != 1 {
+		t.Errorf("LoadFiles() titles %v, skipped %v", titles, skipped)
+	}
+
+	AssignGroup(incoming, "Dropped")
+	for _, in := range incoming {
+		if in.Prompt.Group != "Dropped" {
+			t.Errorf("%s group = %q, want Dropped", in.Source, in.Prompt.Group)
+		}
+	}
+}
+
+func TestFromText(t *testing.T) {
+	tests := []struct {
+		text string
+		want prompt.Prompt
+	}{
+		{"Just some text", prompt.Prompt{Content: "Just some text"}},
+		{"---\ntitle: Pasted\nfavorite: true\n---\n\nBody", prompt.Prompt{Title: "Pasted", Content: "Body"}},
+		{"---\nunclosed", prompt.Prompt{Content: "---\nunclosed"}},
+	}
+	for _, tt := range tests {
+		got := FromText(tt.text)
+		if got.Title != tt.want.Title || got.Content != tt.want.Content || got.Favorite {
+			t.Errorf("FromText(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestGroupAt(t *testing.T) {
+	headers := []Header{
+		{Group: "Coding", Rect: Rect{X: 0, Y: 50, Width: 400, Height: 30}},
+		{Group: "Writing", Rect: Rect{X: 0, Y: 300, Width: 400, Height: 30}},
+		{Group: "Hidden", Rect: Rect{X: 0, Y: 10, Width: 400, Height: 30}},
+	}
+	viewport := Rect{X: 0, Y: 40, Width: 400, Height: 400}
+
+	tests := []struct {
+		x, y   float32
+		want   string
+		wantOK bool
+	}{
+		{10, 60, "Coding", true},
+		{399, 329, "Writing", true},
+		{10, 100, "", false},
+		{10, 20, "", false}, // Scrolled under the toolbar
+		{500, 60, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := GroupAt(headers, viewport, tt.x, tt.y)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("GroupAt(%v, %v) = %q, %v, want %q, %v", tt.x, tt.y, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestCardAt(t *testing.T) {
+	cards := []Rect{
+		{X: 0, Y: 40, Width: 250, Height: 120},
+		{X: 260, Y: 40, Width: 250, Height: 120},
+		{X: 0, Y: 170, Width: 250, Height: 120},
+	}
+	viewport := Rect{X: 0, Y: 60, Width: 600, Height: 400}
+
+	tests := []struct {
+		x, y float32
+		want int
+	}{
+		{10, 100, 0},
+		{300, 100, 1},
+		{10, 200, 2},
+		{255, 100, -1}, // Between cards
+		{300, 200, -1},
+		{10, 50, -1}, // Scrolled under the toolbar
+	}
+	for _, tt := range tests {
+		if got := CardAt(cards, viewport, tt.x, tt.y); got != tt.want {
+			t.Errorf("CardAt(%v, %v) = %d, want %d", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package editor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// DefaultEditor is used when neither the config nor $VISUAL or $EDITOR
+// name an editor
+const DefaultEditor = "code"
+
+// Placeholders in editor command templates
+const (
+	FilePlaceholder    = "{file}"
+	LinePlaceholder    = "{line}"
+	CommandPlaceholder = "{cmd}"
+)
+
+// terminalEditors run in a terminal and need a terminal emulator when
+// launched from the GUI
+var terminalEditors = map[string]bool{
+	"vi": true, "vim": true, "nvim": true, "nano": true, "micro": true,
+	"hx": true, "helix": true, "kak": true, "ne": true, "joe": true,
+	"mg": true,
+}
+
+// terminalCandidates are tried in order when a terminal editor is used
+// without a configured terminal
+var terminalCandidates = [][]string{
+	{"x-terminal-emulator", "-e"},
+	{"gnome-terminal", "--"},
+	{"konsole", "-e"},
+	{"kitty"},
+	{"alacritty", "-e"},
+	{"wezterm", "start", "--"},
+	{"foot"},
+	{"xterm", "-e"},
+}
+
+// Editor launches files in a configured editor command
+type Editor struct {
+	template string
+	terminal string
+}
+
+// New creates an Editor from a command template such as "code --wait {file}"
+// or "nvim +{line} {file}", and an optional terminal wrapper such as
+// "alacritty -e". An empty template falls back to $VISUAL, then $EDITOR,
+// then DefaultEditor.
+func New(template, terminal string) *Editor {
+	if strings.TrimSpace(template) == "" {
+		template = Resolve()
+	}
+	return &Editor{template: template, terminal: terminal}
+}
+
+// Resolve returns the editor named by $VISUAL or $EDITOR, or DefaultEditor
+func Resolve() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if v := strings.TrimSpace(os.Getenv(env)); v != "" {
+			return v
+		}
+	}
+	return DefaultEditor
+}
+
+// Validate checks that a command template can be parsed
+func Validate(template string) error {
+	if _, err := splitArgs(template); err != nil {
+		return fmt.Errorf("invalid editor command %q: %w", template, err)
+	}
+	return nil
+}
+
+// Args returns the command line that opens file at line. A line below 1
+// opens the file at its first line. If the template has no {file}
+// placeholder the file is appended.
+func (e *Editor) Args(file string, line int) ([]string, error) {
+	if line < 1 {
+		line = 1
+	}
+
+	args, err := splitArgs(e.template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid editor command %q: %w", e.template, err)
+	}
+	if len(args) == 0 {
+		return nil, errors.New("no editor configured")
+	}
+
+	hasFile := false
+	for i, arg := range args {
+		if strings.Contains(arg, FilePlaceholder) {
+			hasFile = true
+		}
+		arg = strings.ReplaceAll(arg, FilePlaceholder, file)
+		args[i] = strings.ReplaceAll(arg, LinePlaceholder, strconv.Itoa(line))
+	}
+	if !hasFile {
+		args = append(args, file)
+	}
+
+	terminal, err := e.terminalArgs(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return wrap(terminal, args), nil
+}
+
+// terminalArgs returns the terminal wrapper for the editor program, or nil
+// if it doesn't need one
+func (e *Editor) terminalArgs(program string) ([]string, error) {
+	if e.terminal != "" {
+		args, err := splitArgs(e.terminal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid terminal command %q: %w", e.terminal, err)
+		}
+		return args, nil
+	}
+
+	if !terminalEditors[filepath.Base(program)] {
+		return nil, nil
+	}
+
+	if t := strings.TrimSpace(os.Getenv("TERMINAL")); t != "" {
+		if args, err := splitArgs(t); err == nil && len(args) > 0 {
+			// Bare $TERMINAL values need the conventional -e flag
+			if len(args) == 1 {
+				args = append(args, "-e")
+			}
+			return args, nil
+		}
+	}
+	if runtime.GOOS != "darwin" && runtime.GOOS != "windows" {
+		for _, candidate := range terminalCandidates {
+			if _, err := exec.LookPath(candidate[0]); err == nil {
+				return candidate, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%s runs in a terminal: set terminal in config, for example terminal: \"alacritty -e\"", program)
+}
+
+// wrap runs args inside the terminal command, replacing a {cmd} placeholder
+// or appending them
+func wrap(terminal, args []string) []string {
+	if len(terminal) == 0 {
+		return args
+	}
+
+	var result []string
+	replaced := false
+	for _, arg := range terminal {
+		if arg == CommandPlaceholder {
+			result = append(result, args...)
+			replaced = true
+			continue
+		}
+		result = append(result, arg)
+	}
+	if !replaced {
+		result = append(result, args...)
+	}
+	return result
+}
+
+// Open launches the editor on file at line. Errors starting it are returned
+// directly. If the editor later exits with an error, done is called from
+// another goroutine with the error and the editor's output.
+func (e *Editor) Open(file string, line int, done func(error)) error {
+	args, err := e.Args(file, line)
+	if err != nil {
+		return err
+	}
+
+	var output bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start editor %s: %w", args[0], err)
+	}
+
+	go func() {
+		err := cmd.Wait()
+		if err != nil && done != nil {
+			if msg := strings.TrimSpace(output.String()); msg != "" {
+				err = fmt.Errorf("editor %s failed: %w\n%s", args[0], err, lastLines(msg, 5))
+			} else {
+				err = fmt.Errorf("editor %s failed: %w", args[0], err)
+			}
+			done(err)
+		}
+	}()
+	return nil
+}
+
+// lastLines returns at most n trailing lines of s
+func lastLines(s string, n int) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// splitArgs splits a command line into arguments, honouring single and
+// double quotes and backslash escapes outside single quotes
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			if i+1 >= len(runes) {
+				return nil, errors.New("trailing backslash")
+			}
+			i++
+			current.WriteRune(runes[i])
+			inArg = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("unclosed quote")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
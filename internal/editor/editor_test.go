@@ -0,0 +1,211 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{input: "code --wait", want: []string{"code", "--wait"}},
+		{input: "  nvim   +{line}  {file} ", want: []string{"nvim", "+{line}", "{file}"}},
+		{input: `"/Applications/My Editor/bin/edit" -n`, want: []string{"/Applications/My Editor/bin/edit", "-n"}},
+		{input: `'it''s' a\ b`, want: []string{"its", "a b"}},
+		{input: `sh -c "echo \"hi\""`, want: []string{"sh", "-c", `echo "hi"`}},
+		{input: `""`, want: []string{""}},
+		{input: "", want: nil},
+		{input: `code "unclosed`, wantErr: true},
+		{input: `code \`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := splitArgs(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("splitArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgs(t *testing.T) {
+	t.Setenv("TERMINAL", "")
+
+	tests := []struct {
+		name     string
+		template string
+		terminal string
+		line     int
+		want     []string
+	}{
+		{
+			name:     "file appended",
+			template: "code --wait",
+			line:     3,
+			want:     []string{"code", "--wait", "/p/a b.md"},
+		},
+		{
+			name:     "file and line placeholders",
+			template: "code --goto {file}:{line}",
+			line:     7,
+			want:     []string{"code", "--goto", "/p/a b.md:7"},
+		},
+		{
+			name:     "line defaults to 1",
+			template: "subl -n {file}:{line}",
+			want:     []string{"subl", "-n", "/p/a b.md:1"},
+		},
+		{
+			name:     "terminal wrapper appended",
+			template: "nvim +{line} {file}",
+			terminal: "alacritty -e",
+			line:     5,
+			want:     []string{"alacritty", "-e", "nvim", "+5", "/p/a b.md"},
+		},
+		{
+			name:     "terminal wrapper with cmd placeholder",
+			template: "hx {file}:{line}",
+			terminal: "wezterm start --cwd /tmp -- {cmd}",
+			line:     2,
+			want:     []string{"wezterm", "start", "--cwd", "/tmp", "--", "hx", "/p/a b.md:2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.template, tt.terminal).Args("/p/a b.md", tt.line)
+			if err != nil {
+				t.Fatalf("Args() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Args() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name   string
+		visual string
+		editor string
+		want   string
+	}{
+		{"VISUAL wins", "subl -w", "vim", "subl -w"},
+		{"EDITOR fallback", "", "vim", "vim"},
+		{"default", "", "", DefaultEditor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VISUAL", tt.visual)
+			t.Setenv("EDITOR", tt.editor)
+			if got := Resolve(); got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+			if got := New("", "").template; got != tt.want {
+				t.Errorf("New(\"\") template = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgs_TerminalDetection(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("terminal detection is for Linux and BSD")
+	}
+
+	// Only a fake kitty is on PATH
+	bin := t.TempDir()
+	writeExecutable(t, filepath.Join(bin, "kitty"), "#!/bin/sh\n")
+	t.Setenv("PATH", bin)
+	t.Setenv("TERMINAL", "")
+
+	got, err := New("nvim", "").Args("/p/a.md", 1)
+	if err != nil {
+		t.Fatalf("Args() error = %v", err)
+	}
+	if want := []string{"kitty", "nvim", "/p/a.md"}; !slices.Equal(got, want) {
+		t.Errorf("Args() = %q, want %q", got, want)
+	}
+
+	// $TERMINAL takes precedence
+	t.Setenv("TERMINAL", "foot")
+	got, err = New("nvim", "").Args("/p/a.md", 1)
+	if err != nil {
+		t.Fatalf("Args() error = %v", err)
+	}
+	if want := []string{"foot", "-e", "nvim", "/p/a.md"}; !slices.Equal(got, want) {
+		t.Errorf("Args() with $TERMINAL = %q, want %q", got, want)
+	}
+
+	// No terminal at all is an error rather than a silent failure
+	t.Setenv("TERMINAL", "")
+	t.Setenv("PATH", t.TempDir())
+	if _, err := New("vim", "").Args("/p/a.md", 1); err == nil {
+		t.Error("Args() for terminal editor without terminal should return error")
+	}
+
+	// GUI editors are never wrapped
+	got, err = New("code", "").Args("/p/a.md", 1)
+	if err != nil || got[0] != "code" {
+		t.Errorf("Args() for GUI editor = %q, %v", got, err)
+	}
+}
+
+func TestOpen_Errors(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts")
+	}
+
+	// Missing executables fail straight away
+	err := New("cuecard-no-such-editor", "").Open("/p/a.md", 1, nil)
+	if err == nil {
+		t.Error("Open() with missing editor should return error")
+	}
+
+	// Editors that exit with an error report it with their output
+	script := filepath.Join(t.TempDir(), "bad-editor")
+	writeExecutable(t, script, "#!/bin/sh\necho \"cannot open $1\" >&2\nexit 3\n")
+
+	errc := make(chan error, 1)
+	if err := New(script, "").Open("/p/a.md", 1, func(err error) { errc <- err }); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	select {
+	case err := <-errc:
+		if !strings.Contains(err.Error(), "cannot open /p/a.md") {
+			t.Errorf("done error = %v, want editor output", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("done not called for failing editor")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(`code --wait "{file}"`); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := Validate(`code "{file}`); err == nil {
+		t.Error("Validate() of unclosed quote should return error")
+	}
+}
+
+func writeExecutable(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
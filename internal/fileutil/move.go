@@ -0,0 +1,109 @@
+// Package fileutil holds file helpers shared by packages that have no other
+// reason to depend on each other.
+package fileutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// ErrFileExists is returned when a move would replace another file
+var ErrFileExists = errors.New("file already exists")
+
+// link and rename are os.Link and os.Rename, replaced in tests to simulate
+// moving between file systems
+var (
+	link   = os.Link
+	rename = os.Rename
+)
+
+// MoveFile moves src to dst. It fails with ErrFileExists rather than replace
+// another file, except when only the case of the name changes. When src and
+// dst are on different file systems it copies src, keeping its permissions,
+// and removes it instead.
+func MoveFile(src, dst string) error {
+	// On case-insensitive file systems dst is src itself
+	if strings.EqualFold(src, dst) {
+		srcInfo, err := os.Stat(src)
+		if err != nil {
+			return fmt.Errorf("failed to move file: %w", err)
+		}
+		if dstInfo, err := os.Stat(dst); err == nil && os.SameFile(srcInfo, dstInfo) {
+			if err := rename(src, dst); err != nil {
+				return fmt.Errorf("failed to move file: %w", err)
+			}
+			return nil
+		}
+	}
+
+	// A hard link fails if dst exists, so the check and the move can't race
+	// with another writer
+	err := link(src, dst)
+	switch {
+	case err == nil:
+		if err := os.Remove(src); err != nil {
+			os.Remove(dst)
+			return fmt.Errorf("failed to move file: %w", err)
+		}
+		return nil
+	case errors.Is(err, os.ErrExist):
+		return fmt.Errorf("cannot move to %s: %w", filepath.Base(dst), ErrFileExists)
+	case errors.Is(err, syscall.EXDEV):
+		return copyFile(src, dst)
+	}
+
+	// Some file systems don't support hard links; check and rename instead
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("cannot move to %s: %w", filepath.Base(dst), ErrFileExists)
+	}
+	err = rename(src, dst)
+	if errors.Is(err, syscall.EXDEV) {
+		return copyFile(src, dst)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to move file: %w", err)
+	}
+	return nil
+}
+
+// copyFile moves src to dst on another file system by copying it and
+// removing src. Creating dst exclusively keeps the copy from replacing a
+// file.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to move file: %w", err)
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to move file: %w", err)
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("cannot move to %s: %w", filepath.Base(dst), ErrFileExists)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to move file: %w", err)
+	}
+	_, err = io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("failed to move file: %w", err)
+	}
+	if err := os.Remove(src); err != nil {
+		return fmt.Errorf("failed to move file: %w", err)
+	}
+	return nil
+}
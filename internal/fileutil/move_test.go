@@ -0,0 +1,125 @@
+package fileutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string, perm os.FileMode) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// failWith returns a link or rename that fails with errno
+func failWith(errno syscall.Errno) func(string, string) error {
+	return func(src, dst string) error {
+		return &os.LinkError{Op: "link", Old: src, New: dst, Err: errno}
+	}
+}
+
+func TestMoveFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		link    func(string, string) error
+		rename  func(string, string) error
+		wantErr error
+	}{
+		{"hard link", os.Link, os.Rename, nil},
+		{"no hard links", failWith(syscall.EPERM), os.Rename, nil},
+		{"link across file systems", failWith(syscall.EXDEV), failWith(syscall.EACCES), nil},
+		{"rename across file systems", failWith(syscall.EPERM), failWith(syscall.EXDEV), nil},
+		{"other rename error", failWith(syscall.EPERM), failWith(syscall.EACCES), syscall.EACCES},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link, rename = tt.link, tt.rename
+			t.Cleanup(func() { link, rename = os.Link, os.Rename })
+
+			dir := t.TempDir()
+			src := filepath.Join(dir, "src.md")
+			dst := filepath.Join(dir, "dst.md")
+			writeFile(t, src, "content", 0600)
+
+			err := MoveFile(src, dst)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MoveFile() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if _, err := os.Stat(dst); !os.IsNotExist(err) {
+					t.Error("MoveFile() copied the file after a failed rename")
+				}
+				return
+			}
+
+			if _, err := os.Stat(src); !os.IsNotExist(err) {
+				t.Error("MoveFile() left the source behind")
+			}
+			info, err := os.Stat(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != 0600 {
+				t.Errorf("moved file mode = %v, want 0600", info.Mode().Perm())
+			}
+			if data, _ := os.ReadFile(dst); string(data) != "content" {
+				t.Errorf("moved file = %q, want %q", data, "content")
+			}
+		})
+	}
+}
+
+func TestMoveFile_NoClobber(t *testing.T) {
+	tests := []struct {
+		name         string
+		link, rename func(string, string) error
+	}{
+		{"hard link", os.Link, os.Rename},
+		{"no hard links", failWith(syscall.EPERM), os.Rename},
+		{"across file systems", failWith(syscall.EXDEV), os.Rename},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link, rename = tt.link, tt.rename
+			t.Cleanup(func() { link, rename = os.Link, os.Rename })
+
+			dir := t.TempDir()
+			src := filepath.Join(dir, "a.md")
+			dst := filepath.Join(dir, "b.md")
+			writeFile(t, src, "A", 0644)
+			writeFile(t, dst, "B", 0644)
+
+			if err := MoveFile(src, dst); !errors.Is(err, ErrFileExists) {
+				t.Fatalf("MoveFile() error = %v, want ErrFileExists", err)
+			}
+			if data, _ := os.ReadFile(dst); string(data) != "B" {
+				t.Errorf("destination = %q, want it unchanged", data)
+			}
+			if _, err := os.Stat(src); err != nil {
+				t.Errorf("source removed: %v", err)
+			}
+		})
+	}
+}
+
+func TestMoveFile_CaseOnly(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "prompt.md")
+	dst := filepath.Join(dir, "Prompt.md")
+	writeFile(t, src, "A", 0644)
+
+	if err := MoveFile(src, dst); err != nil {
+		t.Fatalf("MoveFile() error = %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "Prompt.md" {
+		t.Errorf("directory = %v, want only Prompt.md", entries)
+	}
+}
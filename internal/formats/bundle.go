@@ -0,0 +1,51 @@
+package formats
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/grantcarthew/cuecard/internal/bundle"
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+// bundleImporter reads Cuecard export bundles
+type bundleImporter struct{}
+
+func (bundleImporter) ID() string   { return "bundle" }
+func (bundleImporter) Name() string { return "Cuecard export bundle" }
+
+func (bundleImporter) Detect(p string) bool {
+	format, err := bundle.FormatForPath(p)
+	if err != nil || isDir(p) {
+		return false
+	}
+	if format == bundle.Zip {
+		return true
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return false
+	}
+	var header struct {
+		Format string `json:"format"`
+	}
+	return json.Unmarshal(data, &header) == nil && header.Format == bundle.FormatName
+}
+
+func (bundleImporter) Import(p string) ([]Entry, []string, error) {
+	m, err := bundle.Read(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	var entries []Entry
+	for _, e := range m.Prompts {
+		pr, err := prompt.Parse(e.Content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s in bundle: %w", e.Path, err)
+		}
+		pr.Favorite = false
+		entries = append(entries, Entry{Source: e.Path, Path: e.Path, Prompt: pr})
+	}
+	return entries, nil, nil
+}
@@ -0,0 +1,134 @@
+package formats
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+// awesomeGroup is the group given to prompts from awesome-chatgpt-prompts
+const awesomeGroup = "Awesome ChatGPT Prompts"
+
+// csvImporter reads a CSV file with a header row, such as the
+// awesome-chatgpt-prompts prompts.csv with its "act" and "prompt" columns
+type csvImporter struct{}
+
+func (csvImporter) ID() string   { return "csv" }
+func (csvImporter) Name() string { return "CSV (awesome-chatgpt-prompts)" }
+
+func (csvImporter) Detect(path string) bool {
+	return !isDir(path) && hasExt(path, ".csv")
+}
+
+func (csvImporter) Import(path string) ([]Entry, []string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open CSV file: %w", err)
+	}
+	defer f.Close()
+
+	// Spreadsheets often save CSV with a byte order mark
+	br := bufio.NewReader(f)
+	if bom, _ := br.Peek(3); string(bom) == "\xef\xbb\xbf" {
+		br.Discard(3)
+	}
+	r := csv.NewReader(br)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	header, err := r.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	cols := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := cols[name]; !ok {
+			cols[name] = i
+		}
+	}
+	column := func(names ...string) int {
+		for _, name := range names {
+			if i, ok := cols[name]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+	titleCol := column("act", "title", "name")
+	contentCol := column("prompt", "content", "text", "body")
+	if titleCol < 0 || contentCol < 0 {
+		return nil, nil, fmt.Errorf("CSV file needs a title (act, title or name) and a prompt (prompt or content) column")
+	}
+	groupCol := column("group", "category")
+	tagsCol := column("tags")
+	descCol := column("description")
+	devCol := column("for_devs")
+
+	group := prompt.TitleFromFileName(filepath.Base(path))
+	if _, ok := cols["act"]; ok {
+		group = awesomeGroup
+	}
+
+	base := filepath.Base(path)
+	var entries []Entry
+	var skipped []string
+	for row := 2; ; row++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read CSV file: %w", err)
+		}
+		field := func(i int) string {
+			if i < 0 || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		source := base + ":" + strconv.Itoa(row)
+		title, content := field(titleCol), field(contentCol)
+		if title == "" || content == "" {
+			skipped = append(skipped, source+": no title or prompt")
+			continue
+		}
+		p := &prompt.Prompt{
+			Title:       title,
+			Description: field(descCol),
+			Group:       group,
+			Tags:        splitTags(field(tagsCol)),
+			Content:     content,
+		}
+		if g := field(groupCol); g != "" {
+			p.Group = g
+		}
+		if p.Description == "" {
+			p.Description = describe(content)
+		}
+		if dev, _ := strconv.ParseBool(field(devCol)); dev {
+			p.Tags = append(p.Tags, "developer")
+		}
+		entries = append(entries, Entry{Source: source, Prompt: p})
+	}
+	return entries, skipped, nil
+}
+
+// splitTags splits a comma or semicolon separated list of tags
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,97 @@
+package formats
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+// fabricGroup is the group given to Fabric patterns
+const fabricGroup = "Fabric"
+
+// fabricImporter reads Fabric patterns: folders holding a system.md. It
+// accepts a Fabric checkout, its patterns folder or a single pattern.
+type fabricImporter struct{}
+
+func (fabricImporter) ID() string   { return "fabric" }
+func (fabricImporter) Name() string { return "Fabric patterns" }
+
+func (fabricImporter) Detect(path string) bool {
+	return isDir(path) && len(patternDirs(path)) > 0
+}
+
+func (fabricImporter) Import(path string) ([]Entry, []string, error) {
+	dirs := patternDirs(path)
+	if len(dirs) == 0 {
+		return nil, nil, fmt.Errorf("no Fabric patterns found in %s", filepath.Base(path))
+	}
+
+	var entries []Entry
+	var skipped []string
+	for _, dir := range dirs {
+		name := filepath.Base(dir)
+		source := name + "/system.md"
+		data, err := os.ReadFile(filepath.Join(dir, "system.md"))
+		if err != nil {
+			skipped = append(skipped, source+": read error")
+			continue
+		}
+		content := strings.TrimSpace(string(data))
+		if content == "" {
+			skipped = append(skipped, source+": empty")
+			continue
+		}
+		// Patterns expect the input after the instructions
+		if !strings.Contains(content, "${INPUT}") {
+			content += "\n\n${INPUT}"
+		}
+
+		tags := []string{"fabric"}
+		if verb, _, _ := strings.Cut(name, "_"); verb != "" && verb != name {
+			tags = append(tags, verb)
+		}
+		entries = append(entries, Entry{Source: source, Prompt: &prompt.Prompt{
+			Title:       prompt.TitleFromFileName(name),
+			Description: describe(content),
+			Group:       fabricGroup,
+			Tags:        tags,
+			Input:       "required",
+			Content:     content,
+		}})
+	}
+	return entries, skipped, nil
+}
+
+// patternDirs returns the pattern folders at path: path itself, its
+// subfolders or those of its patterns folder
+func patternDirs(path string) []string {
+	if fileExists(filepath.Join(path, "system.md")) {
+		return []string{path}
+	}
+	if sub := filepath.Join(path, "patterns"); isDir(sub) {
+		path = sub
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, entry := range entries {
+		dir := filepath.Join(path, entry.Name())
+		if entry.IsDir() && fileExists(filepath.Join(dir, "system.md")) {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// fileExists reports whether path is a regular file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
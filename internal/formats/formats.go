@@ -0,0 +1,113 @@
+package formats
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+// ErrUnknownFormat is returned when no importer recognises a source
+var ErrUnknownFormat = errors.New("unrecognised import format")
+
+// Entry is a prompt read from an import source
+type Entry struct {
+	Source string // where it came from, such as a file name
+	Path   string // the prompt's slash-separated path in the source, such as code/review.md, or empty if it has none
+	Prompt *prompt.Prompt
+}
+
+// Importer reads prompts stored in some format, from a file or directory
+type Importer interface {
+	// ID is the short name used to pick the format on the command line
+	ID() string
+	// Name describes the format to users
+	Name() string
+	// Detect reports whether the file or directory at path looks like this
+	// format. It should be cheap and never fail.
+	Detect(path string) bool
+	// Import reads the prompts at path. skipped lists the files or records
+	// that were not imported, with the reason.
+	Import(path string) (entries []Entry, skipped []string, err error)
+}
+
+// importers are tried in order by Detect, most specific first
+var importers []Importer
+
+// Register adds an importer. Importers registered first are tried first.
+func Register(imp Importer) {
+	importers = append(importers, imp)
+}
+
+// All returns the registered importers in detection order
+func All() []Importer {
+	return importers
+}
+
+// Lookup returns the importer with the given ID
+func Lookup(id string) (Importer, error) {
+	for _, imp := range importers {
+		if imp.ID() == id {
+			return imp, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown import format: %q", id)
+}
+
+// Detect returns the first importer that recognises the source at path
+func Detect(path string) (Importer, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to read import source: %w", err)
+	}
+	for _, imp := range importers {
+		if imp.Detect(path) {
+			return imp, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", filepath.Base(path), ErrUnknownFormat)
+}
+
+func init() {
+	Register(bundleImporter{})
+	Register(openAIImporter{})
+	Register(csvImporter{})
+	Register(fabricImporter{})
+	Register(markdownImporter{})
+	Register(textImporter{})
+}
+
+// describe returns the first line of content that is not a heading, cut to
+// a length that suits a description
+func describe(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if runes := []rune(line); len(runes) > 100 {
+			return strings.TrimSpace(string(runes[:97])) + "..."
+		}
+		return line
+	}
+	return ""
+}
+
+// hasExt reports whether path has one of exts, ignoring case
+func hasExt(path string, exts ...string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// isDir reports whether path is a directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
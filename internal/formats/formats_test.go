@@ -0,0 +1,274 @@
+package formats
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/grantcarthew/cuecard/internal/bundle"
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// importPath detects the format of path, checks it is want and imports it
+func importPath(t *testing.T, path, want string) ([]Entry, []string) {
+	t.Helper()
+	imp, err := Detect(path)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if imp.ID() != want {
+		t.Fatalf("Detect() = %s, want %s", imp.ID(), want)
+	}
+	entries, skipped, err := imp.Import(path)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	return entries, skipped
+}
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"prompts.csv":                         "act,prompt\nLinux Terminal,Act as a terminal",
+		"chat.json":                           `[{"role": "system", "content": "Be brief."}]`,
+		"other.json":                          `{"name": "x"}`,
+		"notes.txt":                           "A snippet",
+		"plain.md":                            "# Heading\n\nNo frontmatter",
+		"card.md":                             "---\ntitle: Card\n---\n\nBody",
+		"fabric/patterns/summarize/system.md": "# IDENTITY\n\nSummarize.",
+		"library/card.md":                     "---\ntitle: Card\n---\n\nBody",
+		"snippets/a.txt":                      "A",
+		"data.bin":                            "binary",
+	}
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, name), content)
+	}
+	root := t.TempDir()
+	if _, err := bundle.ExportFile(filepath.Join(dir, "bundle.json"), []*prompt.Prompt{
+		{Title: "X", Content: "x", FilePath: filepath.Join(root, "x.md"), FileName: "x.md"},
+	}, root, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"prompts.csv", "csv"},
+		{"chat.json", "openai"},
+		{"bundle.json", "bundle"},
+		{"notes.txt", "text"},
+		{"plain.md", "text"},
+		{"card.md", "cuecard"},
+		{"fabric", "fabric"},
+		{"fabric/patterns", "fabric"},
+		{"fabric/patterns/summarize", "fabric"},
+		{"library", "cuecard"},
+		{"snippets", "text"},
+		{"other.json", ""},
+		{"data.bin", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			imp, err := Detect(filepath.Join(dir, tt.path))
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("Detect() = %s, want an error", imp.ID())
+				}
+				return
+			}
+			if err != nil || imp.ID() != tt.want {
+				t.Errorf("Detect() = %v, %v, want %s", imp, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, imp := range All() {
+		if got, err := Lookup(imp.ID()); err != nil || got != imp {
+			t.Errorf("Lookup(%s) = %v, %v", imp.ID(), got, err)
+		}
+	}
+	if _, err := Lookup("yaml"); err == nil {
+		t.Error("Lookup(yaml) error = nil")
+	}
+}
+
+func TestCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompts.csv")
+	writeFile(t, path, "\ufeff\"act\",\"prompt\",\"for_devs\"\n"+
+		"\"Linux Terminal\",\"I want you to act as a linux terminal.\",\"TRUE\"\n"+
+		"\"Storyteller\",\"I want you to act as a storyteller.\",\"FALSE\"\n"+
+		"\"\",\"No title\",\"FALSE\"\n")
+
+	entries, skipped := importPath(t, path, "csv")
+	if len(entries) != 2 || len(skipped) != 1 {
+		t.Fatalf("Import() = %d entries, skipped %v", len(entries), skipped)
+	}
+	p := entries[0].Prompt
+	if p.Title != "Linux Terminal" || p.Group != awesomeGroup || p.Content != "I want you to act as a linux terminal." {
+		t.Errorf("prompt = %+v", p)
+	}
+	if !slices.Equal(p.Tags, []string{"developer"}) || entries[1].Prompt.Tags != nil {
+		t.Errorf("tags = %v, %v", p.Tags, entries[1].Prompt.Tags)
+	}
+	if entries[0].Source != "prompts.csv:2" || entries[0].Path != "" {
+		t.Errorf("entry = %+v", entries[0])
+	}
+}
+
+func TestCSV_Columns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "team_prompts.csv")
+	writeFile(t, path, "title,content,tags,group\nReview,Review this,\"go, review\",Coding\nPlain,Plain prompt,,\n")
+
+	entries, _ := importPath(t, path, "csv")
+	if len(entries) != 2 {
+		t.Fatalf("Import() = %d entries, want 2", len(entries))
+	}
+	if p := entries[0].Prompt; p.Group != "Coding" || !slices.Equal(p.Tags, []string{"go", "review"}) {
+		t.Errorf("prompt = %+v", p)
+	}
+	if p := entries[1].Prompt; p.Group != "Team Prompts" {
+		t.Errorf("group = %q, want the file name", p.Group)
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.csv")
+	writeFile(t, bad, "a,b\n1,2\n")
+	if _, _, err := (csvImporter{}).Import(bad); err == nil {
+		t.Error("Import() without title and prompt columns error = nil")
+	}
+}
+
+func TestFabric(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "patterns", "extract_wisdom", "system.md"),
+		"# IDENTITY and PURPOSE\n\nYou extract surprising ideas.\n\n# INPUT\n\nINPUT:\n")
+	writeFile(t, filepath.Join(dir, "patterns", "summarize", "system.md"), "Summarize: ${INPUT}")
+	writeFile(t, filepath.Join(dir, "patterns", "empty", "system.md"), "")
+	writeFile(t, filepath.Join(dir, "patterns", "README.md"), "# Patterns")
+
+	entries, skipped := importPath(t, dir, "fabric")
+	if len(entries) != 2 || len(skipped) != 1 {
+		t.Fatalf("Import() = %d entries, skipped %v", len(entries), skipped)
+	}
+	p := entries[0].Prompt
+	if p.Title != "Extract Wisdom" || p.Group != fabricGroup || !slices.Equal(p.Tags, []string{"fabric", "extract"}) {
+		t.Errorf("prompt = %+v", p)
+	}
+	if p.Description != "You extract surprising ideas." || !p.RequiresInput() {
+		t.Errorf("description %q, input %q", p.Description, p.Input)
+	}
+	if want := "INPUT:\n\n${INPUT}"; p.Content[len(p.Content)-len(want):] != want {
+		t.Errorf("content does not end with the input variable:\n%s", p.Content)
+	}
+	if s := entries[1].Prompt; s.Content != "Summarize: ${INPUT}" || !slices.Equal(s.Tags, []string{"fabric"}) {
+		t.Errorf("prompt = %+v", s)
+	}
+}
+
+func TestOpenAI(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		titles  []string
+		content string
+		tags    []string
+	}{
+		{
+			name:    "messages",
+			json:    `[{"role": "system", "content": "Be brief."}, {"role": "user", "content": "Explain ${INPUT}"}, {"role": "assistant", "content": "Sure"}]`,
+			titles:  []string{"Code Helper"},
+			content: "Be brief.\n\nExplain ${INPUT}",
+			tags:    []string{"openai"},
+		},
+		{
+			name:    "request",
+			json:    `{"model": "gpt-4o", "messages": [{"role": "developer", "content": [{"type": "text", "text": "Part one"}, {"type": "text", "text": "part two"}]}]}`,
+			titles:  []string{"Code Helper"},
+			content: "Part one\npart two",
+			tags:    []string{"openai", "gpt-4o"},
+		},
+		{
+			name:    "list",
+			json:    `[{"name": "Tutor", "messages": [{"role": "system", "content": "Teach"}]}, {"messages": [{"role": "user", "content": "Ask"}]}]`,
+			titles:  []string{"Tutor", "Code Helper 2"},
+			content: "Teach",
+			tags:    []string{"openai"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "code-helper.json")
+			writeFile(t, path, tt.json)
+			entries, _ := importPath(t, path, "openai")
+			var titles []string
+			for _, e := range entries {
+				titles = append(titles, e.Prompt.Title)
+			}
+			if !slices.Equal(titles, tt.titles) {
+				t.Errorf("titles = %v, want %v", titles, tt.titles)
+			}
+			p := entries[0].Prompt
+			if p.Content != tt.content || p.Group != openAIGroup || !slices.Equal(p.Tags, tt.tags) {
+				t.Errorf("prompt = %+v", p)
+			}
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "writing")
+	writeFile(t, filepath.Join(dir, "cover_letter.txt"), "Write a cover letter for ${INPUT}.")
+	writeFile(t, filepath.Join(dir, "tone.md"), "# Tone\n\nMake this friendlier.")
+	writeFile(t, filepath.Join(dir, "empty.txt"), "  ")
+	writeFile(t, filepath.Join(dir, "email", "reply.txt"), "Reply politely.")
+
+	entries, skipped := importPath(t, dir, "text")
+	if len(entries) != 3 || len(skipped) != 1 {
+		t.Fatalf("Import() = %+v, skipped %v", entries, skipped)
+	}
+	letter := entries[0].Prompt
+	if letter.Title != "Cover Letter" || letter.Group != "Writing" || !letter.RequiresInput() {
+		t.Errorf("prompt = %+v", letter)
+	}
+	if tone := entries[1].Prompt; tone.Title != "Tone" || tone.Description != "Make this friendlier." {
+		t.Errorf("prompt = %+v", tone)
+	}
+	if e := entries[2]; e.Source != "email/reply.txt" || e.Prompt.Group != "Email" {
+		t.Errorf("entry = %+v", e)
+	}
+
+	single, _ := importPath(t, filepath.Join(dir, "cover_letter.txt"), "text")
+	if len(single) != 1 || single[0].Prompt.Group != "" {
+		t.Errorf("single file = %+v", single)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "good.md"), "---\ntitle: Good\nfavorite: true\n---\n\nBody")
+	writeFile(t, filepath.Join(dir, "README.md"), "# Readme")
+	writeFile(t, filepath.Join(dir, "plain.md"), "No frontmatter")
+	writeFile(t, filepath.Join(dir, "untitled.md"), "---\ngroup: X\n---\n\nBody")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "ignored")
+
+	entries, skipped := importPath(t, dir, "cuecard")
+	if len(entries) != 1 || entries[0].Path != "good.md" || entries[0].Prompt.Favorite {
+		t.Errorf("Import() = %+v", entries)
+	}
+	if len(skipped) != 3 {
+		t.Errorf("skipped = %v, want 3", skipped)
+	}
+}
@@ -0,0 +1,114 @@
+package formats
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+// markdownImporter reads Cuecard prompt files: markdown with frontmatter,
+// either a single file or a directory of them
+type markdownImporter struct{}
+
+func (markdownImporter) ID() string   { return "cuecard" }
+func (markdownImporter) Name() string { return "Cuecard prompts" }
+
+func (markdownImporter) Detect(path string) bool {
+	if !isDir(path) {
+		return hasExt(path, ".md") && hasFrontmatter(path)
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && hasExt(entry.Name(), ".md") && hasFrontmatter(filepath.Join(path, entry.Name())) {
+			return true
+		}
+	}
+	return false
+}
+
+func (markdownImporter) Import(path string) ([]Entry, []string, error) {
+	if !isDir(path) {
+		entry, reason, err := readMarkdown(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if reason != "" {
+			return nil, nil, fmt.Errorf("%s: %s", filepath.Base(path), reason)
+		}
+		return []Entry{entry}, nil, nil
+	}
+
+	dirEntries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read import directory: %w", err)
+	}
+	var entries []Entry
+	var skipped []string
+	for _, de := range dirEntries {
+		name := de.Name()
+		if de.IsDir() || !hasExt(name, ".md") {
+			continue
+		}
+		if strings.EqualFold(name, "readme.md") {
+			skipped = append(skipped, name+": readme")
+			continue
+		}
+		entry, reason, err := readMarkdown(filepath.Join(path, name))
+		if err != nil {
+			skipped = append(skipped, name+": read error")
+			continue
+		}
+		if reason != "" {
+			skipped = append(skipped, name+": "+reason)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, skipped, nil
+}
+
+// readMarkdown reads a prompt file. reason explains why a readable file is
+// not a prompt.
+func readMarkdown(path string) (entry Entry, reason string, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Entry{}, "", fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	content := string(data)
+	if !prompt.HasFrontmatter(content) {
+		return Entry{}, "no frontmatter", nil
+	}
+	p, err := prompt.Parse(content)
+	if err != nil {
+		return Entry{}, "parse error", nil
+	}
+	if p.Title == "" {
+		return Entry{}, "no title", nil
+	}
+	p.Favorite = false
+	name := filepath.Base(path)
+	return Entry{Source: name, Path: name, Prompt: p}, "", nil
+}
+
+// hasFrontmatter reports whether the file at path starts with frontmatter
+func hasFrontmatter(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	buf := make([]byte, 4096)
+	n, _ := f.Read(buf)
+	content := string(buf[:n])
+	if n == len(buf) {
+		// Frontmatter longer than the sniffed prefix still counts
+		return strings.HasPrefix(strings.TrimSpace(content), "---")
+	}
+	return prompt.HasFrontmatter(content)
+}
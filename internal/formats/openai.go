@@ -0,0 +1,167 @@
+package formats
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+// openAIGroup is the group given to prompts from OpenAI message files
+const openAIGroup = "OpenAI"
+
+// openAIImporter reads OpenAI-style chat messages from a JSON file: a
+// messages array, an object with "messages", or an array of such objects
+type openAIImporter struct{}
+
+func (openAIImporter) ID() string   { return "openai" }
+func (openAIImporter) Name() string { return "OpenAI messages (JSON)" }
+
+func (openAIImporter) Detect(path string) bool {
+	if isDir(path) || !hasExt(path, ".json") {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	chats, err := parseChats(data)
+	return err == nil && len(chats) > 0
+}
+
+func (openAIImporter) Import(path string) ([]Entry, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read JSON file: %w", err)
+	}
+	chats, err := parseChats(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	base := filepath.Base(path)
+	stem := prompt.TitleFromFileName(base)
+	var entries []Entry
+	var skipped []string
+	for i, chat := range chats {
+		source := base
+		title := stem
+		if len(chats) > 1 {
+			source += "#" + strconv.Itoa(i+1)
+			title += " " + strconv.Itoa(i+1)
+		}
+		if chat.Title != "" {
+			title = chat.Title
+		} else if chat.Name != "" {
+			title = chat.Name
+		}
+
+		var parts []string
+		for _, m := range chat.Messages {
+			switch m.Role {
+			case "system", "developer", "user":
+				if text := strings.TrimSpace(m.text()); text != "" {
+					parts = append(parts, text)
+				}
+			}
+		}
+		if len(parts) == 0 {
+			skipped = append(skipped, source+": no system or user messages")
+			continue
+		}
+		content := strings.Join(parts, "\n\n")
+
+		tags := []string{"openai"}
+		if chat.Model != "" {
+			tags = append(tags, chat.Model)
+		}
+		p := &prompt.Prompt{
+			Title:       title,
+			Description: chat.Description,
+			Group:       openAIGroup,
+			Tags:        tags,
+			Content:     content,
+		}
+		if p.Description == "" {
+			p.Description = describe(content)
+		}
+		entries = append(entries, Entry{Source: source, Prompt: p})
+	}
+	return entries, skipped, nil
+}
+
+// chat is a conversation in an OpenAI-style request or saved prompt
+type chat struct {
+	Name        string    `json:"name"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Model       string    `json:"model"`
+	Messages    []message `json:"messages"`
+}
+
+// message is a chat message whose content is a string or a list of parts
+type message struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// text returns the message's text, joining text parts
+func (m message) text() string {
+	var s string
+	if json.Unmarshal(m.Content, &s) == nil {
+		return s
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(m.Content, &parts) != nil {
+		return ""
+	}
+	var texts []string
+	for _, part := range parts {
+		if part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// parseChats reads the conversations in data, in any of the accepted shapes
+func parseChats(data []byte) ([]chat, error) {
+	var messages []message
+	if json.Unmarshal(data, &messages) == nil && validMessages(messages) {
+		return []chat{{Messages: messages}}, nil
+	}
+	var single chat
+	if json.Unmarshal(data, &single) == nil && validMessages(single.Messages) {
+		return []chat{single}, nil
+	}
+	var chats []chat
+	if json.Unmarshal(data, &chats) == nil && len(chats) > 0 {
+		for _, c := range chats {
+			if !validMessages(c.Messages) {
+				return nil, fmt.Errorf("not an OpenAI messages file")
+			}
+		}
+		return chats, nil
+	}
+	return nil, fmt.Errorf("not an OpenAI messages file")
+}
+
+// validMessages reports whether every message has a role
+func validMessages(messages []message) bool {
+	if len(messages) == 0 {
+		return false
+	}
+	for _, m := range messages {
+		if m.Role == "" {
+			return false
+		}
+	}
+	return true
+}
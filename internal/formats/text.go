@@ -0,0 +1,129 @@
+package formats
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+// textImporter reads plain text snippets: .txt files and markdown without
+// frontmatter. The file name becomes the title and, when importing a
+// directory, the folder name becomes the group.
+type textImporter struct{}
+
+func (textImporter) ID() string   { return "text" }
+func (textImporter) Name() string { return "Plain text snippets" }
+
+func (textImporter) Detect(path string) bool {
+	if !isDir(path) {
+		return isSnippet(path)
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && isSnippet(filepath.Join(path, entry.Name())) {
+			return true
+		}
+	}
+	return false
+}
+
+func (textImporter) Import(path string) ([]Entry, []string, error) {
+	if !isDir(path) {
+		entry, err := readSnippet(path, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		if entry == nil {
+			return nil, nil, fmt.Errorf("%s: empty", filepath.Base(path))
+		}
+		return []Entry{*entry}, nil, nil
+	}
+
+	entries, skipped, err := readSnippets(path, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	// One level of subfolders, each a group
+	dirEntries, _ := os.ReadDir(path)
+	for _, de := range dirEntries {
+		if !de.IsDir() || strings.HasPrefix(de.Name(), ".") {
+			continue
+		}
+		sub, subSkipped, err := readSnippets(filepath.Join(path, de.Name()), de.Name()+"/")
+		if err != nil {
+			skipped = append(skipped, de.Name()+": read error")
+			continue
+		}
+		entries = append(entries, sub...)
+		skipped = append(skipped, subSkipped...)
+	}
+	return entries, skipped, nil
+}
+
+// readSnippets reads the snippet files in dir, grouped by the folder's name.
+// prefix is prepended to each source.
+func readSnippets(dir, prefix string) ([]Entry, []string, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read import directory: %w", err)
+	}
+	group := prompt.TitleFromFileName(filepath.Base(dir))
+	var entries []Entry
+	var skipped []string
+	for _, de := range dirEntries {
+		name := de.Name()
+		if de.IsDir() || strings.EqualFold(name, "readme.md") || !isSnippet(filepath.Join(dir, name)) {
+			continue
+		}
+		entry, err := readSnippet(filepath.Join(dir, name), group)
+		if err != nil {
+			skipped = append(skipped, prefix+name+": read error")
+			continue
+		}
+		if entry == nil {
+			skipped = append(skipped, prefix+name+": empty")
+			continue
+		}
+		entry.Source = prefix + name
+		entries = append(entries, *entry)
+	}
+	return entries, skipped, nil
+}
+
+// readSnippet reads a snippet file as a prompt in group. It returns nil if
+// the file is empty.
+func readSnippet(path, group string) (*Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	content := strings.TrimSpace(string(data))
+	if content == "" {
+		return nil, nil
+	}
+	p := &prompt.Prompt{
+		Title:       prompt.TitleFromFileName(filepath.Base(path)),
+		Description: describe(content),
+		Group:       group,
+		Content:     content,
+	}
+	if strings.Contains(content, "${INPUT}") {
+		p.Input = "required"
+	}
+	return &Entry{Source: filepath.Base(path), Prompt: p}, nil
+}
+
+// isSnippet reports whether path is a text file or markdown without
+// frontmatter
+func isSnippet(path string) bool {
+	if hasExt(path, ".txt") {
+		return true
+	}
+	return hasExt(path, ".md") && !hasFrontmatter(path)
+}
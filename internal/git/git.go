@@ -0,0 +1,197 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrNotInstalled is returned when no git binary is on the PATH
+	ErrNotInstalled = errors.New("git is not installed")
+	// ErrNotRepo is returned when a directory is not inside a git work tree
+	ErrNotRepo = errors.New("not inside a git work tree")
+	// ErrNothingToCommit is returned by Commit when there are no changes
+	ErrNothingToCommit = errors.New("nothing to commit")
+)
+
+// Status is the git status of a file
+type Status int
+
+const (
+	Clean Status = iota
+	Modified
+	Untracked
+	Conflicted
+)
+
+// String returns the status as shown on a badge
+func (s Status) String() string {
+	switch s {
+	case Modified:
+		return "modified"
+	case Untracked:
+		return "untracked"
+	case Conflicted:
+		return "conflicted"
+	default:
+		return "clean"
+	}
+}
+
+// conflictCodes are the porcelain status codes of unmerged paths
+var conflictCodes = map[string]bool{
+	"DD": true, "AU": true, "UD": true, "UA": true,
+	"DU": true, "AA": true, "UU": true,
+}
+
+// Repo runs git commands for a directory inside a git work tree. Status and
+// commits are limited to that directory, which may be a subdirectory of the
+// repository.
+type Repo struct {
+	dir    string
+	prefix string // dir relative to the work tree root, with a trailing slash
+}
+
+// Open returns the repo for dir. It fails with ErrNotInstalled when git is
+// missing and ErrNotRepo when dir is not inside a work tree.
+func Open(dir string) (*Repo, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, ErrNotInstalled
+	}
+	out, err := run(dir, "rev-parse", "--is-inside-work-tree", "--show-prefix")
+	if err != nil {
+		return nil, ErrNotRepo
+	}
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) == 0 || lines[0] != "true" {
+		return nil, ErrNotRepo
+	}
+	r := &Repo{dir: dir}
+	if len(lines) > 1 {
+		r.prefix = lines[1]
+	}
+	return r, nil
+}
+
+// Dir returns the directory the repo was opened for
+func (r *Repo) Dir() string {
+	return r.dir
+}
+
+// Status returns the status of each changed file in the directory, keyed
+// by path. Clean files are not included.
+func (r *Repo) Status() (map[string]Status, error) {
+	out, err := run(r.dir, "status", "--porcelain=v1", "-z", "--untracked-files=all", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+
+	status := make(map[string]Status)
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		code, path := entry[:2], entry[3:]
+		if code[0] == 'R' || code[0] == 'C' {
+			// Renames and copies are followed by the original path
+			i++
+		}
+
+		var s Status
+		switch {
+		case code == "??":
+			s = Untracked
+		case code == "!!":
+			continue
+		case conflictCodes[code]:
+			s = Conflicted
+		default:
+			s = Modified
+		}
+
+		// Porcelain paths are relative to the work tree root
+		rel, ok := strings.CutPrefix(path, r.prefix)
+		if !ok {
+			continue
+		}
+		status[filepath.Join(r.dir, filepath.FromSlash(rel))] = s
+	}
+	return status, nil
+}
+
+// Pull fetches and merges the upstream branch. Conflicting files are left
+// with conflict markers and reported by Status.
+func (r *Repo) Pull() error {
+	_, err := run(r.dir, "pull", "--no-rebase", "--no-edit")
+	return err
+}
+
+// Commit stages and commits every change in the directory with message.
+// While a merge is in progress the whole merge is committed.
+func (r *Repo) Commit(message string) error {
+	if strings.TrimSpace(message) == "" {
+		return errors.New("commit message is required")
+	}
+	if _, err := run(r.dir, "add", "-A", "--", "."); err != nil {
+		return err
+	}
+
+	args := []string{"commit", "-q", "-m", message}
+	if _, err := run(r.dir, "rev-parse", "-q", "--verify", "MERGE_HEAD"); err != nil {
+		// Not merging, so only the directory's changes are committed
+		status, err := r.Status()
+		if err != nil {
+			return err
+		}
+		if len(status) == 0 {
+			return ErrNothingToCommit
+		}
+		args = append(args, "--", ".")
+	}
+	_, err := run(r.dir, args...)
+	return err
+}
+
+// Push pushes the current branch to its upstream
+func (r *Repo) Push() error {
+	_, err := run(r.dir, "push")
+	return err
+}
+
+// run runs git in dir and returns its output. Prompts for credentials are
+// disabled so a command can never hang waiting for input.
+func run(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		if msg != "" {
+			return "", fmt.Errorf("git %s failed: %w\n%s", args[0], err, lastLines(msg, 5))
+		}
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+// lastLines returns at most n trailing lines of s
+func lastLines(s string, n int) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,191 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// setupGit isolates git from the user's config and skips the test when git
+// is not installed
+func setupGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+}
+
+func gitCmd(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	if _, err := run(dir, args...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setupClones creates a bare remote with an initial commit and returns two
+// clones of it
+func setupClones(t *testing.T) (a, b string) {
+	t.Helper()
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	gitCmd(t, root, "init", "-q", "--bare", "-b", "main", remote)
+
+	a = filepath.Join(root, "a")
+	gitCmd(t, root, "clone", "-q", remote, a)
+	gitCmd(t, a, "checkout", "-q", "-b", "main")
+	writeFile(t, filepath.Join(a, "prompt.md"), "one\n")
+	gitCmd(t, a, "add", ".")
+	gitCmd(t, a, "commit", "-q", "-m", "initial")
+	gitCmd(t, a, "push", "-q", "-u", "origin", "main")
+
+	b = filepath.Join(root, "b")
+	gitCmd(t, root, "clone", "-q", remote, b)
+	return a, b
+}
+
+func TestOpen_NotRepo(t *testing.T) {
+	setupGit(t)
+	if _, err := Open(t.TempDir()); !errors.Is(err, ErrNotRepo) {
+		t.Errorf("Open() error = %v, want ErrNotRepo", err)
+	}
+}
+
+func TestOpen_NotInstalled(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	if _, err := Open(t.TempDir()); !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("Open() error = %v, want ErrNotInstalled", err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	setupGit(t)
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q")
+	prompts := filepath.Join(dir, "prompts")
+	if err := os.Mkdir(prompts, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(prompts, "tracked.md"), "one\n")
+	writeFile(t, filepath.Join(dir, "outside.md"), "one\n")
+	gitCmd(t, dir, "add", ".")
+	gitCmd(t, dir, "commit", "-q", "-m", "initial")
+
+	writeFile(t, filepath.Join(prompts, "tracked.md"), "two\n")
+	writeFile(t, filepath.Join(prompts, "new file.md"), "new\n")
+	writeFile(t, filepath.Join(dir, "outside.md"), "two\n")
+
+	r, err := Open(prompts)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	status, err := r.Status()
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+
+	want := map[string]Status{
+		filepath.Join(prompts, "tracked.md"):  Modified,
+		filepath.Join(prompts, "new file.md"): Untracked,
+	}
+	if len(status) != len(want) {
+		t.Errorf("Status() = %v, want %v", status, want)
+	}
+	for path, s := range want {
+		if status[path] != s {
+			t.Errorf("Status()[%s] = %v, want %v", filepath.Base(path), status[path], s)
+		}
+	}
+}
+
+func TestCommitPushPull(t *testing.T) {
+	setupGit(t)
+	a, b := setupClones(t)
+
+	ra, err := Open(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ra.Commit("nothing"); !errors.Is(err, ErrNothingToCommit) {
+		t.Errorf("Commit() with no changes error = %v, want ErrNothingToCommit", err)
+	}
+
+	writeFile(t, filepath.Join(a, "prompt.md"), "two\n")
+	writeFile(t, filepath.Join(a, "added.md"), "added\n")
+	if err := ra.Commit("Update prompts"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if status, _ := ra.Status(); len(status) != 0 {
+		t.Errorf("Status() after commit = %v, want clean", status)
+	}
+	if err := ra.Push(); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	rb, err := Open(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rb.Pull(); err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(b, "added.md"))
+	if err != nil || string(data) != "added\n" {
+		t.Errorf("pulled added.md = %q, %v", data, err)
+	}
+}
+
+func TestPull_Conflict(t *testing.T) {
+	setupGit(t)
+	a, b := setupClones(t)
+
+	ra, _ := Open(a)
+	writeFile(t, filepath.Join(a, "prompt.md"), "from a\n")
+	if err := ra.Commit("Change in a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ra.Push(); err != nil {
+		t.Fatal(err)
+	}
+
+	rb, _ := Open(b)
+	path := filepath.Join(b, "prompt.md")
+	writeFile(t, path, "from b\n")
+	if err := rb.Commit("Change in b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rb.Pull(); err == nil {
+		t.Fatal("Pull() error = nil, want a merge conflict")
+	}
+
+	status, err := rb.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status[path] != Conflicted {
+		t.Errorf("Status()[prompt.md] = %v, want conflicted", status[path])
+	}
+
+	// Resolving and committing finishes the merge
+	writeFile(t, path, "from both\n")
+	if err := rb.Commit("Merge"); err != nil {
+		t.Fatalf("Commit() of merge error = %v", err)
+	}
+	if err := rb.Push(); err != nil {
+		t.Errorf("Push() after merge error = %v", err)
+	}
+}
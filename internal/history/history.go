@@ -0,0 +1,260 @@
+package history
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLimit is how many versions are kept for each prompt
+const DefaultLimit = 100
+
+// Source records how a version came about
+type Source string
+
+const (
+	// Saved is a save made in Cuecard
+	Saved Source = "save"
+	// External is a change made outside Cuecard and seen by the watcher
+	External Source = "external"
+	// Restored is an earlier version restored from history
+	Restored Source = "restore"
+	// Baseline is a file as it was before Cuecard first saved over it
+	Baseline Source = "baseline"
+)
+
+// Version is a recorded version of a prompt file
+type Version struct {
+	ID     string
+	Time   time.Time
+	Source Source
+	Size   int64
+}
+
+// Store keeps the versions of each prompt file in a directory of its own,
+// named after a hash of the file's path, with a path file recording the
+// path it belongs to. It is safe for concurrent use.
+type Store struct {
+	mu    sync.Mutex
+	dir   string
+	limit int
+}
+
+// New returns the history store in dir, keeping up to limit versions of each
+// prompt. The directory is created when first used.
+func New(dir string, limit int) *Store {
+	return &Store{dir: dir, limit: limit}
+}
+
+// Dir returns the history directory
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// promptDir returns the directory holding the versions of the file at path
+func (s *Store) promptDir(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:8])), nil
+}
+
+// Record adds content as the newest version of the file at path. Nothing is
+// recorded if content matches the newest version, and recorded reports
+// whether a version was added.
+func (s *Store) Record(path string, content []byte, source Source) (v Version, recorded bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.record(path, content, source, false)
+}
+
+// RecordReplaced records content, the file at path just before Cuecard saves
+// over it, so it isn't lost. Cuecard records its own saves, so content that
+// differs from the newest version was changed outside Cuecard and is
+// recorded as External. A file with no history yet is recorded as its
+// Baseline. Nothing is recorded if content matches the newest version.
+func (s *Store) RecordReplaced(path string, content []byte) (v Version, recorded bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.record(path, content, External, true)
+}
+
+// record adds content as the newest version unless it matches it. With
+// replaced set, a file with no history is recorded as Baseline rather than
+// source. s.mu must be held.
+func (s *Store) record(path string, content []byte, source Source, replaced bool) (v Version, recorded bool, err error) {
+	dir, err := s.promptDir(path)
+	if err != nil {
+		return Version{}, false, fmt.Errorf("failed to record history: %w", err)
+	}
+	versions, err := s.List(path)
+	if err != nil {
+		return Version{}, false, err
+	}
+	if len(versions) > 0 {
+		latest, err := os.ReadFile(filepath.Join(dir, versions[0].ID))
+		if err == nil && bytes.Equal(latest, content) {
+			return versions[0], false, nil
+		}
+	} else if replaced {
+		source = Baseline
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return Version{}, false, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	abs, _ := filepath.Abs(path)
+	if err := os.WriteFile(filepath.Join(dir, "path"), []byte(abs), 0600); err != nil {
+		return Version{}, false, fmt.Errorf("failed to record history: %w", err)
+	}
+
+	v = Version{Time: time.Now(), Source: source, Size: int64(len(content))}
+	if len(versions) > 0 && !v.Time.After(versions[0].Time) {
+		// Keep versions in order on clocks too coarse to tell them apart
+		v.Time = versions[0].Time.Add(time.Nanosecond)
+	}
+	v.ID = versionID(v.Time, source)
+	if err := os.WriteFile(filepath.Join(dir, v.ID), content, 0600); err != nil {
+		return Version{}, false, fmt.Errorf("failed to record history: %w", err)
+	}
+
+	// Drop the oldest versions beyond the limit
+	versions = append([]Version{v}, versions...)
+	if s.limit > 0 {
+		for _, old := range versions[min(s.limit, len(versions)):] {
+			os.Remove(filepath.Join(dir, old.ID))
+		}
+	}
+	return v, true, nil
+}
+
+// List returns the versions of the file at path, newest first
+func (s *Store) List(path string) ([]Version, error) {
+	dir, err := s.promptDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var versions []Version
+	for _, entry := range entries {
+		v, ok := parseVersionID(entry.Name())
+		if !ok {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			v.Size = info.Size()
+		}
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Time.After(versions[j].Time)
+	})
+	return versions, nil
+}
+
+// Read returns the content of a version of the file at path
+func (s *Store) Read(path, id string) ([]byte, error) {
+	if _, ok := parseVersionID(id); !ok {
+		return nil, fmt.Errorf("invalid history version: %q", id)
+	}
+	dir, err := s.promptDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return data, nil
+}
+
+// Rename moves the history of the file at oldPath to newPath, after the file
+// is renamed or moved. Versions already recorded for newPath are kept.
+func (s *Store) Rename(oldPath, newPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oldDir, err := s.promptDir(oldPath)
+	if err != nil {
+		return fmt.Errorf("failed to move history: %w", err)
+	}
+	newDir, err := s.promptDir(newPath)
+	if err != nil {
+		return fmt.Errorf("failed to move history: %w", err)
+	}
+	if oldDir == newDir {
+		return nil
+	}
+	if _, err := os.Stat(oldDir); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if _, err := os.Stat(newDir); errors.Is(err, os.ErrNotExist) {
+		if err := os.Rename(oldDir, newDir); err != nil {
+			return fmt.Errorf("failed to move history: %w", err)
+		}
+	} else {
+		versions, err := s.List(oldPath)
+		if err != nil {
+			return err
+		}
+		for _, v := range versions {
+			if err := os.Rename(filepath.Join(oldDir, v.ID), filepath.Join(newDir, v.ID)); err != nil {
+				return fmt.Errorf("failed to move history: %w", err)
+			}
+		}
+		os.RemoveAll(oldDir)
+	}
+
+	abs, _ := filepath.Abs(newPath)
+	if err := os.WriteFile(filepath.Join(newDir, "path"), []byte(abs), 0600); err != nil {
+		return fmt.Errorf("failed to move history: %w", err)
+	}
+	return nil
+}
+
+// versionID names a version file after its time and source, so versions
+// sort by name and need no separate index
+func versionID(t time.Time, source Source) string {
+	return fmt.Sprintf("%019d-%s.md", t.UnixNano(), source)
+}
+
+// parseVersionID parses a version file name made by versionID
+func parseVersionID(name string) (Version, bool) {
+	stem, ok := strings.CutSuffix(name, ".md")
+	if !ok {
+		return Version{}, false
+	}
+	nanos, source, ok := strings.Cut(stem, "-")
+	if !ok {
+		return Version{}, false
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil || n < 0 {
+		return Version{}, false
+	}
+	switch Source(source) {
+	case Saved, External, Restored, Baseline:
+	default:
+		return Version{}, false
+	}
+	return Version{ID: name, Time: time.Unix(0, n), Source: Source(source)}, true
+}
@@ -0,0 +1,155 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func record(t *testing.T, s *Store, path, content string, source Source) (Version, bool) {
+	t.Helper()
+	v, recorded, err := s.Record(path, []byte(content), source)
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	return v, recorded
+}
+
+func TestRecordAndList(t *testing.T) {
+	s := New(t.TempDir(), DefaultLimit)
+	path := filepath.Join(t.TempDir(), "prompt.md")
+
+	first, _ := record(t, s, path, "one", Saved)
+	second, _ := record(t, s, path, "two", External)
+
+	versions, err := s.List(path)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(versions) != 2 || versions[0].ID != second.ID || versions[1].ID != first.ID {
+		t.Fatalf("List() = %+v, want newest first", versions)
+	}
+	if versions[0].Source != External || versions[0].Size != 3 {
+		t.Errorf("List()[0] = %+v", versions[0])
+	}
+
+	data, err := s.Read(path, first.ID)
+	if err != nil || string(data) != "one" {
+		t.Errorf("Read() = %q, %v, want one", data, err)
+	}
+}
+
+func TestRecord_SkipsUnchanged(t *testing.T) {
+	s := New(t.TempDir(), DefaultLimit)
+	path := filepath.Join(t.TempDir(), "prompt.md")
+
+	first, recorded := record(t, s, path, "same", Saved)
+	if !recorded {
+		t.Fatal("first Record() not recorded")
+	}
+	again, recorded := record(t, s, path, "same", External)
+	if recorded || again.ID != first.ID {
+		t.Errorf("Record() of unchanged content = %+v, %v, want skipped", again, recorded)
+	}
+
+	// Content matching an older version is still a new version
+	record(t, s, path, "other", Saved)
+	if _, recorded := record(t, s, path, "same", Saved); !recorded {
+		t.Error("Record() of reverted content was skipped")
+	}
+}
+
+func TestRecordReplaced(t *testing.T) {
+	s := New(t.TempDir(), DefaultLimit)
+	path := filepath.Join(t.TempDir(), "prompt.md")
+
+	tests := []struct {
+		name         string
+		content      string
+		save         string // saved in Cuecard afterwards, if set
+		wantRecorded bool
+		wantSource   Source
+	}{
+		{"first save", "one", "two", true, Baseline},
+		{"matches the last save", "two", "three", false, ""},
+		{"changed outside", "edited", "four", true, External},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, recorded, err := s.RecordReplaced(path, []byte(tt.content))
+			if err != nil {
+				t.Fatalf("RecordReplaced() error = %v", err)
+			}
+			if recorded != tt.wantRecorded || (recorded && v.Source != tt.wantSource) {
+				t.Errorf("RecordReplaced() = %+v, %v, want source %q, %v", v, recorded, tt.wantSource, tt.wantRecorded)
+			}
+			record(t, s, path, tt.save, Saved)
+		})
+	}
+
+	versions, err := s.List(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 5 || versions[4].Source != Baseline {
+		t.Errorf("List() = %+v, want 5 versions starting with the baseline", versions)
+	}
+}
+
+func TestRecord_Limit(t *testing.T) {
+	s := New(t.TempDir(), 3)
+	path := filepath.Join(t.TempDir(), "prompt.md")
+
+	for _, content := range []string{"1", "2", "3", "4", "5"} {
+		record(t, s, path, content, Saved)
+	}
+
+	versions, err := s.List(path)
+	if err != nil || len(versions) != 3 {
+		t.Fatalf("List() = %+v, %v, want 3 versions", versions, err)
+	}
+	data, _ := s.Read(path, versions[2].ID)
+	if string(data) != "3" {
+		t.Errorf("oldest kept version = %q, want 3", data)
+	}
+}
+
+func TestRename(t *testing.T) {
+	s := New(t.TempDir(), DefaultLimit)
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.md")
+	newPath := filepath.Join(dir, "group", "new.md")
+
+	record(t, s, oldPath, "one", Saved)
+	record(t, s, newPath, "unrelated", Saved)
+
+	if err := s.Rename(oldPath, newPath); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	if versions, _ := s.List(oldPath); len(versions) != 0 {
+		t.Errorf("List(old) = %+v, want none", versions)
+	}
+	if versions, _ := s.List(newPath); len(versions) != 2 {
+		t.Errorf("List(new) = %+v, want 2 versions", versions)
+	}
+
+	// Renaming a file without history does nothing
+	if err := s.Rename(filepath.Join(dir, "none.md"), oldPath); err != nil {
+		t.Errorf("Rename() without history error = %v", err)
+	}
+}
+
+func TestRead_InvalidID(t *testing.T) {
+	s := New(t.TempDir(), DefaultLimit)
+	for _, id := range []string{"", "path", "../x.md", "1-bogus.md"} {
+		if _, err := s.Read("prompt.md", id); err == nil {
+			t.Errorf("Read(%q) error = nil", id)
+		}
+	}
+}
+
+func TestList_Missing(t *testing.T) {
+	s := New(t.TempDir(), DefaultLimit)
+	if versions, err := s.List("missing.md"); err != nil || versions != nil {
+		t.Errorf("List() = %v, %v", versions, err)
+	}
+}
@@ -0,0 +1,303 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/grantcarthew/cuecard/internal/formats"
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+// Status is how an incoming prompt compares with the library
+type Status int
+
+const (
+	// New prompts match nothing in the library
+	New Status = iota
+	// Identical prompts match a library prompt exactly
+	Identical
+	// Changed prompts share a path with a library prompt but differ
+	Changed
+	// TitleClash prompts share a title with a different library prompt
+	TitleClash
+)
+
+// String returns the status as shown in the preview
+func (s Status) String() string {
+	switch s {
+	case Identical:
+		return "identical"
+	case Changed:
+		return "changed"
+	case TitleClash:
+		return "title clash"
+	default:
+		return "new"
+	}
+}
+
+// Action is what importing does with an incoming prompt
+type Action int
+
+const (
+	// Import creates a new prompt file
+	Import Action = iota
+	// Skip leaves the library alone
+	Skip
+	// Overwrite replaces the matching prompt, keeping its favorite flag
+	Overwrite
+	// KeepBoth creates a new prompt file beside the matching one
+	KeepBoth
+	// MergeTags adds the incoming tags to the matching prompt
+	MergeTags
+)
+
+var actionNames = map[Action]string{
+	Import:    "import",
+	Skip:      "skip",
+	Overwrite: "overwrite",
+	KeepBoth:  "keep both",
+	MergeTags: "merge tags",
+}
+
+// String returns the action as shown in the preview
+func (a Action) String() string {
+	return actionNames[a]
+}
+
+// ParseAction parses an action name, accepting dashes for spaces
+func ParseAction(s string) (Action, error) {
+	name := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "-", " ")
+	for a, n := range actionNames {
+		if n == name {
+			return a, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown import action: %q", s)
+}
+
+// Incoming is a prompt read from an import source
+type Incoming = formats.Entry
+
+// Item is an incoming prompt in a plan
+type Item struct {
+	Incoming
+	Status   Status
+	Existing *prompt.Prompt // the matching library prompt, nil for New
+	Action   Action
+}
+
+// Actions returns the actions allowed for the item's status
+func (i *Item) Actions() []Action {
+	switch i.Status {
+	case New:
+		return []Action{Import, Skip}
+	case Identical:
+		return []Action{Skip, KeepBoth}
+	default:
+		return []Action{Skip, Overwrite, KeepBoth, MergeTags}
+	}
+}
+
+// SetAction sets the item's action if its status allows it
+func (i *Item) SetAction(a Action) bool {
+	if !slices.Contains(i.Actions(), a) {
+		return false
+	}
+	i.Action = a
+	return true
+}
+
+// Plan is a previewed import: what each incoming prompt is and what
+// importing will do with it
+type Plan struct {
+	Items   []*Item
+	Skipped []string // files that are not prompts, with the reason
+}
+
+// NewPlan compares incoming prompts with the prompts of the library at
+// root. A prompt at the same path relative to root as a library prompt is
+// identical or changed; one with only the same title is identical or a
+// title clash. Paths include group folders, so prompts sharing a file name
+// in different groups don't match. By default new prompts are imported,
+// changed ones overwrite, clashes keep both and identical ones are skipped.
+func NewPlan(incoming []Incoming, library []*prompt.Prompt, root string) *Plan {
+	byPath := make(map[string]*prompt.Prompt)
+	byTitle := make(map[string]*prompt.Prompt)
+	for _, p := range library {
+		if rel, err := filepath.Rel(root, p.FilePath); err == nil {
+			byPath[strings.ToLower(filepath.ToSlash(rel))] = p
+		}
+		byTitle[strings.ToLower(p.Title)] = p
+	}
+
+	plan := &Plan{}
+	for _, in := range incoming {
+		item := &Item{Incoming: in}
+		if existing, ok := byPath[strings.ToLower(in.Path)]; ok && in.Path != "" {
+			item.Existing = existing
+			item.Status = Changed
+			if samePrompt(existing, in.Prompt) {
+				item.Status = Identical
+			}
+		} else if existing, ok := byTitle[strings.ToLower(in.Prompt.Title)]; ok {
+			item.Existing = existing
+			item.Status = TitleClash
+			if samePrompt(existing, in.Prompt) {
+				item.Status = Identical
+			}
+		}
+
+		switch item.Status {
+		case New:
+			item.Action = Import
+		case Identical:
+			item.Action = Skip
+		case Changed:
+			item.Action = Overwrite
+		case TitleClash:
+			item.Action = KeepBoth
+		}
+		plan.Items = append(plan.Items, item)
+	}
+	return plan
+}
+
+// SetActions sets the action of every item with status, where allowed
+func (p *Plan) SetActions(status Status, a Action) {
+	for _, item := range p.Items {
+		if item.Status == status {
+			item.SetAction(a)
+		}
+	}
+}
+
+// Count returns how many items have status
+func (p *Plan) Count(status Status) int {
+	n := 0
+	for _, item := range p.Items {
+		if item.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Change is a file written by Apply. Before is the file's previous content,
+// or nil if Apply created it.
+type Change struct {
+	Path   string
+	Before []byte
+}
+
+// Result is the outcome of applying a plan
+type Result struct {
+	Changes []Change
+	Skipped int
+}
+
+// Created returns the paths of the files Apply created
+func (r Result) Created() []string {
+	var paths []string
+	for _, c := range r.Changes {
+		if c.Before == nil {
+			paths = append(paths, c.Path)
+		}
+	}
+	return paths
+}
+
+// Apply carries out the plan's actions on the library at root. New files go
+// in their group's folder when groupFolders is set; overwritten prompts stay
+// where they are. It carries on past failures and returns them together.
+func Apply(plan *Plan, root string, groupFolders bool) (Result, error) {
+	var result Result
+	var errs []error
+	for _, item := range plan.Items {
+		change, err := apply(item, root, groupFolders)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", item.Source, err))
+			continue
+		}
+		if change == nil {
+			result.Skipped++
+			continue
+		}
+		result.Changes = append(result.Changes, *change)
+	}
+	return result, errors.Join(errs...)
+}
+
+func apply(item *Item, root string, groupFolders bool) (*Change, error) {
+	in := *item.Prompt
+	in.Favorite = false
+
+	switch item.Action {
+	case Import, KeepBoth:
+		dir := prompt.PromptDir(root, in.Group, groupFolders)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create group folder: %w", err)
+		}
+		created, err := prompt.CreatePromptFile(dir, &in)
+		if err != nil {
+			return nil, err
+		}
+		return &Change{Path: created}, nil
+
+	case Overwrite, MergeTags:
+		existing := item.Existing
+		before, err := os.ReadFile(existing.FilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", existing.FileName, err)
+		}
+
+		edited := in
+		edited.Favorite = existing.Favorite
+		if item.Action == MergeTags {
+			edited = *existing
+			edited.Tags = mergeTags(existing.Tags, in.Tags)
+			if len(edited.Tags) == len(existing.Tags) {
+				return nil, nil // No new tags
+			}
+		}
+		if err := existing.Replace(&edited); err != nil {
+			return nil, err
+		}
+		return &Change{Path: existing.FilePath, Before: before}, nil
+
+	default:
+		return nil, nil
+	}
+}
+
+// mergeTags returns tags followed by the extra tags it lacks
+func mergeTags(tags, extra []string) []string {
+	merged := slices.Clone(tags)
+	for _, tag := range extra {
+		if !slices.ContainsFunc(merged, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
+
+// samePrompt reports whether a and b have the same fields and content,
+// ignoring the personal favorite flag
+func samePrompt(a, b *prompt.Prompt) bool {
+	x, y := *a, *b
+	x.Favorite, y.Favorite = false, false
+	return x.ToMarkdown() == y.ToMarkdown()
+}
+
+// Format returns the importer for src: the one with the given ID, or the
+// one that recognises src when id is empty
+func Format(src, id string) (formats.Importer, error) {
+	if id == "" {
+		return formats.Detect(src)
+	}
+	return formats.Lookup(id)
+}
@@ -0,0 +1,248 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/grantcarthew/cuecard/internal/bundle"
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setupLibrary creates a library with two prompts and returns it loaded
+func setupLibrary(t *testing.T) (string, []*prompt.Prompt) {
+	t.Helper()
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "code-review.md"), "---\ntitle: Code Review\ntags: [review]\nfavorite: true\n---\n\nReview this.")
+	writeFile(t, filepath.Join(root, "summarize.md"), "---\ntitle: Summarize\n---\n\nSummarize this.")
+	library, err := prompt.LoadDirectory(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root, library
+}
+
+func incoming(source, content string) Incoming {
+	p, err := prompt.Parse(content)
+	if err != nil {
+		panic(err)
+	}
+	return Incoming{Source: source, Path: source, Prompt: p}
+}
+
+func TestNewPlan(t *testing.T) {
+	root, library := setupLibrary(t)
+
+	plan := NewPlan([]Incoming{
+		incoming("code-review.md", "---\ntitle: Code Review\ntags: [review]\n---\n\nReview this."),
+		incoming("summarize.md", "---\ntitle: Summarize\n---\n\nSummarize briefly."),
+		incoming("other.md", "---\ntitle: code review\n---\n\nSomething else."),
+		incoming("fresh.md", "---\ntitle: Fresh\n---\n\nNew."),
+	}, library, root)
+
+	tests := []struct {
+		status Status
+		action Action
+	}{
+		{Identical, Skip},
+		{Changed, Overwrite},
+		{TitleClash, KeepBoth},
+		{New, Import},
+	}
+	for i, tt := range tests {
+		item := plan.Items[i]
+		if item.Status != tt.status || item.Action != tt.action {
+			t.Errorf("%s: status %v action %v, want %v %v", item.Source, item.Status, item.Action, tt.status, tt.action)
+		}
+	}
+	if plan.Items[3].Existing != nil {
+		t.Error("new item has an existing prompt")
+	}
+	if plan.Items[2].Existing == nil || plan.Items[2].Existing.FileName != "code-review.md" {
+		t.Errorf("title clash matched %+v", plan.Items[2].Existing)
+	}
+}
+
+func TestItem_SetAction(t *testing.T) {
+	item := &Item{Status: New, Action: Import}
+	if item.SetAction(Overwrite) || item.Action != Import {
+		t.Error("SetAction(Overwrite) allowed for a new prompt")
+	}
+	if !item.SetAction(Skip) || item.Action != Skip {
+		t.Error("SetAction(Skip) not allowed for a new prompt")
+	}
+}
+
+func TestApply(t *testing.T) {
+	root, library := setupLibrary(t)
+
+	plan := NewPlan([]Incoming{
+		incoming("code-review.md", "---\ntitle: Code Review\ntags: [review, go]\n---\n\nReview this carefully."),
+		incoming("summarize.md", "---\ntitle: Summarize\ntags: [writing]\n---\n\nSummarize briefly."),
+		incoming("other.md", "---\ntitle: Summarize\n---\n\nAnother summary."),
+		incoming("fresh.md", "---\ntitle: Fresh\ngroup: New Group\nfavorite: true\n---\n\nNew."),
+	}, library, root)
+	plan.Items[1].SetAction(MergeTags)
+
+	result, err := Apply(plan, root, true)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(result.Changes) != 4 || result.Skipped != 0 {
+		t.Fatalf("Apply() = %+v", result)
+	}
+
+	// Overwrite keeps the library prompt's favorite flag
+	review, err := prompt.LoadFile(filepath.Join(root, "code-review.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if review.Content != "Review this carefully." || !review.Favorite {
+		t.Errorf("overwritten prompt = %+v", review)
+	}
+	if !strings.Contains(string(result.Changes[0].Before), "Review this.") {
+		t.Errorf("Before = %q, want the old content", result.Changes[0].Before)
+	}
+
+	// Merge tags keeps the content
+	summary, err := prompt.LoadFile(filepath.Join(root, "summarize.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Content != "Summarize this." || !slices.Equal(summary.Tags, []string{"writing"}) {
+		t.Errorf("merged prompt = %+v", summary)
+	}
+
+	// Keep both and new prompts are created, in group folders when enabled
+	created := result.Created()
+	want := []string{filepath.Join(root, "summarize-2.md"), filepath.Join(root, "new-group", "fresh.md")}
+	if !slices.Equal(created, want) {
+		t.Errorf("Created() = %v, want %v", created, want)
+	}
+	fresh, err := prompt.LoadFile(want[1])
+	if err != nil || fresh.Favorite {
+		t.Errorf("imported prompt = %+v, %v, want favorite cleared", fresh, err)
+	}
+}
+
+func TestApply_Reimport(t *testing.T) {
+	root, library := setupLibrary(t)
+	src := t.TempDir()
+	writeFile(t, filepath.Join(src, "code-review.md"), "---\ntitle: Code Review\ntags: [review]\n---\n\nReview this.")
+
+	imp, err := Format(src, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	in, _, err := imp.Import(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Apply(NewPlan(in, library, root), root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changes) != 0 || result.Skipped != 1 {
+		t.Errorf("re-import = %+v, want skipped", result)
+	}
+	entries, _ := os.ReadDir(root)
+	if len(entries) != 2 {
+		t.Errorf("library has %d files after re-import, want 2", len(entries))
+	}
+}
+
+func TestFormat_Bundle(t *testing.T) {
+	root, library := setupLibrary(t)
+	path := filepath.Join(t.TempDir(), "bundle.zip")
+	if _, err := bundle.ExportFile(path, library, root, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	imp, err := Format(path, "")
+	if err != nil || imp.ID() != "bundle" {
+		t.Fatalf("Format() = %v, %v, want bundle", imp, err)
+	}
+	in, _, err := imp.Import(path)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	plan := NewPlan(in, library, root)
+	if n := plan.Count(Identical); n != 2 {
+		t.Errorf("re-importing an export: %d identical, want 2", n)
+	}
+}
+
+func TestNewPlan_GroupFolders(t *testing.T) {
+	root := t.TempDir()
+	var library []*prompt.Prompt
+	for _, group := range []string{"code", "writing"} {
+		path := filepath.Join(root, group, "review.md")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, path, "---\ntitle: Review "+group+"\ngroup: "+group+"\n---\n\nReview this.")
+		p, err := prompt.LoadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		library = append(library, p)
+	}
+
+	// The same file name in two group folders matches by the whole path
+	plan := NewPlan([]Incoming{
+		incoming("code/review.md", "---\ntitle: Review code\ngroup: code\n---\n\nReview this carefully."),
+		incoming("writing/review.md", "---\ntitle: Review writing\ngroup: writing\n---\n\nReview this."),
+		incoming("review.md", "---\ntitle: Review elsewhere\n---\n\nReview."),
+	}, library, root)
+
+	tests := []struct {
+		status   Status
+		existing string
+	}{
+		{Changed, filepath.Join(root, "code", "review.md")},
+		{Identical, filepath.Join(root, "writing", "review.md")},
+		{New, ""},
+	}
+	for i, tt := range tests {
+		item := plan.Items[i]
+		var existing string
+		if item.Existing != nil {
+			existing = item.Existing.FilePath
+		}
+		if item.Status != tt.status || existing != tt.existing {
+			t.Errorf("%s: status %v existing %q, want %v %q", item.Source, item.Status, existing, tt.status, tt.existing)
+		}
+	}
+}
+
+func TestNewPlan_ForeignFormat(t *testing.T) {
+	root, library := setupLibrary(t)
+
+	// Prompts from other formats have no path, so match only by title
+	in := incoming("summarize.md", "---\ntitle: Other\n---\n\nSummarize this.")
+	in.Path = ""
+	plan := NewPlan([]Incoming{in}, library, root)
+	if plan.Items[0].Status != New {
+		t.Errorf("status = %v, want new", plan.Items[0].Status)
+	}
+}
+
+func TestParseAction(t *testing.T) {
+	for _, s := range []string{"skip", "Overwrite", "keep-both", "merge tags"} {
+		if _, err := ParseAction(s); err != nil {
+			t.Errorf("ParseAction(%q) error = %v", s, err)
+		}
+	}
+	if _, err := ParseAction("delete"); err == nil {
+		t.Error("ParseAction(delete) error = nil")
+	}
+}
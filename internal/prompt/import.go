@@ -0,0 +1,81 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// ReadImportFile reads a file to import as a prompt. Frontmatter is parsed
+// as on load; without it the whole file is the content and the title comes
+// from the file name. hasFrontmatter reports which it was. The favorite flag
+// is personal, so it is cleared.
+func ReadImportFile(path string) (p *Prompt, hasFrontmatter bool, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read import file: %w", err)
+	}
+	content := string(data)
+
+	hasFrontmatter = HasFrontmatter(content)
+	if hasFrontmatter {
+		p, err = Parse(content)
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid frontmatter in %s: %w", filepath.Base(path), err)
+		}
+	} else {
+		// Parse would take an unclosed "---" for broken frontmatter
+		p = &Prompt{Content: strings.TrimSpace(content)}
+	}
+	if p.Title == "" {
+		p.Title = TitleFromFileName(filepath.Base(path))
+	}
+	p.Favorite = false
+	return p, hasFrontmatter, nil
+}
+
+// ImportFile creates a prompt file in dir for p, imported from the file
+// named srcName. With keepName the new file takes the source's name, as
+// markdown and with a numeric suffix if it is taken; otherwise the name
+// comes from the title. It never replaces an existing file.
+func ImportFile(dir string, p *Prompt, srcName string, keepName bool) (string, error) {
+	if !keepName {
+		return CreatePromptFile(dir, p)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read directory: %w", err)
+	}
+	var existingFiles []string
+	for _, e := range entries {
+		existingFiles = append(existingFiles, e.Name())
+	}
+
+	stem := strings.TrimSuffix(filepath.Base(srcName), filepath.Ext(srcName))
+	if stem == "" || strings.HasPrefix(stem, ".") {
+		return CreatePromptFile(dir, p)
+	}
+	path := filepath.Join(dir, uniqueFilename(stem, existingFiles))
+	if err := writeNewFile(path, []byte(p.ToMarkdown()), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// TitleFromFileName turns a file name such as "code_review.txt" into a
+// title such as "Code Review"
+func TitleFromFileName(name string) string {
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.' || unicode.IsSpace(r)
+	})
+	for i, w := range words {
+		runes := []rune(w)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
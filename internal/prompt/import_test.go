@@ -0,0 +1,134 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadImportFile(t *testing.T) {
+	tests := []struct {
+		name            string
+		file            string
+		content         string
+		wantTitle       string
+		wantContent     string
+		wantFrontmatter bool
+	}{
+		{
+			name:            "frontmatter",
+			file:            "review.md",
+			content:         "---\ntitle: Review\nfavorite: true\n---\n\nReview this.",
+			wantTitle:       "Review",
+			wantContent:     "Review this.",
+			wantFrontmatter: true,
+		},
+		{
+			name:            "crlf",
+			file:            "review.md",
+			content:         "---\r\ntitle: Review\r\n---\r\n\r\nLine one.\r\nLine two.",
+			wantTitle:       "Review",
+			wantContent:     "Line one.\r\nLine two.",
+			wantFrontmatter: true,
+		},
+		{
+			name:            "horizontal rule in body",
+			file:            "notes.md",
+			content:         "---\ntitle: Notes\n---\n\nAbove\n\n---\n\nBelow",
+			wantTitle:       "Notes",
+			wantContent:     "Above\n\n---\n\nBelow",
+			wantFrontmatter: true,
+		},
+		{
+			name:            "no title in frontmatter",
+			file:            "code_review.md",
+			content:         "---\ngroup: Coding\n---\n\nBody",
+			wantTitle:       "Code Review",
+			wantContent:     "Body",
+			wantFrontmatter: true,
+		},
+		{
+			name:        "plain text",
+			file:        "cover-letter.txt",
+			content:     "Write a cover letter.\n\n---\n\nSigned",
+			wantTitle:   "Cover Letter",
+			wantContent: "Write a cover letter.\n\n---\n\nSigned",
+		},
+		{
+			name:        "unclosed delimiter",
+			file:        "rule.md",
+			content:     "---\nNot frontmatter",
+			wantTitle:   "Rule",
+			wantContent: "---\nNot frontmatter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			p, hasFrontmatter, err := ReadImportFile(path)
+			if err != nil {
+				t.Fatalf("ReadImportFile() error = %v", err)
+			}
+			if hasFrontmatter != tt.wantFrontmatter {
+				t.Errorf("hasFrontmatter = %v, want %v", hasFrontmatter, tt.wantFrontmatter)
+			}
+			if p.Title != tt.wantTitle || p.Content != tt.wantContent || p.Favorite {
+				t.Errorf("prompt = %+v, want title %q content %q", p, tt.wantTitle, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestImportFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "my_notes.md"), []byte("taken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p := &Prompt{Title: "Review Notes", Content: "Body"}
+
+	tests := []struct {
+		name     string
+		srcName  string
+		keepName bool
+		want     string
+	}{
+		{"title", "my_notes.md", false, "review-notes.md"},
+		{"keep name", "draft.md", true, "draft.md"},
+		{"keep name as markdown", "snippet.txt", true, "snippet.md"},
+		{"keep name taken", "my_notes.md", true, "my_notes-2.md"},
+		{"hidden name", ".env", true, "review-notes-2.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := ImportFile(dir, p, tt.srcName, tt.keepName)
+			if err != nil {
+				t.Fatalf("ImportFile() error = %v", err)
+			}
+			if got := filepath.Base(path); got != tt.want {
+				t.Errorf("ImportFile() = %s, want %s", got, tt.want)
+			}
+			imported, err := LoadFile(path)
+			if err != nil || imported.Title != "Review Notes" || imported.Content != "Body" {
+				t.Errorf("imported = %+v, %v", imported, err)
+			}
+		})
+	}
+}
+
+func TestTitleFromFileName(t *testing.T) {
+	tests := map[string]string{
+		"code_review.txt":   "Code Review",
+		"write-essay":       "Write Essay",
+		"already Titled.md": "Already Titled",
+		"x":                 "X",
+	}
+	for name, want := range tests {
+		if got := TitleFromFileName(name); got != want {
+			t.Errorf("TitleFromFileName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
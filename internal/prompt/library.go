@@ -0,0 +1,136 @@
+package prompt
+
+import (
+	"sync"
+)
+
+// Library is a concurrency-safe collection of prompts loaded from a directory.
+// Readers get snapshots, writers swap the whole set atomically, and
+// subscribers are notified after every swap.
+type Library struct {
+	mu      sync.RWMutex
+	loadMu  sync.Mutex
+	dir     string
+	groups  bool // whether group folders are loaded
+	prompts []*Prompt
+
+	subMu  sync.Mutex
+	subs   map[int]func([]*Prompt)
+	nextID int
+}
+
+// NewLibrary creates an empty library for the given directory
+func NewLibrary(dir string) *Library {
+	return &Library{
+		dir:  dir,
+		subs: make(map[int]func([]*Prompt)),
+	}
+}
+
+// Dir returns the directory the library loads from
+func (l *Library) Dir() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.dir
+}
+
+// SetDir re-points the library at a new directory and reloads it
+func (l *Library) SetDir(dir string) error {
+	l.mu.Lock()
+	l.dir = dir
+	l.mu.Unlock()
+	return l.Reload()
+}
+
+// SetGroupFolders sets whether the directory's group folders are loaded.
+// It takes effect on the next reload.
+func (l *Library) SetGroupFolders(on bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.groups = on
+}
+
+// Reload reads the directory from disk and swaps in the result
+func (l *Library) Reload() error {
+	// Serialise reloads so a slow read can't overwrite a newer one
+	l.loadMu.Lock()
+	defer l.loadMu.Unlock()
+
+	l.mu.RLock()
+	dir, groups := l.dir, l.groups
+	l.mu.RUnlock()
+	prompts, err := LoadDirectory(dir, groups)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	if l.dir != dir {
+		// Re-pointed while loading; the newer reload wins
+		l.mu.Unlock()
+		return nil
+	}
+	l.prompts = prompts
+	l.mu.Unlock()
+
+	l.notify(prompts)
+	return nil
+}
+
+// Swap atomically replaces the prompts and notifies subscribers
+func (l *Library) Swap(prompts []*Prompt) {
+	l.mu.Lock()
+	l.prompts = prompts
+	l.mu.Unlock()
+
+	l.notify(prompts)
+}
+
+// Snapshot returns a copy of the current prompt list. The slice is safe to
+// modify; the prompts it points to are shared.
+func (l *Library) Snapshot() []*Prompt {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	snapshot := make([]*Prompt, len(l.prompts))
+	copy(snapshot, l.prompts)
+	return snapshot
+}
+
+// Len returns the number of prompts in the library
+func (l *Library) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.prompts)
+}
+
+// Subscribe registers fn to be called with a snapshot after every change.
+// Callbacks run on the goroutine that made the change. The returned function
+// removes the subscription.
+func (l *Library) Subscribe(fn func([]*Prompt)) (unsubscribe func()) {
+	l.subMu.Lock()
+	id := l.nextID
+	l.nextID++
+	l.subs[id] = fn
+	l.subMu.Unlock()
+
+	return func() {
+		l.subMu.Lock()
+		delete(l.subs, id)
+		l.subMu.Unlock()
+	}
+}
+
+func (l *Library) notify(prompts []*Prompt) {
+	l.subMu.Lock()
+	subs := make([]func([]*Prompt), 0, len(l.subs))
+	for _, fn := range l.subs {
+		subs = append(subs, fn)
+	}
+	l.subMu.Unlock()
+
+	for _, fn := range subs {
+		snapshot := make([]*Prompt, len(prompts))
+		copy(snapshot, prompts)
+		fn(snapshot)
+	}
+}
@@ -0,0 +1,214 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// writePromptFile writes a prompt titled title to name in dir. name may
+// include subfolders, which are created.
+func writePromptFile(t *testing.T, dir, name, title string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := fmt.Sprintf("---\ntitle: %s\n---\n\nContent for %s", title, title)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLibrary_Reload(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "a.md", "A")
+	writePromptFile(t, dir, "b.md", "B")
+
+	lib := NewLibrary(dir)
+	if lib.Len() != 0 {
+		t.Fatalf("new library Len() = %d, want 0", lib.Len())
+	}
+
+	if err := lib.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if lib.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", lib.Len())
+	}
+
+	writePromptFile(t, dir, "c.md", "C")
+	if err := lib.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if lib.Len() != 3 {
+		t.Errorf("Len() after adding file = %d, want 3", lib.Len())
+	}
+}
+
+func TestLibrary_GroupFolders(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "a.md", "A")
+	writePromptFile(t, dir, filepath.Join("coding", "b.md"), "B")
+
+	lib := NewLibrary(dir)
+	if err := lib.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if lib.Len() != 1 {
+		t.Errorf("Len() without group folders = %d, want 1", lib.Len())
+	}
+
+	lib.SetGroupFolders(true)
+	if err := lib.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if lib.Len() != 2 {
+		t.Errorf("Len() with group folders = %d, want 2", lib.Len())
+	}
+}
+
+func TestLibrary_ReloadMissingDir(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "a.md", "A")
+
+	lib := NewLibrary(dir)
+	if err := lib.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if err := lib.SetDir(filepath.Join(dir, "missing")); err == nil {
+		t.Error("SetDir() to missing directory should return error")
+	}
+	// The last good snapshot is kept
+	if lib.Len() != 1 {
+		t.Errorf("Len() after failed reload = %d, want 1", lib.Len())
+	}
+}
+
+func TestLibrary_SetDir(t *testing.T) {
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	writePromptFile(t, dir1, "a.md", "A")
+	writePromptFile(t, dir2, "b.md", "B")
+	writePromptFile(t, dir2, "c.md", "C")
+
+	lib := NewLibrary(dir1)
+	if err := lib.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := lib.SetDir(dir2); err != nil {
+		t.Fatalf("SetDir() error = %v", err)
+	}
+	if lib.Dir() != dir2 {
+		t.Errorf("Dir() = %q, want %q", lib.Dir(), dir2)
+	}
+	if lib.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", lib.Len())
+	}
+}
+
+func TestLibrary_SnapshotIsCopy(t *testing.T) {
+	lib := NewLibrary("")
+	lib.Swap([]*Prompt{{Title: "A"}, {Title: "B"}})
+
+	snapshot := lib.Snapshot()
+	snapshot[0] = &Prompt{Title: "Changed"}
+	snapshot = append(snapshot, &Prompt{Title: "C"})
+
+	again := lib.Snapshot()
+	if len(again) != 2 {
+		t.Fatalf("Snapshot() len = %d, want 2", len(again))
+	}
+	if again[0].Title != "A" {
+		t.Errorf("Snapshot()[0].Title = %q, want A", again[0].Title)
+	}
+}
+
+func TestLibrary_Subscribe(t *testing.T) {
+	lib := NewLibrary("")
+
+	var got [][]*Prompt
+	unsubscribe := lib.Subscribe(func(prompts []*Prompt) {
+		got = append(got, prompts)
+	})
+
+	lib.Swap([]*Prompt{{Title: "A"}})
+	lib.Swap([]*Prompt{{Title: "A"}, {Title: "B"}})
+
+	if len(got) != 2 {
+		t.Fatalf("subscriber called %d times, want 2", len(got))
+	}
+	if len(got[1]) != 2 {
+		t.Errorf("second notification len = %d, want 2", len(got[1]))
+	}
+
+	unsubscribe()
+	lib.Swap(nil)
+
+	if len(got) != 2 {
+		t.Errorf("subscriber called after unsubscribe")
+	}
+}
+
+func TestLibrary_ConcurrentReloadAndRead(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 10; i++ {
+		writePromptFile(t, dir, fmt.Sprintf("p%d.md", i), fmt.Sprintf("Prompt %d", i))
+	}
+
+	lib := NewLibrary(dir)
+	if err := lib.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	unsubscribe := lib.Subscribe(func(prompts []*Prompt) {
+		// Subscribers may read and reorder their snapshot freely
+		GroupPrompts(prompts)
+	})
+	defer unsubscribe()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if err := lib.Reload(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				snapshot := lib.Snapshot()
+				if len(snapshot) != 10 {
+					t.Errorf("Snapshot() len = %d, want 10", len(snapshot))
+					return
+				}
+				Filter(snapshot, "prompt")
+				_ = lib.Len()
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 50; j++ {
+			unsub := lib.Subscribe(func([]*Prompt) {})
+			unsub()
+		}
+	}()
+
+	wg.Wait()
+}
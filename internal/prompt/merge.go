@@ -0,0 +1,122 @@
+package prompt
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/grantcarthew/cuecard/internal/diff"
+)
+
+// Conflict markers written around content both sides changed
+const (
+	conflictStart = "<<<<<<< yours"
+	conflictSep   = "======="
+	conflictEnd   = ">>>>>>> on disk"
+)
+
+// Merge three-way merges two edits of base: mine, made in the app, and
+// theirs, found on disk. Frontmatter fields changed on only one side take
+// that side's value; a field both changed keeps mine. Content is merged line
+// by line, and lines both sides changed differently are wrapped in conflict
+// markers. clean reports whether there were no conflicts. The result has
+// theirs's file information.
+func Merge(base, mine, theirs *Prompt) (merged *Prompt, clean bool) {
+	clean = true
+	pick := func(b, m, t string) string {
+		switch {
+		case m == b:
+			return t
+		case t == b || t == m:
+			return m
+		}
+		clean = false
+		return m
+	}
+
+	result := *theirs
+	result.Title = pick(base.Title, mine.Title, theirs.Title)
+	result.Description = pick(base.Description, mine.Description, theirs.Description)
+	result.Group = pick(base.Group, mine.Group, theirs.Group)
+	result.Alias = pick(base.Alias, mine.Alias, theirs.Alias)
+	result.Input = pick(base.Input, mine.Input, theirs.Input)
+	result.InputHint = pick(base.InputHint, mine.InputHint, theirs.InputHint)
+
+	switch {
+	case slices.Equal(mine.Tags, base.Tags):
+		result.Tags = slices.Clone(theirs.Tags)
+	case slices.Equal(theirs.Tags, base.Tags) || slices.Equal(theirs.Tags, mine.Tags):
+		result.Tags = slices.Clone(mine.Tags)
+	default:
+		result.Tags = slices.Clone(mine.Tags)
+		clean = false
+	}
+
+	// Toggling a favorite either way is not worth a conflict
+	result.Favorite = mine.Favorite
+	if mine.Favorite == base.Favorite {
+		result.Favorite = theirs.Favorite
+	}
+
+	// Reordering is not worth a conflict either
+	result.Order = mine.Order
+	if mine.Order == base.Order {
+		result.Order = theirs.Order
+	}
+
+	content, contentClean := mergeLines(base.Content, mine.Content, theirs.Content)
+	result.Content = content
+	return &result, clean && contentClean
+}
+
+// mergeLines three-way merges text line by line
+func mergeLines(base, mine, theirs string) (string, bool) {
+	switch {
+	case mine == base || mine == theirs:
+		return theirs, true
+	case theirs == base:
+		return mine, true
+	}
+
+	b := strings.Split(base, "\n")
+	m := strings.Split(mine, "\n")
+	t := strings.Split(theirs, "\n")
+	toMine := diff.Match(b, m)
+	toTheirs := diff.Match(b, t)
+
+	var out []string
+	clean := true
+	bi, mi, ti := 0, 0, 0
+	for {
+		// Find the next base line kept unchanged on both sides
+		next := bi
+		for next < len(b) && (toMine[next] < mi || toTheirs[next] < ti) {
+			next++
+		}
+		mEnd, tEnd := len(m), len(t)
+		if next < len(b) {
+			mEnd, tEnd = toMine[next], toTheirs[next]
+		}
+
+		baseChunk, mineChunk, theirsChunk := b[bi:next], m[mi:mEnd], t[ti:tEnd]
+		switch {
+		case slices.Equal(mineChunk, baseChunk):
+			out = append(out, theirsChunk...)
+		case slices.Equal(theirsChunk, baseChunk) || slices.Equal(theirsChunk, mineChunk):
+			out = append(out, mineChunk...)
+		default:
+			clean = false
+			out = append(out, conflictStart)
+			out = append(out, mineChunk...)
+			out = append(out, conflictSep)
+			out = append(out, theirsChunk...)
+			out = append(out, conflictEnd)
+		}
+
+		if next >= len(b) {
+			break
+		}
+		out = append(out, b[next])
+		bi, mi, ti = next+1, mEnd+1, tEnd+1
+	}
+	return strings.Join(out, "\n"), clean
+}
@@ -0,0 +1,103 @@
+package prompt
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMergeLines(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		mine      string
+		theirs    string
+		want      string
+		wantClean bool
+	}{
+		{
+			name:      "only mine changed",
+			base:      "a\nb\nc",
+			mine:      "a\nB\nc",
+			theirs:    "a\nb\nc",
+			want:      "a\nB\nc",
+			wantClean: true,
+		},
+		{
+			name:      "only theirs changed",
+			base:      "a\nb\nc",
+			mine:      "a\nb\nc",
+			theirs:    "a\nb\nc\nd",
+			want:      "a\nb\nc\nd",
+			wantClean: true,
+		},
+		{
+			name:      "different lines changed",
+			base:      "a\nb\nc\nd",
+			mine:      "A\nb\nc\nd",
+			theirs:    "a\nb\nc\nD",
+			want:      "A\nb\nc\nD",
+			wantClean: true,
+		},
+		{
+			name:      "insert and delete",
+			base:      "a\nb\nc",
+			mine:      "a\nnew\nb\nc",
+			theirs:    "a\nb",
+			want:      "a\nnew\nb",
+			wantClean: true,
+		},
+		{
+			name:      "same change both sides",
+			base:      "a\nb\nc",
+			mine:      "a\nX\nc",
+			theirs:    "a\nX\nc",
+			want:      "a\nX\nc",
+			wantClean: true,
+		},
+		{
+			name:   "conflicting change",
+			base:   "a\nb\nc",
+			mine:   "a\nmine\nc",
+			theirs: "a\ntheirs\nc",
+			want:   "a\n<<<<<<< yours\nmine\n=======\ntheirs\n>>>>>>> on disk\nc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, clean := mergeLines(tt.base, tt.mine, tt.theirs)
+			if got != tt.want {
+				t.Errorf("mergeLines() = %q, want %q", got, tt.want)
+			}
+			if clean != tt.wantClean {
+				t.Errorf("mergeLines() clean = %v, want %v", clean, tt.wantClean)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := &Prompt{Title: "Review", Group: "Code", Tags: []string{"a"}, Content: "one\ntwo"}
+	mine := &Prompt{Title: "Code Review", Group: "Code", Tags: []string{"a"}, Favorite: true, Content: "one\ntwo"}
+	theirs := &Prompt{Title: "Review", Group: "Coding", Tags: []string{"a", "b"}, Order: 3, Content: "one\ntwo\nthree", FilePath: "/p/review.md"}
+
+	merged, clean := Merge(base, mine, theirs)
+	if !clean {
+		t.Error("Merge() clean = false, want true")
+	}
+	if merged.Title != "Code Review" || merged.Group != "Coding" || !merged.Favorite || merged.Order != 3 {
+		t.Errorf("Merge() = %+v", merged)
+	}
+	if !slices.Equal(merged.Tags, []string{"a", "b"}) {
+		t.Errorf("Merge() tags = %v", merged.Tags)
+	}
+	if merged.Content != "one\ntwo\nthree" || merged.FilePath != "/p/review.md" {
+		t.Errorf("Merge() content = %q, path = %q", merged.Content, merged.FilePath)
+	}
+
+	// Both changed the title: mine wins but the merge isn't clean
+	theirs.Title = "Other"
+	if merged, clean = Merge(base, mine, theirs); clean || merged.Title != "Code Review" {
+		t.Errorf("Merge() title = %q, clean = %v", merged.Title, clean)
+	}
+}
@@ -0,0 +1,80 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/grantcarthew/cuecard/internal/fileutil"
+)
+
+// ErrFileExists is returned when a move would overwrite another file
+var ErrFileExists = fileutil.ErrFileExists
+
+// GroupFolder returns the subfolder name used for a group, or "" for
+// ungrouped prompts
+func GroupFolder(group string) string {
+	return slugify(group)
+}
+
+// PromptDir returns the directory new prompts in group are created in
+func PromptDir(root, group string, groupFolders bool) string {
+	if !groupFolders {
+		return root
+	}
+	return filepath.Join(root, GroupFolder(group))
+}
+
+// TargetPath returns where p belongs under root. With rename set the file
+// name is generated from the title, otherwise the current name is kept. With
+// groupFolders set the file goes in its group's folder, otherwise it stays
+// in its current directory.
+func TargetPath(p *Prompt, root string, rename, groupFolders bool) (string, error) {
+	dir := filepath.Dir(p.FilePath)
+	if groupFolders {
+		dir = PromptDir(root, p.Group, true)
+	}
+
+	name := filepath.Base(p.FilePath)
+	if rename {
+		entries, err := os.ReadDir(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read directory: %w", err)
+		}
+		var existing []string
+		for _, e := range entries {
+			// The prompt's own file doesn't block its name
+			if filepath.Join(dir, e.Name()) != p.FilePath {
+				existing = append(existing, e.Name())
+			}
+		}
+		name = GenerateFilename(p.Title, existing)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// Move moves p's file to dst without overwriting an existing file and
+// updates FilePath and FileName. A group folder under root left empty is
+// removed.
+func Move(p *Prompt, root, dst string) error {
+	src := p.FilePath
+	if src == dst {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := fileutil.MoveFile(src, dst); err != nil {
+		return err
+	}
+
+	p.FilePath = dst
+	p.FileName = filepath.Base(dst)
+
+	// Only an empty directory can be removed, so a shared folder survives
+	if dir := filepath.Dir(src); dir != filepath.Dir(dst) && filepath.Dir(dir) == filepath.Clean(root) {
+		_ = os.Remove(dir)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestTargetPath(t *testing.T) {
+	root := t.TempDir()
+	writePromptFile(t, root, "code-review.md", "Code Review")
+	writePromptFile(t, root, "old-name.md", "Old Name")
+
+	tests := []struct {
+		name         string
+		file         string
+		title        string
+		group        string
+		rename       bool
+		groupFolders bool
+		want         string
+	}{
+		{
+			name:  "unchanged",
+			file:  "old-name.md",
+			title: "New Name",
+			want:  "old-name.md",
+		},
+		{
+			name:   "rename to match title",
+			file:   "old-name.md",
+			title:  "New Name",
+			rename: true,
+			want:   "new-name.md",
+		},
+		{
+			name:   "already matches",
+			file:   "code-review.md",
+			title:  "Code Review",
+			rename: true,
+			want:   "code-review.md",
+		},
+		{
+			name:   "title taken by another file",
+			file:   "old-name.md",
+			title:  "Code Review",
+			rename: true,
+			want:   "code-review-2.md",
+		},
+		{
+			name:         "group folder",
+			file:         "old-name.md",
+			title:        "Old Name",
+			group:        "Coding Tips",
+			groupFolders: true,
+			want:         filepath.Join("coding-tips", "old-name.md"),
+		},
+		{
+			name:         "ungrouped goes to root",
+			file:         "old-name.md",
+			title:        "Old Name",
+			groupFolders: true,
+			want:         "old-name.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Prompt{Title: tt.title, Group: tt.group, FilePath: filepath.Join(root, tt.file)}
+			got, err := TargetPath(p, root, tt.rename, tt.groupFolders)
+			if err != nil {
+				t.Fatalf("TargetPath() error = %v", err)
+			}
+			if want := filepath.Join(root, tt.want); got != want {
+				t.Errorf("TargetPath() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestMove(t *testing.T) {
+	root := t.TempDir()
+	writePromptFile(t, root, "a.md", "A")
+	src := filepath.Join(root, "a.md")
+
+	p, err := LoadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Into a group folder, which is created
+	dst := filepath.Join(root, "coding", "a.md")
+	if err := Move(p, root, dst); err != nil {
+		t.Fatalf("Move() error = %v", err)
+	}
+	if p.FilePath != dst || p.FileName != "a.md" {
+		t.Errorf("prompt path = %q, %q", p.FilePath, p.FileName)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("source file still exists")
+	}
+
+	// Back out again; the empty group folder is removed
+	if err := Move(p, root, src); err != nil {
+		t.Fatalf("Move() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "coding")); !os.IsNotExist(err) {
+		t.Error("empty group folder not removed")
+	}
+	if _, err := os.Stat(root); err != nil {
+		t.Error("prompts directory removed")
+	}
+}
+
+func TestLoadDirectory_GroupFolders(t *testing.T) {
+	root := t.TempDir()
+	writePromptFile(t, root, "top.md", "Top")
+	writePromptFile(t, root, filepath.Join("coding", "nested.md"), "Nested")
+	writePromptFile(t, root, filepath.Join("coding", "deeper", "skipped.md"), "Too Deep")
+	writePromptFile(t, root, filepath.Join(".git", "hidden.md"), "Hidden")
+
+	tests := []struct {
+		groupFolders bool
+		want         []string
+	}{
+		{true, []string{"Nested", "Top"}},
+		{false, []string{"Top"}}, // Subfolders are only read as group folders
+	}
+	for _, tt := range tests {
+		prompts, err := LoadDirectory(root, tt.groupFolders)
+		if err != nil {
+			t.Fatalf("LoadDirectory() error = %v", err)
+		}
+		got := titles(prompts)
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("LoadDirectory(groupFolders=%v) titles = %v, want %v", tt.groupFolders, got, tt.want)
+		}
+	}
+}
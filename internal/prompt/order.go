@@ -0,0 +1,124 @@
+package prompt
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SortMode is how prompts are ordered within their sections, and how the
+// group sections are ordered
+type SortMode string
+
+const (
+	// SortAlphabetical orders prompts by title and groups by name
+	SortAlphabetical SortMode = "alphabetical"
+	// SortManual orders prompts by their order field, then by title
+	SortManual SortMode = "manual"
+	// SortMostUsed orders prompts by how often they were copied
+	SortMostUsed SortMode = "most-used"
+	// SortRecent orders prompts by when their file was last modified
+	SortRecent SortMode = "recent"
+)
+
+// SortModes lists the sort modes in the order they are offered
+var SortModes = []SortMode{SortAlphabetical, SortManual, SortMostUsed, SortRecent}
+
+// Ordering sorts prompts and group sections by a sort mode
+type Ordering struct {
+	Mode SortMode
+	Uses map[string]int // copies per prompt file path, for SortMostUsed
+}
+
+// ModTime returns when the prompt's file was last modified as loaded or
+// saved, or the zero time if it hasn't been
+func (p *Prompt) ModTime() time.Time {
+	return p.loaded.modTime
+}
+
+// Less reports whether a sorts before b. Ties, and prompts without a
+// manual order, fall back to alphabetical order.
+func (o Ordering) Less(a, b *Prompt) bool {
+	if c := o.compare(a, b); c != 0 {
+		return c < 0
+	}
+	return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+}
+
+// compare compares a and b by the sort mode alone, returning 0 for a tie
+func (o Ordering) compare(a, b *Prompt) int {
+	switch o.Mode {
+	case SortManual:
+		// Unordered prompts go after ordered ones
+		switch {
+		case a.Order == b.Order:
+			return 0
+		case a.Order <= 0:
+			return 1
+		case b.Order <= 0:
+			return -1
+		}
+		return cmp.Compare(a.Order, b.Order)
+	case SortMostUsed:
+		return cmp.Compare(o.Uses[b.FilePath], o.Uses[a.FilePath])
+	case SortRecent:
+		return b.ModTime().Compare(a.ModTime())
+	}
+	return 0
+}
+
+// Sort sorts prompts in place
+func (o Ordering) Sort(prompts []*Prompt) {
+	sort.SliceStable(prompts, func(i, j int) bool {
+		return o.Less(prompts[i], prompts[j])
+	})
+}
+
+// GroupNames returns the names of groups in section order. Each group
+// sorts by its first prompt, so manual ordering puts groups holding ordered
+// prompts first and recent puts the group with the newest change first;
+// most used sorts by the group's total uses. Ties are alphabetical, and
+// groups must already be sorted by o.
+func (o Ordering) GroupNames(groups map[string][]*Prompt) []string {
+	names := SortedGroupNames(groups)
+	sort.SliceStable(names, func(i, j int) bool {
+		a, b := groups[names[i]], groups[names[j]]
+		if o.Mode == SortMostUsed {
+			return o.groupUses(a) > o.groupUses(b)
+		}
+		return o.compare(a[0], b[0]) < 0
+	})
+	return names
+}
+
+// groupUses returns the total copies of prompts
+func (o Ordering) groupUses(prompts []*Prompt) int {
+	n := 0
+	for _, p := range prompts {
+		n += o.Uses[p.FilePath]
+	}
+	return n
+}
+
+// GroupPromptsOrdered organizes prompts by group like GroupPrompts, sorting
+// each list by o
+func GroupPromptsOrdered(prompts []*Prompt, o Ordering) (favorites []*Prompt, groups map[string][]*Prompt, ungrouped []*Prompt) {
+	favorites, groups, ungrouped = GroupPrompts(prompts)
+	o.Sort(favorites)
+	o.Sort(ungrouped)
+	for _, list := range groups {
+		o.Sort(list)
+	}
+	return favorites, groups, ungrouped
+}
+
+// Reorder returns a copy of prompts with the prompt at from moved to index
+// to, shifting those between
+func Reorder(prompts []*Prompt, from, to int) []*Prompt {
+	moved := slices.Clone(prompts)
+	p := moved[from]
+	moved = slices.Delete(moved, from, from+1)
+	return slices.Insert(moved, to, p)
+}
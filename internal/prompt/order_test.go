@@ -0,0 +1,124 @@
+package prompt
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func titles(prompts []*Prompt) []string {
+	var out []string
+	for _, p := range prompts {
+		out = append(out, p.Title)
+	}
+	return out
+}
+
+func TestOrdering_Sort(t *testing.T) {
+	now := time.Now()
+	modified := func(p *Prompt, ago time.Duration) *Prompt {
+		p.loaded.modTime = now.Add(-ago)
+		return p
+	}
+	prompts := []*Prompt{
+		modified(&Prompt{Title: "delta", FilePath: "d.md", Order: 2}, time.Hour),
+		modified(&Prompt{Title: "Alpha", FilePath: "a.md"}, time.Minute),
+		modified(&Prompt{Title: "charlie", FilePath: "c.md", Order: 1}, 2*time.Hour),
+		modified(&Prompt{Title: "Bravo", FilePath: "b.md"}, time.Minute),
+	}
+	uses := map[string]int{"c.md": 5, "b.md": 2, "d.md": 2}
+
+	tests := []struct {
+		mode SortMode
+		want []string
+	}{
+		{SortAlphabetical, []string{"Alpha", "Bravo", "charlie", "delta"}},
+		{"", []string{"Alpha", "Bravo", "charlie", "delta"}},
+		{SortManual, []string{"charlie", "delta", "Alpha", "Bravo"}},
+		{SortMostUsed, []string{"charlie", "Bravo", "delta", "Alpha"}},
+		{SortRecent, []string{"Alpha", "Bravo", "delta", "charlie"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			sorted := slices.Clone(prompts)
+			Ordering{Mode: tt.mode, Uses: uses}.Sort(sorted)
+			if got := titles(sorted); !slices.Equal(got, tt.want) {
+				t.Errorf("Sort() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrdering_GroupNames(t *testing.T) {
+	prompts := []*Prompt{
+		{Title: "A", Group: "Zebra", FilePath: "a.md", Order: 1},
+		{Title: "B", Group: "Apple", FilePath: "b.md"},
+		{Title: "C", Group: "Middle", FilePath: "c.md"},
+		{Title: "D", Group: "Middle", FilePath: "d.md"},
+	}
+	uses := map[string]int{"c.md": 1, "d.md": 1, "b.md": 1}
+
+	tests := []struct {
+		mode SortMode
+		want []string
+	}{
+		{SortAlphabetical, []string{"Apple", "Middle", "Zebra"}},
+		{SortManual, []string{"Zebra", "Apple", "Middle"}},
+		{SortMostUsed, []string{"Middle", "Apple", "Zebra"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			o := Ordering{Mode: tt.mode, Uses: uses}
+			_, groups, _ := GroupPromptsOrdered(prompts, o)
+			if got := o.GroupNames(groups); !slices.Equal(got, tt.want) {
+				t.Errorf("GroupNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReorder(t *testing.T) {
+	prompts := []*Prompt{{Title: "A"}, {Title: "B"}, {Title: "C"}, {Title: "D"}}
+
+	tests := []struct {
+		name     string
+		from, to int
+		want     []string
+	}{
+		{"down", 0, 2, []string{"B", "C", "A", "D"}},
+		{"up", 3, 1, []string{"A", "D", "B", "C"}},
+		{"to end", 1, 3, []string{"A", "C", "D", "B"}},
+		{"in place", 2, 2, []string{"A", "B", "C", "D"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := titles(Reorder(prompts, tt.from, tt.to)); !slices.Equal(got, tt.want) {
+				t.Errorf("Reorder() = %v, want %v", got, tt.want)
+			}
+			if got := titles(prompts); !slices.Equal(got, []string{"A", "B", "C", "D"}) {
+				t.Errorf("Reorder() changed its input to %v", got)
+			}
+		})
+	}
+}
+
+func TestOrder_RoundTrip(t *testing.T) {
+	p := &Prompt{Title: "Ordered", Order: 4, Content: "Body"}
+	md := p.ToMarkdown()
+	if !strings.Contains(md, "order: 4\n") {
+		t.Errorf("ToMarkdown() = %q, want an order field", md)
+	}
+
+	parsed, err := Parse(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Order != 4 {
+		t.Errorf("parsed order = %d, want 4", parsed.Order)
+	}
+
+	if md := (&Prompt{Title: "Unordered"}).ToMarkdown(); strings.Contains(md, "order:") {
+		t.Errorf("ToMarkdown() = %q, want no order field", md)
+	}
+}
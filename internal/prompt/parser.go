@@ -37,37 +37,58 @@ func splitFrontmatter(content string) (frontmatter, body string, err error) {
 		return "", content, nil
 	}
 
-	// Find the closing delimiter
-	rest := content[len(frontmatterDelimiter):]
-
-	// Handle newline after opening delimiter
-	if len(rest) > 0 && rest[0] == '\n' {
-		rest = rest[1:]
-	} else if len(rest) > 1 && rest[0] == '\r' && rest[1] == '\n' {
-		rest = rest[2:]
-	}
-
-	// Find closing delimiter
-	endIdx := strings.Index(rest, "\n"+frontmatterDelimiter)
-	if endIdx == -1 {
-		// Try with \r\n
-		endIdx = strings.Index(rest, "\r\n"+frontmatterDelimiter)
-		if endIdx == -1 {
+	// Skip the rest of the opening delimiter's line
+	_, rest, _ := strings.Cut(content[len(frontmatterDelimiter):], "\n")
+
+	// Find the closing delimiter, which must be on a line of its own. The
+	// leading newline lets an empty frontmatter close straight away.
+	search := "\n" + rest
+	for i := 0; ; {
+		end := strings.Index(search[i:], "\n"+frontmatterDelimiter)
+		if end == -1 {
 			return "", content, fmt.Errorf("missing closing frontmatter delimiter")
 		}
+		end += i
+		line, after, _ := strings.Cut(search[end+1+len(frontmatterDelimiter):], "\n")
+		if strings.TrimSpace(line) == "" {
+			frontmatter = strings.TrimSuffix(strings.TrimPrefix(search[:end], "\n"), "\r")
+			return frontmatter, after, nil
+		}
+		i = end + 1
 	}
+}
 
-	frontmatter = rest[:endIdx]
-	body = rest[endIdx+1+len(frontmatterDelimiter):]
+// BodyLine returns the 1-based line number where the body text starts in a
+// prompt file, after any frontmatter and blank lines. It is 1 for files
+// without frontmatter or with an empty body.
+func BodyLine(content string) int {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
 
-	// Trim leading newline from body
-	if len(body) > 0 && body[0] == '\n' {
-		body = body[1:]
-	} else if len(body) > 1 && body[0] == '\r' && body[1] == '\n' {
-		body = body[2:]
+	i := 0
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	if i < len(lines) && strings.TrimSpace(lines[i]) == frontmatterDelimiter {
+		end := -1
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == frontmatterDelimiter {
+				end = j
+				break
+			}
+		}
+		if end == -1 {
+			return 1
+		}
+		i = end + 1
+	}
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
 	}
 
-	return frontmatter, body, nil
+	if i >= len(lines) {
+		return 1
+	}
+	return i + 1
 }
 
 // HasFrontmatter checks if content has valid frontmatter
@@ -77,8 +98,8 @@ func HasFrontmatter(content string) bool {
 		return false
 	}
 
-	rest := content[len(frontmatterDelimiter):]
-	return strings.Contains(rest, "\n"+frontmatterDelimiter)
+	_, _, err := splitFrontmatter(content)
+	return err == nil
 }
 
 // ParseFrontmatterOnly parses just the frontmatter without the content
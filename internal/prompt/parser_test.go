@@ -74,6 +74,29 @@ Content here.`,
 			},
 			wantErr: false,
 		},
+		{
+			name:    "crlf line endings",
+			content: "---\r\ntitle: Windows\r\n---\r\n\r\nContent.",
+			want: &Prompt{
+				Title:   "Windows",
+				Content: "Content.",
+			},
+		},
+		{
+			name:    "horizontal rule in body",
+			content: "---\ntitle: Rule\n---\n\nAbove\n\n---\n\nBelow",
+			want: &Prompt{
+				Title:   "Rule",
+				Content: "Above\n\n---\n\nBelow",
+			},
+		},
+		{
+			name:    "empty frontmatter",
+			content: "---\n---\nBody",
+			want: &Prompt{
+				Content: "Body",
+			},
+		},
 		{
 			name: "unclosed frontmatter",
 			content: `---
@@ -145,6 +168,11 @@ Content`,
 			content: "---\ntitle: Test",
 			want:    false,
 		},
+		{
+			name:    "closing delimiter not on its own line",
+			content: "---\ntitle: Test\n----\nContent",
+			want:    false,
+		},
 		{
 			name:    "empty",
 			content: "",
@@ -161,6 +189,48 @@ Content`,
 	}
 }
 
+func TestBodyLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{
+			name:    "frontmatter and blank line",
+			content: "---\ntitle: Test\ntags: [a]\n---\n\nContent",
+			want:    6,
+		},
+		{
+			name:    "no frontmatter",
+			content: "\n\nContent",
+			want:    3,
+		},
+		{
+			name:    "CRLF line endings",
+			content: "---\r\ntitle: Test\r\n---\r\nContent",
+			want:    4,
+		},
+		{
+			name:    "empty body",
+			content: "---\ntitle: Test\n---\n",
+			want:    1,
+		},
+		{
+			name:    "unclosed frontmatter",
+			content: "---\ntitle: Test\nContent",
+			want:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BodyLine(tt.content); got != tt.want {
+				t.Errorf("BodyLine() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestPrompt_RequiresInput(t *testing.T) {
 	tests := []struct {
 		name  string
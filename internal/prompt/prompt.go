@@ -21,11 +21,14 @@ type Prompt struct {
 	Input       string   `yaml:"input"` // "required", "optional", or ""
 	InputHint   string   `yaml:"input_hint"`
 	Favorite    bool     `yaml:"favorite"`
+	Order       int      `yaml:"order"` // position within its group, 0 if unordered
 
 	// Content and file info
 	Content  string // The prompt content (after frontmatter)
 	FilePath string // Full path to the file
 	FileName string // Just the filename
+
+	loaded version // the file as loaded or last saved, to detect external edits
 }
 
 // RequiresInput returns true if the prompt requires user input
@@ -48,8 +51,15 @@ func (p *Prompt) HasVariables() bool {
 	return strings.Contains(p.Content, "${")
 }
 
-// LoadDirectory loads all prompts from a directory
-func LoadDirectory(dir string) ([]*Prompt, error) {
+// LoadDirectory loads all prompts from a directory. With groupFolders set
+// its immediate subdirectories, which hold group folders, are loaded too;
+// hidden ones are skipped. Otherwise subdirectories are left alone, so
+// folders of drafts or templates don't show up as prompts.
+func LoadDirectory(dir string, groupFolders bool) ([]*Prompt, error) {
+	return loadDirectory(dir, groupFolders)
+}
+
+func loadDirectory(dir string, subdirs bool) ([]*Prompt, error) {
 	var prompts []*Prompt
 
 	entries, err := os.ReadDir(dir)
@@ -58,11 +68,16 @@ func LoadDirectory(dir string) ([]*Prompt, error) {
 	}
 
 	for _, entry := range entries {
+		name := entry.Name()
 		if entry.IsDir() {
+			if subdirs && !strings.HasPrefix(name, ".") {
+				// An unreadable group folder shouldn't hide the rest
+				sub, _ := loadDirectory(filepath.Join(dir, name), false)
+				prompts = append(prompts, sub...)
+			}
 			continue
 		}
 
-		name := entry.Name()
 		// Skip non-markdown files
 		if !strings.HasSuffix(strings.ToLower(name), ".md") {
 			continue
@@ -86,18 +101,19 @@ func LoadDirectory(dir string) ([]*Prompt, error) {
 
 // LoadFile loads a single prompt from a file
 func LoadFile(path string) (*Prompt, error) {
-	data, err := os.ReadFile(path)
+	v, err := readVersion(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, err
 	}
 
-	prompt, err := Parse(string(data))
+	prompt, err := Parse(v.content)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
 	}
 
 	prompt.FilePath = path
 	prompt.FileName = filepath.Base(path)
+	prompt.loaded = v
 
 	return prompt, nil
 }
@@ -146,34 +162,17 @@ func SortedGroupNames(groups map[string][]*Prompt) []string {
 
 // GenerateFilename creates a filename from a title
 func GenerateFilename(title string, existingFiles []string) string {
-	// Convert to lowercase
-	name := strings.ToLower(title)
-
-	// Replace spaces with hyphens
-	name = strings.ReplaceAll(name, " ", "-")
-
-	// Remove special characters (keep alphanumeric and hyphens)
-	var result strings.Builder
-	for _, r := range name {
-		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' {
-			result.WriteRune(r)
-		}
-	}
-	name = result.String()
-
-	// Remove consecutive hyphens
-	for strings.Contains(name, "--") {
-		name = strings.ReplaceAll(name, "--", "-")
-	}
-
-	// Trim leading/trailing hyphens
-	name = strings.Trim(name, "-")
-
+	name := slugify(title)
 	if name == "" {
 		name = "prompt"
 	}
 
-	// Add .md extension
+	return uniqueFilename(name, existingFiles)
+}
+
+// uniqueFilename returns name with a .md extension, adding a numeric
+// suffix if that is among existingFiles
+func uniqueFilename(name string, existingFiles []string) string {
 	baseName := name + ".md"
 
 	// Check for duplicates and add suffix if needed
@@ -201,6 +200,34 @@ func GenerateFilename(title string, existingFiles []string) string {
 	return baseName // fallback
 }
 
+// slugify lowercases s and reduces it to letters, digits and single hyphens
+func slugify(s string) string {
+	// Convert to lowercase
+	name := strings.ToLower(s)
+
+	// Replace spaces with hyphens
+	name = strings.ReplaceAll(name, " ", "-")
+
+	// Remove special characters (keep alphanumeric and hyphens)
+	var result strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' {
+			result.WriteRune(r)
+		}
+	}
+	name = result.String()
+
+	// Remove consecutive hyphens
+	for strings.Contains(name, "--") {
+		name = strings.ReplaceAll(name, "--", "-")
+	}
+
+	// Trim leading/trailing hyphens
+	name = strings.Trim(name, "-")
+
+	return name
+}
+
 // Filter returns prompts matching the search query
 func Filter(prompts []*Prompt, query string) []*Prompt {
 	if query == "" {
@@ -290,9 +317,10 @@ type ValidationResult struct {
 	Message string
 }
 
-// ValidateDirectory checks all prompts in a directory
-func ValidateDirectory(dir string) (valid int, warnings []FileValidation, errors []FileValidation) {
-	prompts, err := LoadDirectory(dir)
+// ValidateDirectory checks all prompts in a directory, and in its group
+// folders when groupFolders is set
+func ValidateDirectory(dir string, groupFolders bool) (valid int, warnings []FileValidation, errors []FileValidation) {
+	prompts, err := LoadDirectory(dir, groupFolders)
 	if err != nil {
 		errors = append(errors, FileValidation{
 			FileName: dir,
@@ -347,7 +375,8 @@ type FileValidation struct {
 	Issues   []ValidationResult
 }
 
-// CreatePromptFile creates a new prompt file with the given metadata
+// CreatePromptFile creates a new prompt file with the given metadata. It
+// never replaces an existing file.
 func CreatePromptFile(dir string, p *Prompt) (string, error) {
 	// Get existing files for duplicate check
 	entries, err := os.ReadDir(dir)
@@ -364,8 +393,8 @@ func CreatePromptFile(dir string, p *Prompt) (string, error) {
 	path := filepath.Join(dir, filename)
 
 	content := p.ToMarkdown()
-	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
-		return "", fmt.Errorf("failed to write file: %w", err)
+	if err := writeNewFile(path, []byte(content), 0644); err != nil {
+		return "", err
 	}
 
 	return path, nil
@@ -398,6 +427,9 @@ func (p *Prompt) ToMarkdown() string {
 	if p.Favorite {
 		fm.WriteString("favorite: true\n")
 	}
+	if p.Order != 0 {
+		fm.WriteString(fmt.Sprintf("order: %d\n", p.Order))
+	}
 
 	fm.WriteString("---\n\n")
 	fm.WriteString(p.Content)
@@ -418,6 +450,7 @@ func DuplicatePrompt(p *Prompt) (*Prompt, error) {
 		Input:       p.Input,
 		InputHint:   p.InputHint,
 		Favorite:    false, // Don't copy favorite
+		Order:       p.Order,
 		Content:     p.Content,
 	}
 
@@ -428,6 +461,7 @@ func DuplicatePrompt(p *Prompt) (*Prompt, error) {
 
 	newPrompt.FilePath = path
 	newPrompt.FileName = filepath.Base(path)
+	newPrompt.loaded, _ = readVersion(path)
 
 	return newPrompt, nil
 }
@@ -437,11 +471,14 @@ func DeletePrompt(p *Prompt) error {
 	return os.Remove(p.FilePath)
 }
 
-// UpdateFavorite updates the favorite status in the file
+// UpdateFavorite updates the favorite status in the file. The change is
+// applied to the file as it is now, so edits made elsewhere are kept.
 func (p *Prompt) UpdateFavorite(favorite bool) error {
+	if err := p.Reload(); err != nil {
+		return err
+	}
 	p.Favorite = favorite
-	content := p.ToMarkdown()
-	return os.WriteFile(p.FilePath, []byte(content), 0644)
+	return p.Overwrite()
 }
 
 // colorForGroup generates a deterministic color index for a group name
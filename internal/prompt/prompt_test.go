@@ -317,7 +317,7 @@ More content.`
 		t.Fatal(err)
 	}
 
-	prompts, err := LoadDirectory(tempDir)
+	prompts, err := LoadDirectory(tempDir, false)
 	if err != nil {
 		t.Fatalf("LoadDirectory() error = %v", err)
 	}
@@ -2,6 +2,7 @@ package prompt
 
 import (
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 )
@@ -81,14 +82,18 @@ func ContainsAnyVariable(content string) bool {
 	return re.MatchString(content)
 }
 
-// ValidateVariables checks if all variables in content are known
-func ValidateVariables(content string) []string {
-	knownVars := map[string]bool{
-		"INPUT":     true,
-		"DATE":      true,
-		"DATETIME":  true,
-		"CLIPBOARD": true,
-		"FILE":      true,
+// BuiltinVariables are the variables substituted without configuration
+var BuiltinVariables = []string{"INPUT", "DATE", "DATETIME", "CLIPBOARD", "FILE"}
+
+// ValidateVariables checks if all variables in content are known. Variables
+// defined elsewhere, such as in the config, can be passed as extra.
+func ValidateVariables(content string, extra ...string) []string {
+	knownVars := make(map[string]bool, len(BuiltinVariables)+len(extra))
+	for _, v := range BuiltinVariables {
+		knownVars[v] = true
+	}
+	for _, v := range extra {
+		knownVars[v] = true
 	}
 
 	vars := ExtractVariables(content)
@@ -100,3 +105,58 @@ func ValidateVariables(content string) []string {
 	}
 	return unknown
 }
+
+// VariableSpan is the location of a ${NAME} reference in content. Start and
+// End are byte offsets, with End exclusive.
+type VariableSpan struct {
+	Start int
+	End   int
+	Name  string
+}
+
+// VariableSpans returns the location of every variable reference in content
+func VariableSpans(content string) []VariableSpan {
+	re := regexp.MustCompile(`\$\{([A-Z_]+)\}`)
+	var spans []VariableSpan
+	for _, m := range re.FindAllStringSubmatchIndex(content, -1) {
+		spans = append(spans, VariableSpan{
+			Start: m[0],
+			End:   m[1],
+			Name:  content[m[2]:m[3]],
+		})
+	}
+	return spans
+}
+
+// VariablePrefix returns the partial variable name being typed before
+// offset, such as "DA" for "Today is ${DA". Start is the offset of the "${".
+// It reports false if offset is not inside an unclosed variable reference.
+func VariablePrefix(content string, offset int) (prefix string, start int, ok bool) {
+	if offset < 0 || offset > len(content) {
+		return "", 0, false
+	}
+	before := content[:offset]
+	start = strings.LastIndex(before, "${")
+	if start == -1 {
+		return "", 0, false
+	}
+	prefix = before[start+2:]
+	for _, r := range prefix {
+		if r != '_' && (r < 'A' || r > 'Z') {
+			return "", 0, false
+		}
+	}
+	return prefix, start, true
+}
+
+// CompleteVariable returns the known variables starting with prefix, sorted
+func CompleteVariable(prefix string, known []string) []string {
+	var matches []string
+	for _, v := range known {
+		if strings.HasPrefix(v, prefix) && !slices.Contains(matches, v) {
+			matches = append(matches, v)
+		}
+	}
+	slices.Sort(matches)
+	return matches
+}
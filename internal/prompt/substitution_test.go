@@ -332,3 +332,73 @@ func TestValidateVariables(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateVariables_Extra(t *testing.T) {
+	got := ValidateVariables("${INPUT} ${AUTHOR} ${TEAM}", "AUTHOR")
+	if len(got) != 1 || got[0] != "TEAM" {
+		t.Errorf("ValidateVariables() = %v, want [TEAM]", got)
+	}
+}
+
+func TestVariableSpans(t *testing.T) {
+	content := "Hi ${NAME}, today is ${DATE}. $NOT {THIS}"
+	got := VariableSpans(content)
+	want := []VariableSpan{
+		{Start: 3, End: 10, Name: "NAME"},
+		{Start: 21, End: 28, Name: "DATE"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("VariableSpans() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("VariableSpans()[%d] = %v, want %v", i, got[i], want[i])
+		}
+		if content[got[i].Start:got[i].End] != "${"+want[i].Name+"}" {
+			t.Errorf("span %d covers %q", i, content[got[i].Start:got[i].End])
+		}
+	}
+}
+
+func TestVariablePrefix(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		offset     int
+		wantPrefix string
+		wantStart  int
+		wantOK     bool
+	}{
+		{"partial name", "Today is ${DA", 13, "DA", 9, true},
+		{"just opened", "Hello ${", 8, "", 6, true},
+		{"closed reference", "Hello ${NAME} there", 19, "", 0, false},
+		{"cursor inside closed reference", "Hello ${NAME}", 10, "NA", 6, true},
+		{"lowercase is not a variable", "Hello ${na", 10, "", 0, false},
+		{"no reference", "Hello", 5, "", 0, false},
+		{"offset out of range", "Hello", 9, "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix, start, ok := VariablePrefix(tt.content, tt.offset)
+			if ok != tt.wantOK || prefix != tt.wantPrefix || start != tt.wantStart {
+				t.Errorf("VariablePrefix() = %q, %d, %v, want %q, %d, %v",
+					prefix, start, ok, tt.wantPrefix, tt.wantStart, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCompleteVariable(t *testing.T) {
+	known := append([]string{"DEADLINE"}, BuiltinVariables...)
+	got := CompleteVariable("D", known)
+	want := []string{"DATE", "DATETIME", "DEADLINE"}
+	if len(got) != len(want) {
+		t.Fatalf("CompleteVariable() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("CompleteVariable()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
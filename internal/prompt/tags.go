@@ -0,0 +1,176 @@
+package prompt
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Count is a tag or group and how many prompts have it
+type Count struct {
+	Name  string
+	Count int
+}
+
+// HasTag reports whether p is tagged with tag, ignoring case
+func (p *Prompt) HasTag(tag string) bool {
+	return slices.ContainsFunc(p.Tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
+
+// TagCounts returns every tag and the number of prompts tagged with it,
+// sorted by name. Tags differing only in case count as one, under the
+// spelling seen first.
+func TagCounts(prompts []*Prompt) []Count {
+	index := make(map[string]int)
+	var counts []Count
+	for _, p := range prompts {
+		seen := make(map[string]bool)
+		for _, tag := range p.Tags {
+			key := strings.ToLower(strings.TrimSpace(tag))
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			i, ok := index[key]
+			if !ok {
+				i = len(counts)
+				index[key] = i
+				counts = append(counts, Count{Name: strings.TrimSpace(tag)})
+			}
+			counts[i].Count++
+		}
+	}
+	sortCounts(counts)
+	return counts
+}
+
+// GroupCounts returns every group and its number of prompts, sorted by name
+func GroupCounts(prompts []*Prompt) []Count {
+	_, groups, _ := GroupPrompts(prompts)
+	counts := make([]Count, 0, len(groups))
+	for _, name := range SortedGroupNames(groups) {
+		counts = append(counts, Count{Name: name, Count: len(groups[name])})
+	}
+	return counts
+}
+
+// VariableCounts returns every variable used in prompt content and the
+// number of prompts using it, sorted by name
+func VariableCounts(prompts []*Prompt) []Count {
+	index := make(map[string]int)
+	var counts []Count
+	for _, p := range prompts {
+		for _, v := range p.GetVariables() {
+			i, ok := index[v]
+			if !ok {
+				i = len(counts)
+				index[v] = i
+				counts = append(counts, Count{Name: v})
+			}
+			counts[i].Count++
+		}
+	}
+	sortCounts(counts)
+	return counts
+}
+
+// sortCounts sorts counts by name, ignoring case
+func sortCounts(counts []Count) {
+	sort.Slice(counts, func(i, j int) bool {
+		return strings.ToLower(counts[i].Name) < strings.ToLower(counts[j].Name)
+	})
+}
+
+// TagFilter narrows prompts to chosen tags, groups and variables. It is
+// used with Filter, so a search query applies on top.
+type TagFilter struct {
+	Tags      []string
+	MatchAll  bool     // prompts need every tag, rather than any of them
+	Groups    []string // prompts must be in one of these, when set
+	Variables []string // prompts must use one of these, when set
+}
+
+// Active reports whether the filter hides any prompts
+func (f TagFilter) Active() bool {
+	return len(f.Tags) > 0 || len(f.Groups) > 0 || len(f.Variables) > 0
+}
+
+// Match reports whether p passes the filter
+func (f TagFilter) Match(p *Prompt) bool {
+	if len(f.Groups) > 0 && !slices.Contains(f.Groups, p.Group) {
+		return false
+	}
+	if len(f.Variables) > 0 && !slices.ContainsFunc(p.GetVariables(), f.HasVariable) {
+		return false
+	}
+	if len(f.Tags) == 0 {
+		return true
+	}
+	if f.MatchAll {
+		return !slices.ContainsFunc(f.Tags, func(tag string) bool { return !p.HasTag(tag) })
+	}
+	return slices.ContainsFunc(f.Tags, p.HasTag)
+}
+
+// Apply returns the prompts passing the filter
+func (f TagFilter) Apply(prompts []*Prompt) []*Prompt {
+	if !f.Active() {
+		return prompts
+	}
+	var matches []*Prompt
+	for _, p := range prompts {
+		if f.Match(p) {
+			matches = append(matches, p)
+		}
+	}
+	return matches
+}
+
+// HasTag reports whether tag is one of the filter's tags, ignoring case
+func (f TagFilter) HasTag(tag string) bool {
+	return slices.ContainsFunc(f.Tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
+
+// HasVariable reports whether variable is one of the filter's variables
+func (f TagFilter) HasVariable(variable string) bool {
+	return slices.Contains(f.Variables, variable)
+}
+
+// ToggleTag adds tag to the filter, or removes it if it is there
+func (f *TagFilter) ToggleTag(tag string) {
+	if f.HasTag(tag) {
+		f.Tags = slices.DeleteFunc(slices.Clone(f.Tags), func(t string) bool { return strings.EqualFold(t, tag) })
+		return
+	}
+	f.Tags = append(slices.Clone(f.Tags), tag)
+}
+
+// ToggleGroup adds group to the filter, or removes it if it is there
+func (f *TagFilter) ToggleGroup(group string) {
+	if i := slices.Index(f.Groups, group); i >= 0 {
+		f.Groups = slices.Delete(slices.Clone(f.Groups), i, i+1)
+		return
+	}
+	f.Groups = append(slices.Clone(f.Groups), group)
+}
+
+// ToggleVariable adds variable to the filter, or removes it if it is there
+func (f *TagFilter) ToggleVariable(variable string) {
+	if i := slices.Index(f.Variables, variable); i >= 0 {
+		f.Variables = slices.Delete(slices.Clone(f.Variables), i, i+1)
+		return
+	}
+	f.Variables = append(slices.Clone(f.Variables), variable)
+}
+
+// RenameTag replaces the tag from with to in the filter, as after renaming
+// it across the prompts
+func (f *TagFilter) RenameTag(from, to string) {
+	if !f.HasTag(from) {
+		return
+	}
+	f.ToggleTag(from)
+	if !f.HasTag(to) {
+		f.ToggleTag(to)
+	}
+}
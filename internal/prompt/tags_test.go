@@ -0,0 +1,98 @@
+package prompt
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestTagCounts(t *testing.T) {
+	prompts := []*Prompt{
+		{Title: "A", Group: "Coding", Tags: []string{"go", "Review"}},
+		{Title: "B", Group: "Coding", Tags: []string{"Go", "go", " "}},
+		{Title: "C", Tags: []string{"review", "api"}},
+		{Title: "D", Group: "Writing"},
+	}
+
+	wantTags := []Count{{"api", 1}, {"go", 2}, {"Review", 2}}
+	if got := TagCounts(prompts); !reflect.DeepEqual(got, wantTags) {
+		t.Errorf("TagCounts() = %v, want %v", got, wantTags)
+	}
+
+	wantGroups := []Count{{"Coding", 2}, {"Writing", 1}}
+	if got := GroupCounts(prompts); !reflect.DeepEqual(got, wantGroups) {
+		t.Errorf("GroupCounts() = %v, want %v", got, wantGroups)
+	}
+}
+
+func TestVariableCounts(t *testing.T) {
+	prompts := []*Prompt{
+		{Title: "A", Content: "${INPUT} and ${CLIPBOARD} and ${INPUT}"},
+		{Title: "B", Content: "Today is ${DATE}: ${INPUT}"},
+		{Title: "C", Content: "No variables"},
+	}
+
+	want := []Count{{"CLIPBOARD", 1}, {"DATE", 1}, {"INPUT", 2}}
+	if got := VariableCounts(prompts); !reflect.DeepEqual(got, want) {
+		t.Errorf("VariableCounts() = %v, want %v", got, want)
+	}
+}
+
+func TestTagFilter(t *testing.T) {
+	prompts := []*Prompt{
+		{Title: "A", Group: "Coding", Tags: []string{"go", "review"}},
+		{Title: "B", Group: "Coding", Tags: []string{"Go"}},
+		{Title: "C", Tags: []string{"review"}, Content: "${CLIPBOARD}"},
+		{Title: "D", Group: "Writing", Content: "${DATE} ${INPUT}"},
+	}
+
+	tests := []struct {
+		name   string
+		filter TagFilter
+		want   []string
+	}{
+		{"none", TagFilter{}, []string{"A", "B", "C", "D"}},
+		{"any tag", TagFilter{Tags: []string{"GO", "review"}}, []string{"A", "B", "C"}},
+		{"all tags", TagFilter{Tags: []string{"go", "review"}, MatchAll: true}, []string{"A"}},
+		{"group", TagFilter{Groups: []string{"Writing", "Coding"}}, []string{"A", "B", "D"}},
+		{"group and tag", TagFilter{Tags: []string{"review"}, Groups: []string{"Coding"}}, []string{"A"}},
+		{"variable", TagFilter{Variables: []string{"CLIPBOARD", "INPUT"}}, []string{"C", "D"}},
+		{"variable and tag", TagFilter{Tags: []string{"review"}, Variables: []string{"DATE"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := titles(tt.filter.Apply(prompts)); !slices.Equal(got, tt.want) {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagFilter_Toggle(t *testing.T) {
+	var f TagFilter
+	f.ToggleTag("go")
+	f.ToggleTag("review")
+	f.ToggleTag("GO")
+	f.ToggleGroup("Coding")
+	f.ToggleGroup("Writing")
+	f.ToggleGroup("Coding")
+	f.ToggleVariable("DATE")
+	f.ToggleVariable("FILE")
+	f.ToggleVariable("DATE")
+	if !slices.Equal(f.Tags, []string{"review"}) || !slices.Equal(f.Groups, []string{"Writing"}) || !slices.Equal(f.Variables, []string{"FILE"}) {
+		t.Errorf("after toggling: %+v", f)
+	}
+
+	f.RenameTag("Review", "feedback")
+	f.RenameTag("missing", "other")
+	if !slices.Equal(f.Tags, []string{"feedback"}) {
+		t.Errorf("after RenameTag(): %v", f.Tags)
+	}
+
+	// Renaming onto a tag already in the filter merges them
+	f.ToggleTag("notes")
+	f.RenameTag("notes", "Feedback")
+	if !slices.Equal(f.Tags, []string{"feedback"}) {
+		t.Errorf("after merging: %v", f.Tags)
+	}
+}
@@ -0,0 +1,203 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/grantcarthew/cuecard/internal/fileutil"
+)
+
+// ConflictError is returned when a prompt file changed on disk since it was
+// loaded, so saving would overwrite someone else's edit
+type ConflictError struct {
+	Path string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s was changed on disk since it was loaded", filepath.Base(e.Path))
+}
+
+// version records a prompt file as it was loaded or last saved
+type version struct {
+	content string
+	modTime time.Time
+	size    int64
+}
+
+// WriteFileAtomic writes data to a temp file in the same directory, syncs it
+// and renames it over path, so readers see either the old or the new
+// content and never a partial file. An existing file's permissions are kept;
+// perm is used for new files.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := writeTemp(filepath.Dir(path), data, perm)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// writeNewFile atomically creates path with data, failing with
+// ErrFileExists rather than replace an existing file
+func writeNewFile(path string, data []byte, perm os.FileMode) error {
+	tmp, err := writeTemp(filepath.Dir(path), data, perm)
+	if err != nil {
+		return err
+	}
+	if err := fileutil.MoveFile(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// writeTemp writes data to a synced temp file in dir and returns its path.
+// The temp name doesn't end in .md, so watchers ignore it.
+func writeTemp(dir string, data []byte, perm os.FileMode) (string, error) {
+	f, err := os.CreateTemp(dir, ".cuecard-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if err == nil {
+		err = f.Chmod(perm)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// syncDir flushes a directory entry change to disk. Not every platform
+// supports syncing a directory, so failures are ignored.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// readVersion reads the file at path and records its current version
+func readVersion(path string) (version, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return version{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return version{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	return version{content: string(data), modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// changedOnDisk reports whether the prompt's file differs from the version
+// it was loaded from. A matching mtime and size is trusted; otherwise the
+// content is compared, so a touched but unchanged file isn't a conflict.
+// Prompts that weren't loaded from a file have nothing to compare.
+func (p *Prompt) changedOnDisk() (bool, error) {
+	if p.loaded.modTime.IsZero() {
+		return false, nil
+	}
+	info, err := os.Stat(p.FilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		// Deleted externally; saving recreates it
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check file: %w", err)
+	}
+	if info.ModTime().Equal(p.loaded.modTime) && info.Size() == p.loaded.size {
+		return false, nil
+	}
+
+	current, err := readVersion(p.FilePath)
+	if err != nil {
+		return false, err
+	}
+	return current.content != p.loaded.content, nil
+}
+
+// Overwrite atomically writes the prompt to its file, replacing any changes
+// made on disk
+func (p *Prompt) Overwrite() error {
+	if err := WriteFileAtomic(p.FilePath, []byte(p.ToMarkdown()), 0644); err != nil {
+		return err
+	}
+	if v, err := readVersion(p.FilePath); err == nil {
+		p.loaded = v
+	}
+	return nil
+}
+
+// Update replaces the prompt's fields with edited's and saves it. If the
+// file changed on disk it returns a *ConflictError and leaves p unchanged.
+func (p *Prompt) Update(edited *Prompt) error {
+	changed, err := p.changedOnDisk()
+	if err != nil {
+		return err
+	}
+	if changed {
+		return &ConflictError{Path: p.FilePath}
+	}
+	return p.Replace(edited)
+}
+
+// Replace replaces the prompt's fields with edited's and saves it,
+// overwriting any changes made on disk
+func (p *Prompt) Replace(edited *Prompt) error {
+	next := *edited
+	next.FilePath = p.FilePath
+	next.FileName = p.FileName
+	next.loaded = p.loaded
+	if err := next.Overwrite(); err != nil {
+		return err
+	}
+	*p = next
+	return nil
+}
+
+// Reload replaces the prompt with its file's current content
+func (p *Prompt) Reload() error {
+	loaded, err := LoadFile(p.FilePath)
+	if err != nil {
+		return err
+	}
+	*p = *loaded
+	return nil
+}
+
+// MergeDisk reloads the prompt from disk and returns edited merged with the
+// changes made on disk since the prompt was loaded. clean is false if both
+// changed the same thing; the merged content then has conflict markers.
+func (p *Prompt) MergeDisk(edited *Prompt) (merged *Prompt, clean bool, err error) {
+	base, err := Parse(p.loaded.content)
+	if err != nil {
+		base = &Prompt{}
+	}
+	if err := p.Reload(); err != nil {
+		return nil, false, err
+	}
+
+	merged, clean = Merge(base, edited, p)
+	return merged, clean, nil
+}
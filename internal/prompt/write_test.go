@@ -0,0 +1,165 @@
+package prompt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.md")
+
+	if err := WriteFileAtomic(path, []byte("first"), 0600); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(path, 0640); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := WriteFileAtomic(path, []byte("second"), 0644); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "second" {
+		t.Errorf("content = %q, %v", data, err)
+	}
+	if runtime.GOOS != "windows" {
+		info, _ := os.Stat(path)
+		if info.Mode().Perm() != 0640 {
+			t.Errorf("mode = %v, want existing 0640 kept", info.Mode().Perm())
+		}
+	}
+
+	// No temp files are left behind
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want 1", len(entries))
+	}
+}
+
+func TestUpdate_Conflict(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "a.md", "Original")
+	path := filepath.Join(dir, "a.md")
+
+	p, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unchanged on disk: saving works, and again after our own save
+	if err := p.Update(&Prompt{Title: "Mine", Content: "mine"}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := p.Update(&Prompt{Title: "Mine Again", Content: "mine"}); err != nil {
+		t.Fatalf("second Update() error = %v", err)
+	}
+
+	// Changed externally, with a different mtime
+	external := "---\ntitle: External\n---\n\nexternal body\n"
+	if err := os.WriteFile(path, []byte(external), 0644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(path, future, future)
+
+	err = p.Update(&Prompt{Title: "Clobber"})
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("Update() error = %v, want ConflictError", err)
+	}
+	if p.Title != "Mine Again" {
+		t.Errorf("prompt changed on conflict: %q", p.Title)
+	}
+	if data, _ := os.ReadFile(path); string(data) != external {
+		t.Error("file overwritten on conflict")
+	}
+
+	// Replace overwrites deliberately
+	if err := p.Replace(&Prompt{Title: "Forced"}); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	if loaded, _ := LoadFile(path); loaded.Title != "Forced" {
+		t.Errorf("title after Replace = %q", loaded.Title)
+	}
+}
+
+func TestUpdate_TouchedIsNotConflict(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "a.md", "Original")
+	path := filepath.Join(dir, "a.md")
+	p, _ := LoadFile(path)
+
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(path, future, future)
+
+	if err := p.Update(&Prompt{Title: "Mine"}); err != nil {
+		t.Errorf("Update() error = %v for touched file", err)
+	}
+}
+
+func TestMergeDisk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.md")
+	if err := os.WriteFile(path, []byte("---\ntitle: A\n---\n\none\ntwo\nthree\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, _ := LoadFile(path)
+
+	if err := os.WriteFile(path, []byte("---\ntitle: A\n---\n\none\ntwo\nthree\nfour\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	edited := *p
+	edited.Content = "ONE\ntwo\nthree"
+	merged, clean, err := p.MergeDisk(&edited)
+	if err != nil {
+		t.Fatalf("MergeDisk() error = %v", err)
+	}
+	if !clean || merged.Content != "ONE\ntwo\nthree\nfour" {
+		t.Errorf("MergeDisk() = %q, clean %v", merged.Content, clean)
+	}
+
+	// p now tracks the disk version, so saving the merge succeeds
+	if err := p.Update(merged); err != nil {
+		t.Fatalf("Update() after merge error = %v", err)
+	}
+}
+
+func TestUpdateFavorite_KeepsExternalEdits(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "a.md", "A")
+	path := filepath.Join(dir, "a.md")
+	p, _ := LoadFile(path)
+
+	if err := os.WriteFile(path, []byte("---\ntitle: A\n---\n\nedited elsewhere\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.UpdateFavorite(true); err != nil {
+		t.Fatalf("UpdateFavorite() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "edited elsewhere") || !strings.Contains(string(data), "favorite: true") {
+		t.Errorf("file = %q", data)
+	}
+}
+
+func TestCreatePromptFile_NoClobber(t *testing.T) {
+	dir := t.TempDir()
+	path, err := CreatePromptFile(dir, &Prompt{Title: "Hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A file appearing under the generated name isn't replaced
+	if err := writeNewFile(path, []byte("other"), 0644); !errors.Is(err, ErrFileExists) {
+		t.Errorf("writeNewFile() error = %v, want ErrFileExists", err)
+	}
+}
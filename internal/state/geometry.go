@@ -0,0 +1,82 @@
+package state
+
+import "math"
+
+// Rect is an area of the screen, such as a monitor's work area
+type Rect struct {
+	X, Y, Width, Height int
+}
+
+// overlap returns the area shared by two rectangles
+func overlap(a, b Rect) int {
+	w := min(a.X+a.Width, b.X+b.Width) - max(a.X, b.X)
+	h := min(a.Y+a.Height, b.Y+b.Height) - max(a.Y, b.Y)
+	if w <= 0 || h <= 0 {
+		return 0
+	}
+	return w * h
+}
+
+// Clamp fits a saved window onto the visible screens. The window is shrunk
+// to fit the screen it mostly covers and moved fully onto it. A window that
+// is off every screen, for example because a monitor was unplugged, is
+// centred on the first screen. With no screens the window is unchanged.
+func Clamp(w Window, screens []Rect) Window {
+	if len(screens) == 0 {
+		return w
+	}
+
+	screen := screens[0]
+	visible := false
+	if w.HasPosition {
+		rect := Rect{X: w.X, Y: w.Y, Width: w.Width, Height: w.Height}
+		best := 0
+		for _, s := range screens {
+			if area := overlap(rect, s); area > best {
+				best = area
+				screen = s
+			}
+		}
+		visible = best > 0
+	}
+
+	if w.Width > screen.Width {
+		w.Width = screen.Width
+	}
+	if w.Height > screen.Height {
+		w.Height = screen.Height
+	}
+
+	if !w.HasPosition {
+		return w
+	}
+	if !visible {
+		w.X = screen.X + (screen.Width-w.Width)/2
+		w.Y = screen.Y + (screen.Height-w.Height)/2
+		return w
+	}
+
+	w.X = min(max(w.X, screen.X), screen.X+screen.Width-w.Width)
+	w.Y = min(max(w.Y, screen.Y), screen.Y+screen.Height-w.Height)
+	return w
+}
+
+// ClampScaled is Clamp for a window whose size is in scaled units, as Fyne
+// sizes are, with scale screen pixels to the unit. Its position and the
+// screens are in screen pixels, so the size is converted to pixels to clamp
+// it and back to units afterwards.
+func ClampScaled(w Window, screens []Rect, scale float32) Window {
+	if scale <= 0 {
+		scale = 1
+	}
+	s := float64(scale)
+	w.Width = int(math.Round(float64(w.Width) * s))
+	w.Height = int(math.Round(float64(w.Height) * s))
+
+	w = Clamp(w, screens)
+
+	// Rounding down keeps the window within the screen
+	w.Width = int(float64(w.Width) / s)
+	w.Height = int(float64(w.Height) / s)
+	return w
+}
@@ -0,0 +1,107 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/grantcarthew/cuecard/internal/config"
+)
+
+// State holds UI state that persists between runs. Unlike the config it is
+// written by the app and can be lost without harm.
+type State struct {
+	Window Window `json:"window"`
+	View   View   `json:"view"`
+	// Usage counts how often each prompt was copied, by file path
+	Usage map[string]int `json:"usage,omitempty"`
+}
+
+// Window is the main window's geometry. Width and Height are in Fyne units;
+// X and Y are screen coordinates of the top-left corner.
+type Window struct {
+	Width       int  `json:"width"`
+	Height      int  `json:"height"`
+	X           int  `json:"x"`
+	Y           int  `json:"y"`
+	HasPosition bool `json:"has_position"`
+}
+
+// View is the last view mode of the prompt list
+type View struct {
+	Compact bool `json:"compact"`
+	List    bool `json:"list"`
+	// Sort is the prompt sort mode, alphabetical when empty
+	Sort string `json:"sort,omitempty"`
+	// Sidebar is whether the tag and group browser is shown
+	Sidebar bool `json:"sidebar"`
+}
+
+// RecordUse counts a copy of the prompt at path
+func (s *State) RecordUse(path string) {
+	if s.Usage == nil {
+		s.Usage = make(map[string]int)
+	}
+	s.Usage[path]++
+}
+
+// MoveUse carries the prompt's copy count over when its file moves
+func (s *State) MoveUse(oldPath, newPath string) {
+	n, ok := s.Usage[oldPath]
+	if !ok || oldPath == newPath {
+		return
+	}
+	delete(s.Usage, oldPath)
+	s.Usage[newPath] += n
+}
+
+// Path returns the path to the state file in the state directory
+func Path() (string, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "state.json"), nil
+}
+
+// Load reads the state file at path. A missing file gives empty state; a
+// corrupt one is reported as an error along with empty state, so callers can
+// carry on with defaults.
+func Load(path string) (*State, error) {
+	s := &State{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return &State{}, fmt.Errorf("failed to parse state file: %w", err)
+	}
+	return s, nil
+}
+
+// Save writes the state to path, creating the state directory if needed
+func (s *State) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	// Replace the file atomically so a crash never leaves half a file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
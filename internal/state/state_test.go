@@ -0,0 +1,205 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+
+	s := &State{
+		Window: Window{Width: 900, Height: 700, X: 120, Y: 80, HasPosition: true},
+		View:   View{Compact: true, Sort: "manual", Sidebar: true},
+		Usage:  map[string]int{"/prompts/review.md": 3},
+	}
+	if err := s.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, s) {
+		t.Errorf("Load() = %+v, want %+v", *loaded, *s)
+	}
+
+	// No temp file is left behind
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temp file left after Save()")
+	}
+}
+
+func TestLoad_Missing(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(s, &State{}) {
+		t.Errorf("Load() = %+v, want empty state", *s)
+	}
+}
+
+func TestLoad_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(`{"window": {"width": "wide"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(path)
+	if err == nil {
+		t.Error("Load() of corrupt file should return error")
+	}
+	if !reflect.DeepEqual(s, &State{}) {
+		t.Errorf("Load() = %+v, want empty state", s)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	var s State
+	s.RecordUse("/prompts/a.md")
+	s.RecordUse("/prompts/a.md")
+	s.RecordUse("/prompts/b.md")
+
+	s.MoveUse("/prompts/a.md", "/prompts/coding/a.md")
+	s.MoveUse("/prompts/missing.md", "/prompts/c.md")
+
+	want := map[string]int{"/prompts/coding/a.md": 2, "/prompts/b.md": 1}
+	if !reflect.DeepEqual(s.Usage, want) {
+		t.Errorf("Usage = %v, want %v", s.Usage, want)
+	}
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/xdg/state")
+	t.Setenv("CUECARD_CONFIG", "")
+
+	got, err := Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/xdg/state/cuecard/state.json"; got != want {
+		t.Errorf("Path() = %q, want %q", got, want)
+	}
+}
+
+func TestClamp(t *testing.T) {
+	primary := Rect{X: 0, Y: 0, Width: 1920, Height: 1080}
+	secondary := Rect{X: 1920, Y: 0, Width: 1280, Height: 1024}
+	screens := []Rect{primary, secondary}
+
+	tests := []struct {
+		name    string
+		window  Window
+		screens []Rect
+		want    Window
+	}{
+		{
+			name:    "fits unchanged",
+			window:  Window{Width: 800, Height: 600, X: 100, Y: 100, HasPosition: true},
+			screens: screens,
+			want:    Window{Width: 800, Height: 600, X: 100, Y: 100, HasPosition: true},
+		},
+		{
+			name:    "on second screen",
+			window:  Window{Width: 800, Height: 600, X: 2000, Y: 100, HasPosition: true},
+			screens: screens,
+			want:    Window{Width: 800, Height: 600, X: 2000, Y: 100, HasPosition: true},
+		},
+		{
+			name:    "hanging off the right edge",
+			window:  Window{Width: 800, Height: 600, X: 2900, Y: 100, HasPosition: true},
+			screens: screens,
+			want:    Window{Width: 800, Height: 600, X: 2400, Y: 100, HasPosition: true},
+		},
+		{
+			name:    "above the top",
+			window:  Window{Width: 800, Height: 600, X: 100, Y: -300, HasPosition: true},
+			screens: screens,
+			want:    Window{Width: 800, Height: 600, X: 100, Y: 0, HasPosition: true},
+		},
+		{
+			name:    "larger than the screen",
+			window:  Window{Width: 2500, Height: 1500, X: 0, Y: 0, HasPosition: true},
+			screens: []Rect{primary},
+			want:    Window{Width: 1920, Height: 1080, X: 0, Y: 0, HasPosition: true},
+		},
+		{
+			name:    "monitor unplugged",
+			window:  Window{Width: 800, Height: 600, X: 4000, Y: 200, HasPosition: true},
+			screens: []Rect{primary},
+			want:    Window{Width: 800, Height: 600, X: 560, Y: 240, HasPosition: true},
+		},
+		{
+			name:    "size only",
+			window:  Window{Width: 3000, Height: 600},
+			screens: []Rect{primary},
+			want:    Window{Width: 1920, Height: 600},
+		},
+		{
+			name:   "no screens",
+			window: Window{Width: 800, Height: 600, X: -5000, Y: 0, HasPosition: true},
+			want:   Window{Width: 800, Height: 600, X: -5000, Y: 0, HasPosition: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Clamp(tt.window, tt.screens); got != tt.want {
+				t.Errorf("Clamp() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClampScaled(t *testing.T) {
+	screen := Rect{X: 0, Y: 0, Width: 3840, Height: 2160}
+
+	tests := []struct {
+		name   string
+		window Window
+		scale  float32
+		want   Window
+	}{
+		{
+			name:   "unscaled",
+			window: Window{Width: 800, Height: 600, X: 5000, Y: 0, HasPosition: true},
+			scale:  1,
+			want:   Window{Width: 800, Height: 600, X: 1520, Y: 780, HasPosition: true},
+		},
+		{
+			name:   "centred at double scale",
+			window: Window{Width: 800, Height: 600, X: 5000, Y: 0, HasPosition: true},
+			scale:  2,
+			want:   Window{Width: 800, Height: 600, X: 1120, Y: 480, HasPosition: true},
+		},
+		{
+			name:   "shrunk at double scale",
+			window: Window{Width: 2500, Height: 600, X: 0, Y: 0, HasPosition: true},
+			scale:  2,
+			want:   Window{Width: 1920, Height: 600, X: 0, Y: 0, HasPosition: true},
+		},
+		{
+			name:   "kept on screen at fractional scale",
+			window: Window{Width: 1000, Height: 500, X: 3000, Y: 100, HasPosition: true},
+			scale:  1.5,
+			want:   Window{Width: 1000, Height: 500, X: 2340, Y: 100, HasPosition: true},
+		},
+		{
+			name:   "unknown scale",
+			window: Window{Width: 800, Height: 600},
+			want:   Window{Width: 800, Height: 600},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClampScaled(tt.window, []Rect{screen}, tt.scale); got != tt.want {
+				t.Errorf("ClampScaled() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package trash
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grantcarthew/cuecard/internal/fileutil"
+)
+
+// DefaultRetention is how long trashed prompts are kept before Purge
+// removes them
+const DefaultRetention = 30 * 24 * time.Hour
+
+// ErrFileExists is returned when restoring would overwrite a file
+var ErrFileExists = fileutil.ErrFileExists
+
+// timeFormat is the DeletionDate format from the freedesktop trash spec
+const timeFormat = "2006-01-02T15:04:05"
+
+// Item is a trashed file
+type Item struct {
+	ID           string // name in the trash, unique among trashed items
+	OriginalPath string
+	DeletedAt    time.Time
+}
+
+// Name returns the item's original file name
+func (i Item) Name() string {
+	return filepath.Base(i.OriginalPath)
+}
+
+// Trash is a trash directory laid out like the freedesktop trash spec:
+// trashed files in files/ and a .trashinfo file for each in info/
+type Trash struct {
+	dir string
+}
+
+// New returns the trash in dir. The directory is created when first used.
+func New(dir string) *Trash {
+	return &Trash{dir: dir}
+}
+
+// Dir returns the trash directory
+func (t *Trash) Dir() string {
+	return t.dir
+}
+
+func (t *Trash) filesDir() string { return filepath.Join(t.dir, "files") }
+func (t *Trash) infoDir() string  { return filepath.Join(t.dir, "info") }
+
+// Move moves the file at path into the trash
+func (t *Trash) Move(path string) (Item, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Item{}, fmt.Errorf("failed to trash file: %w", err)
+	}
+	for _, dir := range []string{t.filesDir(), t.infoDir()} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return Item{}, fmt.Errorf("failed to create trash directory: %w", err)
+		}
+	}
+
+	item := Item{OriginalPath: abs, DeletedAt: time.Now()}
+	info, err := t.reserve(filepath.Base(abs))
+	if err != nil {
+		return Item{}, err
+	}
+	item.ID = strings.TrimSuffix(filepath.Base(info.Name()), ".trashinfo")
+
+	_, err = fmt.Fprintf(info, "[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: abs}).EscapedPath(), item.DeletedAt.Format(timeFormat))
+	if closeErr := info.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = fileutil.MoveFile(abs, filepath.Join(t.filesDir(), item.ID))
+	}
+	if err != nil {
+		os.Remove(info.Name())
+		return Item{}, fmt.Errorf("failed to trash file: %w", err)
+	}
+	return item, nil
+}
+
+// reserve creates the info file for a new item named after name, adding a
+// suffix if an item with that name is already trashed
+func (t *Trash) reserve(name string) (*os.File, error) {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	for i := 1; i < 10000; i++ {
+		id := name
+		if i > 1 {
+			id = fmt.Sprintf("%s.%d%s", stem, i, ext)
+		}
+		f, err := os.OpenFile(filepath.Join(t.infoDir(), id+".trashinfo"), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to trash file: %w", err)
+		}
+		return f, nil
+	}
+	return nil, fmt.Errorf("failed to trash file: too many trashed files named %s", name)
+}
+
+// List returns the trashed items, most recently deleted first
+func (t *Trash) List() ([]Item, error) {
+	entries, err := os.ReadDir(t.infoDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	var items []Item
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".trashinfo")
+		if !ok {
+			continue
+		}
+		item, err := t.item(id)
+		if err != nil {
+			// Skip info files for items that no longer exist or can't be read
+			continue
+		}
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// item reads the info file for id
+func (t *Trash) item(id string) (Item, error) {
+	if _, err := os.Lstat(filepath.Join(t.filesDir(), id)); err != nil {
+		return Item{}, err
+	}
+	f, err := os.Open(filepath.Join(t.infoDir(), id+".trashinfo"))
+	if err != nil {
+		return Item{}, err
+	}
+	defer f.Close()
+
+	item := Item{ID: id}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Path":
+			path, err := url.PathUnescape(value)
+			if err != nil {
+				return Item{}, err
+			}
+			item.OriginalPath = path
+		case "DeletionDate":
+			item.DeletedAt, _ = time.ParseInLocation(timeFormat, value, time.Local)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Item{}, err
+	}
+	if item.OriginalPath == "" {
+		return Item{}, errors.New("trash info has no path")
+	}
+	return item, nil
+}
+
+// Restore moves an item back to its original path. It fails with
+// ErrFileExists rather than overwrite a file created there since.
+func (t *Trash) Restore(id string) (Item, error) {
+	item, err := t.item(id)
+	if err != nil {
+		return Item{}, fmt.Errorf("failed to restore %s: %w", id, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(item.OriginalPath), 0755); err != nil {
+		return Item{}, fmt.Errorf("failed to restore %s: %w", item.Name(), err)
+	}
+	err = fileutil.MoveFile(filepath.Join(t.filesDir(), id), item.OriginalPath)
+	if errors.Is(err, ErrFileExists) {
+		return Item{}, fmt.Errorf("cannot restore %s: %w", item.Name(), ErrFileExists)
+	}
+	if err != nil {
+		return Item{}, fmt.Errorf("failed to restore %s: %w", item.Name(), err)
+	}
+	os.Remove(filepath.Join(t.infoDir(), id+".trashinfo"))
+	return item, nil
+}
+
+// Delete permanently removes an item
+func (t *Trash) Delete(id string) error {
+	if err := os.RemoveAll(filepath.Join(t.filesDir(), id)); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", id, err)
+	}
+	if err := os.Remove(filepath.Join(t.infoDir(), id+".trashinfo")); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete %s: %w", id, err)
+	}
+	return nil
+}
+
+// Purge permanently removes items deleted more than age ago and returns
+// how many were removed
+func (t *Trash) Purge(age time.Duration) (int, error) {
+	items, err := t.List()
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-age)
+	purged := 0
+	for _, item := range items {
+		if item.DeletedAt.After(cutoff) {
+			continue
+		}
+		if err := t.Delete(item.ID); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// Empty permanently removes every item
+func (t *Trash) Empty() error {
+	_, err := t.Purge(-time.Hour)
+	return err
+}
@@ -0,0 +1,134 @@
+package trash
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMoveAndRestore(t *testing.T) {
+	prompts := t.TempDir()
+	tr := New(filepath.Join(t.TempDir(), "trash"))
+
+	path := filepath.Join(prompts, "my prompt.md")
+	writeFile(t, path, "content")
+
+	item, err := tr.Move(path)
+	if err != nil {
+		t.Fatalf("Move() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("file still exists after Move()")
+	}
+
+	// The info file follows the freedesktop format
+	info, err := os.ReadFile(filepath.Join(tr.Dir(), "info", item.ID+".trashinfo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(info), "Path="+filepath.ToSlash(prompts)) || !strings.Contains(string(info), "my%20prompt.md") {
+		t.Errorf("trashinfo = %q", info)
+	}
+
+	items, err := tr.List()
+	if err != nil || len(items) != 1 {
+		t.Fatalf("List() = %v, %v", items, err)
+	}
+	if items[0].OriginalPath != path || items[0].Name() != "my prompt.md" {
+		t.Errorf("List()[0] = %+v", items[0])
+	}
+
+	if _, err := tr.Restore(item.ID); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "content" {
+		t.Errorf("restored file = %q, %v", data, err)
+	}
+	if items, _ := tr.List(); len(items) != 0 {
+		t.Errorf("List() after restore = %v", items)
+	}
+}
+
+func TestMove_SameName(t *testing.T) {
+	dir := t.TempDir()
+	tr := New(filepath.Join(t.TempDir(), "trash"))
+	path := filepath.Join(dir, "a.md")
+
+	writeFile(t, path, "first")
+	first, err := tr.Move(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, "second")
+	second, err := tr.Move(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID == second.ID {
+		t.Fatalf("items share ID %q", first.ID)
+	}
+
+	// Restoring over the recreated file is refused
+	writeFile(t, path, "third")
+	if _, err := tr.Restore(first.ID); !errors.Is(err, ErrFileExists) {
+		t.Errorf("Restore() error = %v, want ErrFileExists", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "third" {
+		t.Errorf("file overwritten: %q", data)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	dir := t.TempDir()
+	tr := New(filepath.Join(t.TempDir(), "trash"))
+
+	writeFile(t, filepath.Join(dir, "old.md"), "old")
+	writeFile(t, filepath.Join(dir, "new.md"), "new")
+	old, err := tr.Move(filepath.Join(dir, "old.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Move(filepath.Join(dir, "new.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Backdate one item
+	infoPath := filepath.Join(tr.Dir(), "info", old.ID+".trashinfo")
+	data, _ := os.ReadFile(infoPath)
+	backdated := strings.Replace(string(data), old.DeletedAt.Format(timeFormat),
+		old.DeletedAt.Add(-40*24*time.Hour).Format(timeFormat), 1)
+	writeFile(t, infoPath, backdated)
+
+	n, err := tr.Purge(DefaultRetention)
+	if err != nil || n != 1 {
+		t.Fatalf("Purge() = %d, %v, want 1", n, err)
+	}
+	items, _ := tr.List()
+	if len(items) != 1 || items[0].Name() != "new.md" {
+		t.Errorf("List() after purge = %v", items)
+	}
+
+	if err := tr.Empty(); err != nil {
+		t.Fatalf("Empty() error = %v", err)
+	}
+	if items, _ := tr.List(); len(items) != 0 {
+		t.Errorf("List() after Empty() = %v", items)
+	}
+}
+
+func TestList_Missing(t *testing.T) {
+	items, err := New(filepath.Join(t.TempDir(), "none")).List()
+	if err != nil || len(items) != 0 {
+		t.Errorf("List() = %v, %v", items, err)
+	}
+}
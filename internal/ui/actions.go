@@ -0,0 +1,464 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/grantcarthew/cuecard/internal/bulk"
+	"github.com/grantcarthew/cuecard/internal/bundle"
+	"github.com/grantcarthew/cuecard/internal/config"
+	"github.com/grantcarthew/cuecard/internal/drop"
+	"github.com/grantcarthew/cuecard/internal/history"
+	"github.com/grantcarthew/cuecard/internal/importer"
+	"github.com/grantcarthew/cuecard/internal/prompt"
+	"github.com/grantcarthew/cuecard/internal/trash"
+	"github.com/grantcarthew/cuecard/internal/undo"
+	"github.com/grantcarthew/cuecard/internal/watcher"
+)
+
+// toastDuration is how long a toast stays up
+const toastDuration = 6 * time.Second
+
+// undoShortcut is Ctrl+Z, or Cmd+Z on macOS
+var undoShortcut = &desktop.CustomShortcut{KeyName: fyne.KeyZ, Modifier: fyne.KeyModifierShortcutDefault}
+
+// setupUndo creates the trash and undo stack and removes trashed prompts
+// older than the retention period
+func (a *App) setupUndo() {
+	a.undo = undo.New(undo.DefaultLimit)
+
+	dir, err := config.DataDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		dir = filepath.Join(os.TempDir(), "cuecard")
+	}
+	a.trash = trash.New(filepath.Join(dir, "trash"))
+	go func() {
+		if _, err := a.trash.Purge(trash.DefaultRetention); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to purge trash: %v\n", err)
+		}
+	}()
+
+	a.window.Canvas().AddShortcut(undoShortcut, func(fyne.Shortcut) {
+		a.Undo()
+	})
+}
+
+// setupHistory creates the store for prompt version history
+func (a *App) setupHistory() {
+	dir, err := config.StateDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		dir = filepath.Join(os.TempDir(), "cuecard")
+	}
+	a.history = history.New(filepath.Join(dir, "history"), history.DefaultLimit)
+}
+
+// recordHistory records the file at path as a new version in its history.
+// Failures only warn, since history must never get in the way of a save.
+func (a *App) recordHistory(path string, source history.Source) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		_, _, err = a.history.Record(path, data, source)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record history of %s: %v\n", filepath.Base(path), err)
+	}
+}
+
+// recordExternal records the prompt files in events that were created or
+// changed outside Cuecard. Saves made in Cuecard are already recorded, so
+// they match the newest version and are skipped. Events come from the
+// watcher goroutine, so the config is read on the main thread.
+func (a *App) recordExternal(events []watcher.Event) {
+	fyne.Do(func() {
+		if !a.config.HistoryExternal {
+			return
+		}
+		for _, ev := range events {
+			if ev.Op == watcher.Create || ev.Op == watcher.Write {
+				a.recordHistory(ev.Path, history.External)
+			}
+		}
+	})
+}
+
+// recordBefore records the file at path as it is before Cuecard saves over
+// it, so the version being replaced is kept even when it was never recorded
+func (a *App) recordBefore(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record history of %s: %v\n", filepath.Base(path), err)
+		return
+	}
+	a.recordReplaced(path, data)
+}
+
+// recordReplaced records data, the file at path just before Cuecard saved
+// over it, unless it is already the newest version
+func (a *App) recordReplaced(path string, data []byte) {
+	if _, _, err := a.history.RecordReplaced(path, data); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record history of %s: %v\n", filepath.Base(path), err)
+	}
+}
+
+// movePrompt moves p's file to dst, taking its history, use count and
+// selection along
+func (a *App) movePrompt(p *prompt.Prompt, dst string) error {
+	src := p.FilePath
+	if err := prompt.Move(p, a.config.PromptsDir, dst); err != nil {
+		return err
+	}
+	a.state.MoveUse(src, p.FilePath)
+	a.mainView.selection.Rename(src, p.FilePath)
+	if err := a.history.Rename(src, p.FilePath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return nil
+}
+
+// RestoreVersion saves an earlier version of p from its history over p's
+// file
+func (a *App) RestoreVersion(p *prompt.Prompt, v history.Version) error {
+	data, err := a.history.Read(p.FilePath, v.ID)
+	if err != nil {
+		return err
+	}
+	undoFn := a.editUndo(p)
+	a.recordBefore(p.FilePath)
+	if err := prompt.WriteFileAtomic(p.FilePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to restore version: %w", err)
+	}
+	a.recordHistory(p.FilePath, history.Restored)
+	a.refresh()
+
+	a.done("Restore "+p.Title, undoFn)
+	return nil
+}
+
+// Undo reverts the most recent action
+func (a *App) Undo() {
+	action, err := a.undo.Undo()
+	if errors.Is(err, undo.ErrEmpty) {
+		return
+	}
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to undo %s: %w", action.Label, err), a.window)
+	} else {
+		a.showToast("Undone: "+action.Label, false)
+	}
+	a.refresh()
+}
+
+// done records an undoable action and shows a toast offering to undo it
+func (a *App) done(label string, undoFn func() error) {
+	a.undo.Push(undo.Action{Label: label, Undo: undoFn})
+	a.showToast(label, true)
+}
+
+// DeletePrompt moves a prompt's file to the trash
+func (a *App) DeletePrompt(p *prompt.Prompt) {
+	item, err := a.trash.Move(p.FilePath)
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	a.refresh()
+
+	a.done("Delete "+p.Title, func() error {
+		_, err := a.trash.Restore(item.ID)
+		return err
+	})
+}
+
+// DuplicatePrompt copies a prompt to a new file
+func (a *App) DuplicatePrompt(p *prompt.Prompt) {
+	dup, err := prompt.DuplicatePrompt(p)
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	a.recordHistory(dup.FilePath, history.Saved)
+	a.refresh()
+
+	a.done("Duplicate "+p.Title, func() error {
+		_, err := a.trash.Move(dup.FilePath)
+		return err
+	})
+}
+
+// ToggleFavorite pins or unpins a prompt
+func (a *App) ToggleFavorite(p *prompt.Prompt) error {
+	favorite := !p.Favorite
+	a.recordBefore(p.FilePath)
+	if err := p.UpdateFavorite(favorite); err != nil {
+		return err
+	}
+	a.recordHistory(p.FilePath, history.Saved)
+
+	label := "Favorite " + p.Title
+	if !favorite {
+		label = "Unfavorite " + p.Title
+	}
+	a.done(label, func() error {
+		if err := p.UpdateFavorite(!favorite); err != nil {
+			return err
+		}
+		a.recordHistory(p.FilePath, history.Saved)
+		return nil
+	})
+	return nil
+}
+
+// ReorderPrompts saves prompts' new order within their group and switches
+// to manual sorting so it shows
+func (a *App) ReorderPrompts(prompts []*prompt.Prompt) {
+	a.mainView.SetSortMode(prompt.SortManual)
+	a.editPrompts(prompts, "Reorder %d prompts", bulk.SetOrder(prompts))
+}
+
+// imported records prompts created by an import so they can be undone
+func (a *App) imported(paths []string) {
+	for _, path := range paths {
+		a.recordHistory(path, history.Saved)
+	}
+	a.refresh()
+	if len(paths) == 0 {
+		return
+	}
+
+	label := fmt.Sprintf("Import %d prompts", len(paths))
+	if len(paths) == 1 {
+		label = "Import " + filepath.Base(paths[0])
+	}
+	a.done(label, func() error {
+		var errs []error
+		for _, path := range paths {
+			if _, err := a.trash.Move(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// PreviewImport shows what importing the prompts in the file or folder at
+// path would do, and imports them once confirmed. The format is detected.
+func (a *App) PreviewImport(path string) {
+	a.previewImport(path, "")
+}
+
+// previewImport is PreviewImport, putting every prompt in group if it is
+// not empty
+func (a *App) previewImport(path, group string) {
+	imp, err := importer.Format(path, "")
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	incoming, skipped, err := imp.Import(path)
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	a.showImportPreview(imp.Name(), incoming, skipped, group)
+}
+
+// showImportPreview previews importing incoming, in group if it is not empty
+func (a *App) showImportPreview(format string, incoming []importer.Incoming, skipped []string, group string) {
+	drop.AssignGroup(incoming, group)
+	plan := importer.NewPlan(incoming, a.GetPrompts(), a.config.PromptsDir)
+	ShowImportPreviewDialog(a.window, format, plan, skipped, a.applyImport)
+}
+
+// importPromptFile imports a single prompt or text file, in group if it is
+// not empty
+func (a *App) importPromptFile(path, group string) {
+	ShowImportPromptDialog(a.window, path, group, a.config.PromptsDir, a.config.GroupFolders, a.config.Variables, a.imported)
+}
+
+// onDropped imports files and folders dropped on the window the same way
+// File > Import does. Items dropped on a group header go in that group.
+// Fyne only delivers dropped files, not text selections, so dropped text
+// is handled by pastePrompt instead.
+func (a *App) onDropped(pos fyne.Position, uris []fyne.URI) {
+	var paths []string
+	for _, u := range uris {
+		if u.Scheme() == "file" {
+			paths = append(paths, u.Path())
+		}
+	}
+	if len(paths) == 0 {
+		a.showToast("Only files can be dropped. For text, copy it and use Edit > Paste as New Prompt", false)
+		return
+	}
+	group, _ := a.mainView.GroupAt(pos)
+
+	d := drop.Sort(paths)
+	for _, src := range d.Sources {
+		a.previewImport(src, group)
+	}
+	if len(d.Files) > 0 {
+		incoming, skipped := drop.LoadFiles(d.Files)
+		a.showImportPreview("Dropped files", incoming, skipped, group)
+	}
+	if d.File != "" {
+		a.importPromptFile(d.File, group)
+	}
+	if len(d.Ignored) > 0 {
+		a.showToast("Not imported: "+strings.Join(d.Ignored, ", "), false)
+	}
+}
+
+// pastePrompt opens the prompt editor with the clipboard's text, the way a
+// file without frontmatter is imported. Frontmatter in the text is used. It
+// stands in for dropping text, which the window can't receive.
+func (a *App) pastePrompt() {
+	text := strings.TrimSpace(a.clipboard.Read())
+	if text == "" {
+		a.showToast("The clipboard has no text", false)
+		return
+	}
+	a.newPrompt(drop.FromText(text))
+}
+
+// applyImport carries out an import plan and records it so it can be
+// undone: created prompts go to the trash and overwritten ones are put back
+func (a *App) applyImport(plan *importer.Plan) {
+	result, err := importer.Apply(plan, a.config.PromptsDir, a.config.GroupFolders)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("some prompts failed to import: %w", err), a.window)
+	}
+	for _, c := range result.Changes {
+		if c.Before != nil {
+			a.recordReplaced(c.Path, c.Before)
+		}
+		a.recordHistory(c.Path, history.Saved)
+	}
+	a.refresh()
+	if len(result.Changes) == 0 {
+		return
+	}
+
+	label := fmt.Sprintf("Import %d prompts", len(result.Changes))
+	if len(result.Changes) == 1 {
+		label = "Import " + filepath.Base(result.Changes[0].Path)
+	}
+	a.done(label, func() error {
+		var errs []error
+		for _, c := range result.Changes {
+			var err error
+			if c.Before == nil {
+				_, err = a.trash.Move(c.Path)
+			} else if err = prompt.WriteFileAtomic(c.Path, c.Before, 0644); err == nil {
+				a.recordHistory(c.Path, history.Saved)
+			}
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// editUndo returns a function that puts back p's file as it is now, moving
+// it back if a later rename or group change moved it
+func (a *App) editUndo(p *prompt.Prompt) func() error {
+	data, err := os.ReadFile(p.FilePath)
+	if err != nil {
+		name := filepath.Base(p.FilePath)
+		return func() error {
+			return fmt.Errorf("no saved copy of %s: %w", name, err)
+		}
+	}
+	return a.savedUndo(p, data)
+}
+
+// savedUndo returns a function that writes data back to p's file at its
+// current path, moving it back there if a later rename or group change
+// moved it
+func (a *App) savedUndo(p *prompt.Prompt, data []byte) func() error {
+	oldPath := p.FilePath
+	return func() error {
+		if p.FilePath != oldPath {
+			if err := a.movePrompt(p, oldPath); err != nil {
+				return err
+			}
+		}
+		if err := prompt.WriteFileAtomic(oldPath, data, 0644); err != nil {
+			return err
+		}
+		a.recordHistory(oldPath, history.Saved)
+		return nil
+	}
+}
+
+// showToast shows a short message at the bottom of the window, with an Undo
+// button for the most recent action when undoable is set. It replaces any
+// toast already showing.
+func (a *App) showToast(msg string, undoable bool) {
+	if a.toast != nil {
+		a.toast.Hide()
+	}
+
+	label := widget.NewLabel(msg)
+	content := container.NewHBox(label)
+	var popup *widget.PopUp
+	if undoable {
+		undoBtn := widget.NewButtonWithIcon("Undo", theme.ContentUndoIcon(), func() {
+			popup.Hide()
+			a.Undo()
+		})
+		undoBtn.Importance = widget.HighImportance
+		content.Add(undoBtn)
+	}
+
+	c := a.window.Canvas()
+	popup = widget.NewPopUp(content, c)
+	size := popup.MinSize()
+	popup.ShowAtPosition(fyne.NewPos(
+		(c.Size().Width-size.Width)/2,
+		c.Size().Height-size.Height-theme.Padding()*4,
+	))
+	a.toast = popup
+
+	time.AfterFunc(toastDuration, func() {
+		fyne.Do(func() {
+			if a.toast == popup {
+				popup.Hide()
+				a.toast = nil
+			}
+		})
+	})
+}
+
+func (a *App) showTrash() {
+	ShowTrashDialog(a.window, a.trash, a.refresh)
+}
+
+// ExportPrompts shows the export dialog with the given prompts selected, or
+// every prompt if none are given
+func (a *App) ExportPrompts(selected []*prompt.Prompt) {
+	ShowExportDialog(a.window, a.GetPrompts(), selected, a.config.PromptsDir, a.config.Profile,
+		func(m bundle.Manifest, path string) {
+			a.showToast(fmt.Sprintf("Exported %d prompts to %s", len(m.Prompts), filepath.Base(path)), false)
+		})
+}
+
+// ShowHistory shows the version history of p
+func (a *App) ShowHistory(p *prompt.Prompt) {
+	ShowHistoryDialog(a.window, a.history, p, func(v history.Version) error {
+		return a.RestoreVersion(p, v)
+	})
+}
@@ -4,36 +4,66 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"slices"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
 
 	"github.com/grantcarthew/cuecard/internal/clipboard"
 	"github.com/grantcarthew/cuecard/internal/config"
+	"github.com/grantcarthew/cuecard/internal/editor"
+	"github.com/grantcarthew/cuecard/internal/git"
+	"github.com/grantcarthew/cuecard/internal/history"
 	"github.com/grantcarthew/cuecard/internal/prompt"
+	"github.com/grantcarthew/cuecard/internal/state"
+	"github.com/grantcarthew/cuecard/internal/trash"
+	"github.com/grantcarthew/cuecard/internal/undo"
 	"github.com/grantcarthew/cuecard/internal/watcher"
+	"github.com/grantcarthew/cuecard/internal/window"
 )
 
 const appID = "com.grantcarthew.cuecard"
 
+// Options are startup settings from the command line
+type Options struct {
+	// Profile selects a config profile, overriding the config's profile field
+	Profile string
+}
+
 // App represents the main application
 type App struct {
-	fyneApp   fyne.App
-	window    fyne.Window
-	config    *config.Config
-	prompts   []*prompt.Prompt
-	clipboard *clipboard.Clipboard
-	watcher   *watcher.Watcher
-	mainView  *MainView
+	fyneApp    fyne.App
+	window     fyne.Window
+	options    Options
+	baseConfig *config.Config // config file as loaded, before the profile
+	config     *config.Config // effective config with the profile applied
+	library    *prompt.Library
+	clipboard  *clipboard.Clipboard
+	watcher    *watcher.Watcher
+	cfgWatch   *watcher.Watcher
+	mainView   *MainView
+	state      *state.State
+	statePath  string
+	trash      *trash.Trash
+	undo       *undo.Stack
+	history    *history.Store
+	git        *git.Repo
+	gitStatus  map[string]git.Status
+	toast      *widget.PopUp
 }
 
 // New creates a new application instance
-func New() *App {
+func New(opts Options) *App {
 	fyneApp := app.NewWithID(appID)
 	return &App{
 		fyneApp: fyneApp,
+		options: opts,
 	}
 }
 
@@ -43,6 +73,7 @@ func (a *App) Run() error {
 	a.window = a.fyneApp.NewWindow("Cuecard")
 	a.window.Resize(fyne.NewSize(1024, 768))
 	a.window.CenterOnScreen()
+	a.loadState()
 
 	// Check if config exists
 	configExists, err := config.Exists()
@@ -53,7 +84,7 @@ func (a *App) Run() error {
 	// Check if prompts directory exists (if config exists)
 	promptsDirExists := false
 	if configExists {
-		cfg, err := config.Load()
+		_, cfg, err := a.loadConfig()
 		if err == nil {
 			if _, err := os.Stat(cfg.PromptsDir); err == nil {
 				promptsDirExists = true
@@ -77,6 +108,7 @@ func (a *App) Run() error {
 
 	// Handle window close
 	a.window.SetCloseIntercept(func() {
+		a.saveState()
 		if desk, ok := a.fyneApp.(desktop.App); ok {
 			// Minimize to tray instead of quitting
 			a.window.Hide()
@@ -86,6 +118,10 @@ func (a *App) Run() error {
 		}
 	})
 
+	// Position can only be set once the window exists
+	a.fyneApp.Lifecycle().SetOnStarted(a.restoreGeometry)
+	a.fyneApp.Lifecycle().SetOnStopped(a.saveState)
+
 	// Show and run
 	a.window.ShowAndRun()
 
@@ -93,38 +129,90 @@ func (a *App) Run() error {
 	if a.watcher != nil {
 		a.watcher.Stop()
 	}
+	if a.cfgWatch != nil {
+		a.cfgWatch.Stop()
+	}
 
 	return nil
 }
 
+// loadConfig loads the config file and applies the startup profile
+func (a *App) loadConfig() (base, cfg *config.Config, err error) {
+	base, err = config.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	profile := base.Profile
+	if a.options.Profile != "" {
+		profile = a.options.Profile
+	}
+	cfg, err = base.WithProfile(profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return base, cfg, nil
+}
+
 func (a *App) loadMainUI() error {
 	// Load configuration
-	cfg, err := config.Load()
+	base, cfg, err := a.loadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	a.baseConfig = base
 	a.config = cfg
 
 	// Apply theme
 	a.applyTheme()
 
-	// Resize window based on config
-	a.window.Resize(fyne.NewSize(float32(cfg.Window.Width), float32(cfg.Window.Height)))
+	// Resize window based on config, or the last size if remembered
+	size := fyne.NewSize(float32(cfg.Window.Width), float32(cfg.Window.Height))
+	if a.rememberGeometry() && a.state.Window.Width > 0 && a.state.Window.Height > 0 {
+		size = fyne.NewSize(float32(a.state.Window.Width), float32(a.state.Window.Height))
+	}
+	a.window.Resize(size)
 
 	// Initialize clipboard
 	a.clipboard = clipboard.New(a.window)
 
 	// Load prompts
-	if err := a.loadPrompts(); err != nil {
+	a.library = prompt.NewLibrary(cfg.PromptsDir)
+	a.library.SetGroupFolders(cfg.GroupFolders)
+	if err := a.library.Reload(); err != nil {
 		return fmt.Errorf("failed to load prompts: %w", err)
 	}
 
 	// Create main view
 	a.mainView = NewMainView(a)
+	if a.rememberGeometry() {
+		a.mainView.SetViewMode(a.state.View)
+	}
 	a.window.SetContent(a.mainView.Container())
+	a.window.SetOnDropped(a.onDropped)
+	a.window.Canvas().AddShortcut(selectAllShortcut, func(fyne.Shortcut) {
+		a.mainView.SelectAll()
+	})
+
+	// Library changes can come from the watcher goroutine, so the view is
+	// always refreshed on the main thread
+	a.library.Subscribe(func(prompts []*prompt.Prompt) {
+		fyne.Do(func() {
+			a.mainView.Refresh(prompts)
+			a.refreshGitStatus()
+		})
+	})
+
+	// Deleted prompts go to the trash, and recent actions can be undone
+	a.setupUndo()
+	a.setupHistory()
+
+	// Show git status and actions when the prompts are in a git repo
+	a.setupGit()
 
 	// Set up menus
 	a.setupMenus()
+	a.applyHotkey(nil)
 
 	// Set up system tray
 	a.setupSystemTray()
@@ -134,9 +222,74 @@ func (a *App) loadMainUI() error {
 		fmt.Fprintf(os.Stderr, "Warning: file watcher failed: %v\n", err)
 	}
 
+	// Watch the config file for live changes
+	if err := a.setupConfigWatcher(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: config watcher failed: %v\n", err)
+	}
+
 	return nil
 }
 
+// loadState reads the saved UI state. Problems are reported but not fatal;
+// the app starts with empty state instead.
+func (a *App) loadState() {
+	a.state = &state.State{}
+	path, err := state.Path()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	a.statePath = path
+
+	s, err := state.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	a.state = s
+}
+
+// saveState records the window geometry and view mode. The previous
+// position is kept if the current one can't be read.
+func (a *App) saveState() {
+	if a.statePath == "" || a.mainView == nil {
+		return
+	}
+
+	size := a.window.Canvas().Size()
+	a.state.Window.Width = int(size.Width)
+	a.state.Window.Height = int(size.Height)
+	if x, y, ok := window.Position(a.window); ok {
+		a.state.Window.X = x
+		a.state.Window.Y = y
+		a.state.Window.HasPosition = true
+	}
+	a.state.View = a.mainView.ViewMode()
+
+	if err := a.state.Save(a.statePath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// rememberGeometry reports whether saved geometry should be restored
+func (a *App) rememberGeometry() bool {
+	return a.config != nil && a.config.Window.Position == "remember"
+}
+
+// restoreGeometry moves and resizes the window to its saved geometry,
+// clamped to the screens currently connected. The size is saved in Fyne's
+// scaled units while positions and screens are in screen pixels.
+func (a *App) restoreGeometry() {
+	if !a.rememberGeometry() || a.state.Window.Width <= 0 || a.state.Window.Height <= 0 {
+		return
+	}
+
+	w := state.ClampScaled(a.state.Window, window.Screens(), a.window.Canvas().Scale())
+	a.window.Resize(fyne.NewSize(float32(w.Width), float32(w.Height)))
+	if w.HasPosition {
+		window.Move(a.window, w.X, w.Y)
+	}
+}
+
 func (a *App) applyTheme() {
 	switch a.config.Theme {
 	case "light":
@@ -145,59 +298,240 @@ func (a *App) applyTheme() {
 		a.fyneApp.Settings().SetTheme(&darkTheme{})
 	default:
 		// Use system theme (default Fyne behavior)
+		a.fyneApp.Settings().SetTheme(theme.DefaultTheme())
 	}
 }
 
-func (a *App) loadPrompts() error {
-	prompts, err := prompt.LoadDirectory(a.config.PromptsDir)
+func (a *App) setupWatcher() error {
+	w, err := watcher.New(a.config.PromptsDir, func() {
+		// Reload prompts on change; subscribers refresh the UI
+		a.library.Reload()
+	})
 	if err != nil {
 		return err
 	}
-	a.prompts = prompts
-	return nil
+
+	if a.config.Watch.Mode == "poll" {
+		w.SetPolling(a.config.PollInterval())
+	}
+	w.SetIgnorePatterns(append(append([]string{}, watcher.DefaultIgnorePatterns...), a.config.Watch.Ignore...))
+	// Group folders are one level down; other subfolders aren't prompts
+	w.SetSubdirs(a.config.GroupFolders)
+	w.SetEventHandler(a.recordExternal)
+	w.SetStatusHandler(func(status watcher.Status) {
+		fyne.Do(func() {
+			a.mainView.SetWatchStatus(status)
+		})
+	})
+
+	a.watcher = w
+	return w.Start()
 }
 
-func (a *App) setupWatcher() error {
-	w, err := watcher.New(a.config.PromptsDir, func() {
-		// Reload prompts on change
-		if err := a.loadPrompts(); err != nil {
-			return
-		}
-		// Refresh UI on main thread
-		a.mainView.Refresh(a.prompts)
+func (a *App) setupConfigWatcher() error {
+	path, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	w, err := watcher.New(filepath.Dir(path), func() {
+		base, err := config.LoadFromPath(path)
+		fyne.Do(func() {
+			if err != nil {
+				a.mainView.SetConfigError(err)
+				return
+			}
+			cfg, err := base.WithProfile(a.keptProfile(base))
+			if err != nil {
+				a.mainView.SetConfigError(err)
+				return
+			}
+			if a.applyConfig(cfg) {
+				a.baseConfig = base
+				a.profilesChanged()
+			}
+		})
 	})
 	if err != nil {
 		return err
 	}
+	w.SetFilter(func(p string) bool {
+		return filepath.Base(p) == filepath.Base(path)
+	})
 
-	a.watcher = w
+	a.cfgWatch = w
 	return w.Start()
 }
 
+// applyConfig switches the running app to a new configuration and reports
+// whether it was applied. If the new prompts directory can't be used the
+// previous config stays in effect. It must be called on the main thread.
+func (a *App) applyConfig(cfg *config.Config) bool {
+	old := a.config
+
+	a.library.SetGroupFolders(cfg.GroupFolders)
+	if cfg.PromptsDir != old.PromptsDir {
+		if err := a.library.SetDir(cfg.PromptsDir); err != nil {
+			a.library.SetGroupFolders(old.GroupFolders)
+			a.library.SetDir(old.PromptsDir)
+			a.mainView.SetConfigError(err)
+			return false
+		}
+	} else if cfg.GroupFolders != old.GroupFolders {
+		if err := a.library.Reload(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	a.config = cfg
+	a.mainView.SetConfigError(nil)
+
+	if cfg.Theme != old.Theme {
+		a.applyTheme()
+	}
+
+	if cfg.Hotkey != old.Hotkey {
+		a.applyHotkey(old)
+	}
+
+	if cfg.PromptsDir != old.PromptsDir || cfg.GroupFolders != old.GroupFolders || !sameWatchConfig(cfg.Watch, old.Watch) {
+		if a.watcher != nil {
+			a.watcher.Stop()
+			a.watcher = nil
+		}
+		if err := a.setupWatcher(); err != nil {
+			a.mainView.SetWatchStatus(watcher.Status{State: watcher.StatePaused, Err: err})
+		}
+	}
+
+	if cfg.PromptsDir != old.PromptsDir {
+		a.setupGit()
+		a.setupMenus()
+	}
+	return true
+}
+
+// keptProfile returns the active profile if base still defines it. If it
+// was removed the base config is used instead, and the user is told.
+func (a *App) keptProfile(base *config.Config) string {
+	name := a.config.Profile
+	if _, ok := base.Profiles[name]; name != "" && !ok {
+		a.showToast(fmt.Sprintf("Profile %s was removed, so the base config is used", name), false)
+		return ""
+	}
+	return name
+}
+
+// SwitchProfile applies the named profile to the base config, reloading
+// prompts and the watcher if the prompts directory changes. An empty name
+// switches back to the base config.
+func (a *App) SwitchProfile(name string) {
+	if name == a.config.Profile {
+		return
+	}
+	cfg, err := a.baseConfig.WithProfile(name)
+	if err != nil {
+		a.mainView.SetConfigError(err)
+		return
+	}
+	if a.applyConfig(cfg) {
+		a.profilesChanged()
+	}
+}
+
+// profilesChanged updates the profile switchers after a profile switch or
+// config reload
+func (a *App) profilesChanged() {
+	a.mainView.UpdateProfiles()
+	a.setupSystemTray()
+}
+
+// ProfileNames returns the configured profile names
+func (a *App) ProfileNames() []string {
+	return a.baseConfig.ProfileNames()
+}
+
+// ActiveProfile returns the active profile name, or "" for the base config
+func (a *App) ActiveProfile() string {
+	return a.config.Profile
+}
+
+func sameWatchConfig(a, b config.WatchConfig) bool {
+	return a.Mode == b.Mode && a.PollInterval == b.PollInterval && slices.Equal(a.Ignore, b.Ignore)
+}
+
 func (a *App) setupSystemTray() {
 	if desk, ok := a.fyneApp.(desktop.App); ok {
-		menu := fyne.NewMenu("Cuecard",
+		items := []*fyne.MenuItem{
 			fyne.NewMenuItem("Show", func() {
 				a.window.Show()
 			}),
+		}
+
+		if names := a.ProfileNames(); len(names) > 0 {
+			profileItem := fyne.NewMenuItem("Profile", nil)
+			profileItem.ChildMenu = fyne.NewMenu("", a.profileMenuItems(names)...)
+			items = append(items, profileItem)
+		}
+
+		items = append(items,
 			fyne.NewMenuItemSeparator(),
 			fyne.NewMenuItem("Quit", func() {
+				a.saveState()
 				a.fyneApp.Quit()
 			}),
 		)
-		desk.SetSystemTrayMenu(menu)
+		desk.SetSystemTrayMenu(fyne.NewMenu("Cuecard", items...))
+	}
+}
+
+// profileMenuItems builds checkable menu items for the base config and each
+// profile
+func (a *App) profileMenuItems(names []string) []*fyne.MenuItem {
+	active := a.ActiveProfile()
+
+	base := fyne.NewMenuItem(noProfileLabel, func() {
+		a.SwitchProfile("")
+	})
+	base.Checked = active == ""
+	items := []*fyne.MenuItem{base, fyne.NewMenuItemSeparator()}
+
+	for _, name := range names {
+		item := fyne.NewMenuItem(name, func() {
+			a.SwitchProfile(name)
+		})
+		item.Checked = name == active
+		items = append(items, item)
 	}
+	return items
 }
 
 func (a *App) setupMenus() {
 	fileMenu := fyne.NewMenu("File",
 		fyne.NewMenuItem("New Prompt", a.showNewPromptDialog),
 		fyne.NewMenuItemSeparator(),
-		fyne.NewMenuItem("Import File...", a.importFile),
-		fyne.NewMenuItem("Import Directory...", a.importDirectory),
+		a.importMenuItem(),
+		fyne.NewMenuItem("Export...", func() { a.ExportPrompts(nil) }),
 		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("Open Folder", a.openPromptsFolder),
 		fyne.NewMenuItem("Refresh", a.refresh),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Settings...", a.showSettings),
+	)
+
+	undoItem := fyne.NewMenuItem("Undo", a.Undo)
+	undoItem.Shortcut = undoShortcut
+	selectAllItem := fyne.NewMenuItem("Select All Shown", func() { a.mainView.SelectAll() })
+	selectAllItem.Shortcut = selectAllShortcut
+	editMenu := fyne.NewMenu("Edit",
+		undoItem,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Paste as New Prompt", a.pastePrompt),
+		fyne.NewMenuItemSeparator(),
+		selectAllItem,
+		fyne.NewMenuItem("Clear Selection", func() { a.mainView.ClearSelection() }),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Trash...", a.showTrash),
 	)
 
 	helpMenu := fyne.NewMenu("Help",
@@ -206,26 +540,101 @@ func (a *App) setupMenus() {
 		fyne.NewMenuItem("About", a.showAbout),
 	)
 
-	mainMenu := fyne.NewMainMenu(fileMenu, helpMenu)
+	menus := []*fyne.Menu{fileMenu, editMenu}
+	if gitMenu := a.gitMenu(); gitMenu != nil {
+		menus = append(menus, gitMenu)
+	}
+	mainMenu := fyne.NewMainMenu(append(menus, helpMenu)...)
 	a.window.SetMainMenu(mainMenu)
 }
 
 func (a *App) showNewPromptDialog() {
-	ShowNewPromptDialog(a.window, a.config.PromptsDir, func() {
+	a.newPrompt(&prompt.Prompt{})
+}
+
+// newPrompt shows the prompt editor for a new prompt starting from p
+func (a *App) newPrompt(p *prompt.Prompt) {
+	ShowNewPromptDialog(a.window, p, a.config.PromptsDir, a.config.GroupFolders, a.config.Variables, func(path string) {
+		a.recordHistory(path, history.Saved)
 		a.refresh()
 	})
 }
 
-func (a *App) importFile() {
-	ShowImportFileDialog(a.window, a.config.PromptsDir, func() {
-		a.refresh()
+// EditPrompt shows the prompt editor for p
+func (a *App) EditPrompt(p *prompt.Prompt) {
+	ShowEditPromptDialog(a.window, p, a.config.Variables, func(oldTitle string, before []byte) {
+		if before == nil {
+			// Reloaded from disk, so there is nothing to undo
+			a.refresh()
+			return
+		}
+		undoFn := a.savedUndo(p, before)
+		a.recordReplaced(p.FilePath, before)
+		a.recordHistory(p.FilePath, history.Saved)
+		a.relocatePrompt(p, oldTitle)
+		a.done("Edit "+oldTitle, undoFn)
 	})
 }
 
-func (a *App) importDirectory() {
-	ShowImportDirectoryDialog(a.window, a.config.PromptsDir, func() {
+// relocatePrompt files an edited prompt: it moves to its group's folder when
+// group folders are on, and renaming the file is offered if the title
+// changed. Favorites and aliases are frontmatter, so they move with the file.
+func (a *App) relocatePrompt(p *prompt.Prompt, oldTitle string) {
+	root := a.config.PromptsDir
+	if a.config.GroupFolders {
+		dst, err := prompt.TargetPath(p, root, false, true)
+		if err == nil {
+			err = a.movePrompt(p, dst)
+		}
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to move prompt to its group folder: %w", err), a.window)
+		}
+	}
+	a.refresh()
+
+	if p.Title == oldTitle {
+		return
+	}
+	dst, err := prompt.TargetPath(p, root, true, false)
+	if err != nil || dst == p.FilePath {
+		return
+	}
+	msg := fmt.Sprintf("Rename %s to %s to match the new title?", p.FileName, filepath.Base(dst))
+	dialog.ShowConfirm("Rename File", msg, func(rename bool) {
+		if !rename {
+			return
+		}
+		if err := a.movePrompt(p, dst); err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
 		a.refresh()
-	})
+	}, a.window)
+}
+
+// importMenuItem returns the Import submenu. Files and folders in other
+// formats are detected and previewed before importing.
+func (a *App) importMenuItem() *fyne.MenuItem {
+	item := fyne.NewMenuItem("Import", nil)
+	item.ChildMenu = fyne.NewMenu("",
+		fyne.NewMenuItem("Prompt File...", a.importFile),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("File...", a.importSource),
+		fyne.NewMenuItem("Folder...", a.importFolder),
+	)
+	return item
+}
+
+func (a *App) importFile() {
+	ShowImportFileDialog(a.window, func(path string) { a.importPromptFile(path, "") })
+}
+
+func (a *App) importSource() {
+	ShowImportSourceDialog(a.window, a.PreviewImport)
+}
+
+func (a *App) importFolder() {
+	ShowImportFolderDialog(a.window, a.PreviewImport)
 }
 
 func (a *App) openPromptsFolder() {
@@ -242,24 +651,93 @@ func (a *App) openPromptsFolder() {
 }
 
 func (a *App) refresh() {
-	if err := a.loadPrompts(); err != nil {
-		return
-	}
-	a.mainView.Refresh(a.prompts)
+	a.library.Reload()
 }
 
 func (a *App) showValidation() {
-	ShowValidationDialog(a.window, a.config.PromptsDir)
+	ShowValidationDialog(a.window, a.config.PromptsDir, a.config.GroupFolders)
+}
+
+func (a *App) showSettings() {
+	ShowSettingsDialog(a.window, a.baseConfig, func(base *config.Config) error {
+		if err := base.Save(); err != nil {
+			return err
+		}
+
+		// Apply now rather than waiting for the config watcher
+		cfg, err := base.WithProfile(a.keptProfile(base))
+		if err != nil {
+			return err
+		}
+		if a.applyConfig(cfg) {
+			a.baseConfig = base
+		}
+		return nil
+	})
+}
+
+// applyHotkey registers the configured hotkey as a window shortcut that
+// focuses search, replacing the one from the previous config. Fyne has no
+// global hotkey support, so it only works while the window has focus.
+func (a *App) applyHotkey(old *config.Config) {
+	canvas := a.window.Canvas()
+	if old != nil {
+		if shortcut := hotkeyShortcut(old.Hotkey); shortcut != nil {
+			canvas.RemoveShortcut(shortcut)
+		}
+	}
+	if shortcut := hotkeyShortcut(a.config.Hotkey); shortcut != nil {
+		canvas.AddShortcut(shortcut, func(fyne.Shortcut) {
+			a.window.Show()
+			a.window.RequestFocus()
+			a.mainView.FocusSearch()
+		})
+	}
+}
+
+// hotkeyShortcut converts a config hotkey to a Fyne shortcut, or returns nil
+// if none is set
+func hotkeyShortcut(s string) *desktop.CustomShortcut {
+	if s == "" {
+		return nil
+	}
+	hk, err := config.ParseHotkey(s)
+	if err != nil {
+		return nil
+	}
+
+	var mod fyne.KeyModifier
+	if hk.Ctrl {
+		mod |= fyne.KeyModifierControl
+	}
+	if hk.Alt {
+		mod |= fyne.KeyModifierAlt
+	}
+	if hk.Shift {
+		mod |= fyne.KeyModifierShift
+	}
+	if hk.Super {
+		mod |= fyne.KeyModifierSuper
+	}
+	return &desktop.CustomShortcut{KeyName: fyne.KeyName(hk.Key), Modifier: mod}
 }
 
 func (a *App) showAbout() {
 	ShowAboutDialog(a.window)
 }
 
-// OpenInEditor opens a file in the configured editor
-func (a *App) OpenInEditor(path string) {
-	cmd := exec.Command(a.config.Editor, path)
-	cmd.Start()
+// OpenInEditor opens a file at line in the configured editor, showing
+// launch failures in a dialog
+func (a *App) OpenInEditor(path string, line int) {
+	ed := editor.New(a.config.Editor, a.config.Terminal)
+	err := ed.Open(path, line, func(err error) {
+		fyne.Do(func() {
+			dialog.ShowError(err, a.window)
+		})
+	})
+	if err != nil {
+		dialog.ShowError(err, a.window)
+	}
 }
 
 // CopyPrompt copies a prompt to clipboard with variable substitution
@@ -273,12 +751,19 @@ func (a *App) CopyPrompt(p *prompt.Prompt, inputValue string) {
 	}
 
 	content := prompt.Substitute(p.Content, resolver)
+	content = prompt.SubstituteWithValues(content, a.config.Variables)
 	a.clipboard.Copy(content)
+	a.state.RecordUse(p.FilePath)
 }
 
-// GetPrompts returns the current prompts
+// GetPrompts returns a snapshot of the current prompts
 func (a *App) GetPrompts() []*prompt.Prompt {
-	return a.prompts
+	return a.library.Snapshot()
+}
+
+// Library returns the shared prompt library
+func (a *App) Library() *prompt.Library {
+	return a.library
 }
 
 // GetConfig returns the configuration
@@ -0,0 +1,309 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/grantcarthew/cuecard/internal/bulk"
+	"github.com/grantcarthew/cuecard/internal/history"
+	"github.com/grantcarthew/cuecard/internal/prompt"
+	"github.com/grantcarthew/cuecard/internal/trash"
+)
+
+// selectAllShortcut is Ctrl+Shift+A, or Cmd+Shift+A on macOS. Ctrl+A is
+// left to the search box.
+var selectAllShortcut = &desktop.CustomShortcut{
+	KeyName:  fyne.KeyA,
+	Modifier: fyne.KeyModifierShortcutDefault | fyne.KeyModifierShift,
+}
+
+// newBulkBar returns the bar of actions on the selected prompts. It is
+// hidden until prompts are selected.
+func (mv *MainView) newBulkBar() *fyne.Container {
+	a := mv.app
+	mv.selectedLabel = widget.NewLabel("")
+	mv.selectedLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	var tagsBtn *widget.Button
+	tagsBtn = widget.NewButton("Tags", func() {
+		menu := fyne.NewMenu("",
+			fyne.NewMenuItem("Add Tags...", a.bulkAddTags),
+			fyne.NewMenuItem("Remove Tags...", a.bulkRemoveTags),
+		)
+		c := fyne.CurrentApp().Driver().CanvasForObject(tagsBtn)
+		pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(tagsBtn)
+		widget.ShowPopUpMenuAtPosition(menu, c, pos.Add(fyne.NewPos(0, tagsBtn.Size().Height)))
+	})
+
+	deleteBtn := widget.NewButton("Delete", a.bulkDelete)
+	deleteBtn.Importance = widget.DangerImportance
+	bar := container.NewHBox(
+		mv.selectedLabel,
+		widget.NewButton("Group...", a.bulkSetGroup),
+		tagsBtn,
+		widget.NewButton("Favorite", func() { a.bulkFavorite(true) }),
+		widget.NewButton("Unfavorite", func() { a.bulkFavorite(false) }),
+		widget.NewButton("Export...", func() { a.ExportPrompts(mv.Selected()) }),
+		widget.NewButton("Duplicate", a.bulkDuplicate),
+		deleteBtn,
+		widget.NewButton("Clear", mv.ClearSelection),
+	)
+	bar.Hide()
+	return bar
+}
+
+// SelectClick handles a click on p's card. A Ctrl- or Cmd-click toggles
+// the prompt's selection and a Shift-click selects the range from the last
+// click. It reports whether the click was a selection, so it shouldn't
+// copy the prompt.
+func (mv *MainView) SelectClick(p *prompt.Prompt, modifier fyne.KeyModifier) bool {
+	switch {
+	case modifier&fyne.KeyModifierShift != 0:
+		mv.selection.Extend(mv.visible, p.FilePath)
+	case modifier&fyne.KeyModifierShortcutDefault != 0:
+		mv.selection.Toggle(p.FilePath)
+	default:
+		return false
+	}
+	mv.rebuildCards()
+	return true
+}
+
+// IsSelected reports whether p is selected
+func (mv *MainView) IsSelected(p *prompt.Prompt) bool {
+	return mv.selection.Has(p.FilePath)
+}
+
+// Selected returns the selected prompts
+func (mv *MainView) Selected() []*prompt.Prompt {
+	return mv.selection.Prompts(mv.prompts)
+}
+
+// SelectAll selects every prompt shown, which is those matching the search
+func (mv *MainView) SelectAll() {
+	mv.selection.Set(mv.visible)
+	mv.rebuildCards()
+}
+
+// ClearSelection deselects every prompt
+func (mv *MainView) ClearSelection() {
+	mv.selection.Clear()
+	mv.rebuildCards()
+}
+
+// updateBulkBar shows the bulk actions while prompts are selected
+func (mv *MainView) updateBulkBar() {
+	n := len(mv.Selected())
+	if n == 0 {
+		mv.bulkBar.Hide()
+		return
+	}
+	mv.selectedLabel.SetText(fmt.Sprintf("%d selected", n))
+	mv.bulkBar.Show()
+}
+
+// bulkEdit makes edit to the selected prompts as one undoable action. label
+// describes the action, with %d for the number of prompts changed.
+func (a *App) bulkEdit(label string, edit bulk.Edit) {
+	a.editPrompts(a.mainView.Selected(), label, edit)
+}
+
+// editPrompts makes edit to prompts as one undoable action, labelled as for
+// bulkEdit. Changed prompts move to their group's folder when group folders
+// are on.
+func (a *App) editPrompts(prompts []*prompt.Prompt, label string, edit bulk.Edit) {
+	undos := make(map[*prompt.Prompt]func() error, len(prompts))
+	for _, p := range prompts {
+		undos[p] = a.editUndo(p)
+		a.recordBefore(p.FilePath)
+	}
+
+	changed, err := bulk.Apply(prompts, edit)
+	errs := []error{err}
+	for _, p := range changed {
+		a.recordHistory(p.FilePath, history.Saved)
+		if a.config.GroupFolders {
+			dst, err := prompt.TargetPath(p, a.config.PromptsDir, false, true)
+			if err == nil {
+				err = a.movePrompt(p, dst)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to move %s to its group folder: %w", p.FileName, err))
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		dialog.ShowError(fmt.Errorf("some prompts were not changed: %w", err), a.window)
+	}
+	a.refresh()
+	if len(changed) == 0 {
+		a.showToast("No prompts changed", false)
+		return
+	}
+
+	a.done(fmt.Sprintf(label, len(changed)), func() error {
+		var errs []error
+		for _, p := range changed {
+			if err := undos[p](); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+func (a *App) bulkSetGroup() {
+	_, groups, _ := prompt.GroupPrompts(a.GetPrompts())
+	ShowBulkGroupDialog(a.window, len(a.mainView.Selected()), prompt.SortedGroupNames(groups), func(group string) {
+		a.bulkEdit("Set group of %d prompts", bulk.SetGroup(group))
+	})
+}
+
+func (a *App) bulkAddTags() {
+	ShowBulkTagsDialog(a.window, "Add Tags", "Add", allTags(a.GetPrompts()), func(tags []string) {
+		a.bulkEdit("Add tags to %d prompts", bulk.AddTags(tags))
+	})
+}
+
+func (a *App) bulkRemoveTags() {
+	ShowBulkTagsDialog(a.window, "Remove Tags", "Remove", allTags(a.mainView.Selected()), func(tags []string) {
+		a.bulkEdit("Remove tags from %d prompts", bulk.RemoveTags(tags))
+	})
+}
+
+func (a *App) bulkFavorite(favorite bool) {
+	label := "Favorite %d prompts"
+	if !favorite {
+		label = "Unfavorite %d prompts"
+	}
+	a.bulkEdit(label, bulk.SetFavorite(favorite))
+}
+
+// bulkDuplicate copies the selected prompts as one undoable action
+func (a *App) bulkDuplicate() {
+	var dups []string
+	var errs []error
+	for _, p := range a.mainView.Selected() {
+		dup, err := prompt.DuplicatePrompt(p)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		a.recordHistory(dup.FilePath, history.Saved)
+		dups = append(dups, dup.FilePath)
+	}
+	if err := errors.Join(errs...); err != nil {
+		dialog.ShowError(fmt.Errorf("some prompts were not duplicated: %w", err), a.window)
+	}
+	a.refresh()
+	if len(dups) == 0 {
+		return
+	}
+
+	a.done(fmt.Sprintf("Duplicate %d prompts", len(dups)), func() error {
+		var errs []error
+		for _, path := range dups {
+			if _, err := a.trash.Move(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// bulkDelete moves the selected prompts to the trash, once confirmed, as
+// one undoable action
+func (a *App) bulkDelete() {
+	selected := a.mainView.Selected()
+	if len(selected) == 0 {
+		return
+	}
+	msg := fmt.Sprintf("Move %d prompts to the trash?", len(selected))
+	dialog.ShowConfirm("Delete Prompts", msg, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		var items []trash.Item
+		var errs []error
+		for _, p := range selected {
+			item, err := a.trash.Move(p.FilePath)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			items = append(items, item)
+		}
+		if err := errors.Join(errs...); err != nil {
+			dialog.ShowError(fmt.Errorf("some prompts were not deleted: %w", err), a.window)
+		}
+		a.mainView.ClearSelection()
+		a.refresh()
+		if len(items) == 0 {
+			return
+		}
+
+		a.done(fmt.Sprintf("Delete %d prompts", len(items)), func() error {
+			var errs []error
+			for _, item := range items {
+				if _, err := a.trash.Restore(item.ID); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			return errors.Join(errs...)
+		})
+	}, a.window)
+}
+
+// ShowBulkGroupDialog asks for the group to put n prompts in, offering the
+// existing groups. An empty group ungroups them.
+func ShowBulkGroupDialog(window fyne.Window, n int, groups []string, onSet func(group string)) {
+	entry := widget.NewSelectEntry(groups)
+	entry.SetPlaceHolder("Leave empty to ungroup")
+	items := []*widget.FormItem{widget.NewFormItem("Group", entry)}
+	d := dialog.NewForm(fmt.Sprintf("Set Group of %d Prompts", n), "Set", "Cancel", items, func(ok bool) {
+		if ok {
+			onSet(entry.Text)
+		}
+	}, window)
+	d.Resize(fyne.NewSize(400, d.MinSize().Height))
+	d.Show()
+}
+
+// ShowBulkTagsDialog asks for a comma separated list of tags, offering
+// options to pick from
+func ShowBulkTagsDialog(window fyne.Window, title, confirm string, options []string, onDone func(tags []string)) {
+	entry := widget.NewSelectEntry(options)
+	entry.SetPlaceHolder("tag, another tag")
+	items := []*widget.FormItem{widget.NewFormItem("Tags", entry)}
+	d := dialog.NewForm(title, confirm, "Cancel", items, func(ok bool) {
+		if tags := bulk.ParseTags(entry.Text); ok && len(tags) > 0 {
+			onDone(tags)
+		}
+	}, window)
+	d.Resize(fyne.NewSize(400, d.MinSize().Height))
+	d.Show()
+}
+
+// allTags returns the tags used by prompts, sorted
+func allTags(prompts []*prompt.Prompt) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, p := range prompts {
+		for _, tag := range p.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
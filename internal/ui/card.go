@@ -3,11 +3,15 @@ package ui
 import (
 	"errors"
 	"image/color"
+	"os"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/grantcarthew/cuecard/internal/prompt"
@@ -33,16 +37,23 @@ type PromptCard struct {
 	prompt     *prompt.Prompt
 	app        *App
 	compact    bool
+	selected   bool
+	filter     prompt.TagFilter // highlights the chips being filtered on
+	modifier   fyne.KeyModifier // keys held at the last mouse down
+	dragPos    fyne.Position    // where a drag of the card last reached
 	container  *fyne.Container
 	inputEntry *widget.Entry
 }
 
-// NewPromptCard creates a new prompt card
-func NewPromptCard(p *prompt.Prompt, app *App, compact bool) *PromptCard {
+// NewPromptCard creates a new prompt card, highlighted if selected. Its tag
+// and variable chips are highlighted when in filter.
+func NewPromptCard(p *prompt.Prompt, app *App, compact, selected bool, filter prompt.TagFilter) *PromptCard {
 	card := &PromptCard{
-		prompt:  p,
-		app:     app,
-		compact: compact,
+		prompt:   p,
+		app:      app,
+		compact:  compact,
+		selected: selected,
+		filter:   filter,
 	}
 	card.ExtendBaseWidget(card)
 	card.build()
@@ -60,14 +71,17 @@ func (c *PromptCard) build() {
 	})
 	starBtn.Importance = widget.LowImportance
 
-	// Title button - clicking copies to clipboard
+	// Title button - clicking copies to clipboard, or selects with Ctrl or Shift
 	titleBtn := widget.NewButton(c.prompt.Title, func() {
+		if c.selectClick() {
+			return
+		}
 		c.copyToClipboard()
 	})
 	titleBtn.Importance = widget.LowImportance
 	titleBtn.Alignment = widget.ButtonAlignLeading
 
-	titleRow := container.NewBorder(nil, nil, starBtn, nil, titleBtn)
+	titleRow := container.NewBorder(nil, nil, starBtn, newGitBadge(c.app.GitStatus(c.prompt.FilePath)), titleBtn)
 
 	// Input field if needed
 	var inputContainer fyne.CanvasObject
@@ -85,18 +99,24 @@ func (c *PromptCard) build() {
 		inputContainer = c.inputEntry
 	}
 
-	// Build layout
-	var content *fyne.Container
+	// Build layout. The description, tags and variables are left out of
+	// compact cards.
+	rows := []fyne.CanvasObject{titleRow}
+	if !c.compact && c.prompt.Description != "" {
+		desc := widget.NewLabel(c.prompt.Description)
+		desc.Truncation = fyne.TextTruncateEllipsis
+		desc.Importance = widget.LowImportance
+		rows = append(rows, desc)
+	}
 	if inputContainer != nil {
-		content = container.NewVBox(
-			titleRow,
-			inputContainer,
-		)
-	} else {
-		content = container.NewVBox(
-			titleRow,
-		)
+		rows = append(rows, inputContainer)
 	}
+	if !c.compact {
+		if chips := c.chips(); chips != nil {
+			rows = append(rows, chips)
+		}
+	}
+	content := container.NewVBox(rows...)
 
 	// Card background - use group color if available
 	var bgColor color.Color
@@ -117,13 +137,48 @@ func (c *PromptCard) build() {
 	bg.CornerRadius = 8
 	bg.StrokeColor = color.RGBA{180, 180, 180, 255}
 	bg.StrokeWidth = 1
+	if c.selected {
+		bg.StrokeColor = theme.Color(theme.ColorNamePrimary)
+		bg.StrokeWidth = 3
+	}
 
 	c.container = container.NewStack(bg, container.NewPadded(content))
 }
 
+// chips returns a row of badges for the variables the prompt uses and
+// chips for its tags, or nil if it has neither. Clicking one filters the
+// cards by it.
+func (c *PromptCard) chips() fyne.CanvasObject {
+	row := container.NewHBox()
+	for _, v := range c.prompt.GetVariables() {
+		row.Add(newChip("$"+strings.ToLower(v), c.filter.HasVariable(v), func() {
+			c.app.mainView.ToggleVariableFilter(v)
+		}))
+	}
+	for _, tag := range c.prompt.Tags {
+		row.Add(newChip("#"+tag, c.filter.HasTag(tag), func() {
+			c.app.mainView.ToggleTagFilter(tag)
+		}))
+	}
+	if len(row.Objects) == 0 {
+		return nil
+	}
+	return container.NewHScroll(row)
+}
+
+// newChip returns a small button for a tag or variable, highlighted while
+// it is being filtered on
+func newChip(text string, active bool, onTapped func()) *widget.Button {
+	chip := widget.NewButton(text, onTapped)
+	chip.Importance = widget.LowImportance
+	if active {
+		chip.Importance = widget.HighImportance
+	}
+	return chip
+}
+
 func (c *PromptCard) toggleFavorite() {
-	c.prompt.Favorite = !c.prompt.Favorite
-	if err := c.prompt.UpdateFavorite(c.prompt.Favorite); err != nil {
+	if err := c.app.ToggleFavorite(c.prompt); err != nil {
 		return
 	}
 	// Rebuild to update star icon
@@ -163,9 +218,35 @@ func (c *PromptCard) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(c.container)
 }
 
-// Tapped handles tap events - shows context menu on long press or secondary click
+// Tapped handles tap events - a Ctrl- or Shift-click selects the card
 func (c *PromptCard) Tapped(e *fyne.PointEvent) {
-	// Normal tap does nothing special
+	c.selectClick()
+}
+
+// MouseDown notes the keys held, since taps don't carry them
+func (c *PromptCard) MouseDown(e *desktop.MouseEvent) {
+	c.modifier = e.Modifier
+}
+
+// MouseUp is needed with MouseDown to receive mouse events
+func (c *PromptCard) MouseUp(*desktop.MouseEvent) {}
+
+// Dragged follows the card being dragged to a new place in its group
+func (c *PromptCard) Dragged(e *fyne.DragEvent) {
+	c.dragPos = e.AbsolutePosition
+}
+
+// DragEnd moves the card to where it was dropped
+func (c *PromptCard) DragEnd() {
+	c.app.mainView.DropCard(c.prompt, c.dragPos)
+}
+
+// selectClick passes a click with Ctrl or Shift held to the selection and
+// reports whether it did
+func (c *PromptCard) selectClick() bool {
+	modifier := c.modifier
+	c.modifier = 0
+	return c.app.mainView.SelectClick(c.prompt, modifier)
 }
 
 // TappedSecondary handles right-click - show context menu
@@ -179,9 +260,16 @@ func (c *PromptCard) showContextMenu(e *fyne.PointEvent) {
 			c.showPreview()
 		}),
 		fyne.NewMenuItem("Edit", func() {
-			ShowEditPromptDialog(c.app.window, c.prompt, func() {
-				c.app.refresh()
-			})
+			c.app.EditPrompt(c.prompt)
+		}),
+		fyne.NewMenuItem("Open in Editor", func() {
+			c.openInEditor()
+		}),
+		fyne.NewMenuItem("History", func() {
+			c.app.ShowHistory(c.prompt)
+		}),
+		fyne.NewMenuItem("Export...", func() {
+			c.app.ExportPrompts([]*prompt.Prompt{c.prompt})
 		}),
 		fyne.NewMenuItem("Duplicate", func() {
 			c.duplicate()
@@ -207,25 +295,26 @@ func (c *PromptCard) showPreview() {
 	dialog.ShowCustom(c.prompt.Title, "Close", scroll, c.app.window)
 }
 
-func (c *PromptCard) duplicate() {
-	_, err := prompt.DuplicatePrompt(c.prompt)
-	if err != nil {
-		dialog.ShowError(err, c.app.window)
-		return
+// openInEditor opens the prompt file with the cursor at the start of the
+// prompt body
+func (c *PromptCard) openInEditor() {
+	line := 1
+	if data, err := os.ReadFile(c.prompt.FilePath); err == nil {
+		line = prompt.BodyLine(string(data))
 	}
-	c.app.refresh()
+	c.app.OpenInEditor(c.prompt.FilePath, line)
+}
+
+func (c *PromptCard) duplicate() {
+	c.app.DuplicatePrompt(c.prompt)
 }
 
 func (c *PromptCard) confirmDelete() {
 	dialog.ShowConfirm("Delete Prompt",
-		"Are you sure you want to delete \""+c.prompt.Title+"\"?",
+		"Move \""+c.prompt.Title+"\" to the trash?",
 		func(confirmed bool) {
 			if confirmed {
-				if err := prompt.DeletePrompt(c.prompt); err != nil {
-					dialog.ShowError(err, c.app.window)
-					return
-				}
-				c.app.refresh()
+				c.app.DeletePrompt(c.prompt)
 			}
 		},
 		c.app.window,
@@ -264,14 +353,18 @@ type PromptListItem struct {
 	widget.BaseWidget
 	prompt    *prompt.Prompt
 	app       *App
+	selected  bool
+	modifier  fyne.KeyModifier // keys held at the last mouse down
+	dragPos   fyne.Position    // where a drag of the item last reached
 	container *fyne.Container
 }
 
-// NewPromptListItem creates a new list item
-func NewPromptListItem(p *prompt.Prompt, app *App) *PromptListItem {
+// NewPromptListItem creates a new list item, highlighted if selected
+func NewPromptListItem(p *prompt.Prompt, app *App, selected bool) *PromptListItem {
 	item := &PromptListItem{
-		prompt: p,
-		app:    app,
+		prompt:   p,
+		app:      app,
+		selected: selected,
 	}
 	item.ExtendBaseWidget(item)
 	item.build()
@@ -284,8 +377,7 @@ func (li *PromptListItem) build() {
 		starIcon = "★"
 	}
 	starBtn := widget.NewButton(starIcon, func() {
-		li.prompt.Favorite = !li.prompt.Favorite
-		li.prompt.UpdateFavorite(li.prompt.Favorite)
+		li.app.ToggleFavorite(li.prompt)
 		li.build()
 		li.Refresh()
 	})
@@ -293,6 +385,9 @@ func (li *PromptListItem) build() {
 
 	// Title button - clicking copies to clipboard
 	titleBtn := widget.NewButton(li.prompt.Title, func() {
+		if li.selectClick() {
+			return
+		}
 		li.app.CopyPrompt(li.prompt, "")
 	})
 	titleBtn.Importance = widget.LowImportance
@@ -306,12 +401,56 @@ func (li *PromptListItem) build() {
 	group := widget.NewLabel(li.prompt.Group)
 	group.Importance = widget.LowImportance
 
-	li.container = container.NewBorder(
+	right := container.NewHBox()
+	if badge := newGitBadge(li.app.GitStatus(li.prompt.FilePath)); badge != nil {
+		right.Add(badge)
+	}
+	right.Add(group)
+
+	row := container.NewBorder(
 		nil, nil,
 		container.NewHBox(starBtn, titleBtn),
-		group,
+		right,
 		desc,
 	)
+	if !li.selected {
+		li.container = row
+		return
+	}
+	highlight := canvas.NewRectangle(theme.Color(theme.ColorNameSelection))
+	highlight.CornerRadius = 4
+	li.container = container.NewStack(highlight, row)
+}
+
+// Tapped selects the item on a Ctrl- or Shift-click
+func (li *PromptListItem) Tapped(*fyne.PointEvent) {
+	li.selectClick()
+}
+
+// MouseDown notes the keys held, since taps don't carry them
+func (li *PromptListItem) MouseDown(e *desktop.MouseEvent) {
+	li.modifier = e.Modifier
+}
+
+// MouseUp is needed with MouseDown to receive mouse events
+func (li *PromptListItem) MouseUp(*desktop.MouseEvent) {}
+
+// Dragged follows the item being dragged to a new place in its group
+func (li *PromptListItem) Dragged(e *fyne.DragEvent) {
+	li.dragPos = e.AbsolutePosition
+}
+
+// DragEnd moves the item to where it was dropped
+func (li *PromptListItem) DragEnd() {
+	li.app.mainView.DropCard(li.prompt, li.dragPos)
+}
+
+// selectClick passes a click with Ctrl or Shift held to the selection and
+// reports whether it did
+func (li *PromptListItem) selectClick() bool {
+	modifier := li.modifier
+	li.modifier = 0
+	return li.app.mainView.SelectClick(li.prompt, modifier)
 }
 
 // CreateRenderer creates the widget renderer
@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+// showConflictDialog asks what to do when saving edited over p failed
+// because p's file changed on disk: reload the file and drop the edits,
+// overwrite the file, or merge the two. onSaved is called once the editor
+// closes with the file's content before it was saved over, or nil if it was
+// reloaded.
+func showConflictDialog(window fyne.Window, p, edited *prompt.Prompt, ed *editorDialog, onSaved func(before []byte)) {
+	msg := widget.NewLabel(fmt.Sprintf(
+		"%s was changed outside Cuecard while you were editing it.\n\n"+
+			"Reload discards your edits, Overwrite replaces the other changes, and "+
+			"Merge combines both.", p.FileName))
+	msg.Wrapping = fyne.TextWrapWord
+
+	var d *dialog.CustomDialog
+	done := func(before []byte, err error) {
+		d.Hide()
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		ed.Close()
+		onSaved(before)
+	}
+
+	reloadBtn := widget.NewButton("Reload", func() {
+		done(nil, p.Reload())
+	})
+	overwriteBtn := widget.NewButton("Overwrite", func() {
+		done(saveOver(p, func() error { return p.Replace(edited) }))
+	})
+	mergeBtn := widget.NewButton("Merge", func() {
+		d.Hide()
+		mergeConflict(window, p, edited, ed, onSaved)
+	})
+	mergeBtn.Importance = widget.HighImportance
+	cancelBtn := widget.NewButton("Keep Editing", func() {
+		d.Hide()
+	})
+
+	d = dialog.NewCustomWithoutButtons("File Changed on Disk", container.NewPadded(msg), window)
+	d.SetButtons([]fyne.CanvasObject{cancelBtn, reloadBtn, overwriteBtn, mergeBtn})
+	d.Resize(fyne.NewSize(460, 0))
+	d.Show()
+}
+
+// mergeConflict merges edited with the changes on disk. A clean merge is
+// saved straight away; otherwise the editor shows the merge with conflict
+// markers for the user to resolve and save.
+func mergeConflict(window fyne.Window, p, edited *prompt.Prompt, ed *editorDialog, onSaved func(before []byte)) {
+	merged, clean, err := p.MergeDisk(edited)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to merge changes: %w", err), window)
+		return
+	}
+
+	if !clean {
+		ed.SetPrompt(merged)
+		dialog.ShowInformation("Merge Conflicts",
+			"Some of your edits conflict with the changes on disk. Fields keep your "+
+				"value, and conflicting lines are marked with <<<<<<< and >>>>>>>. "+
+				"Resolve them and save again.", window)
+		return
+	}
+
+	before, err := saveOver(p, func() error { return p.Update(merged) })
+	var conflict *prompt.ConflictError
+	if errors.As(err, &conflict) {
+		// Changed again while merging
+		showConflictDialog(window, p, merged, ed, onSaved)
+		return
+	}
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to save prompt: %w", err), window)
+		return
+	}
+	ed.Close()
+	onSaved(before)
+}
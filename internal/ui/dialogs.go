@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,212 +16,74 @@ import (
 	"github.com/grantcarthew/cuecard/internal/prompt"
 )
 
-// ShowEditPromptDialog shows the edit prompt dialog with pre-populated values
-func ShowEditPromptDialog(window fyne.Window, p *prompt.Prompt, onSaved func()) {
-	titleEntry := widget.NewEntry()
-	titleEntry.SetText(p.Title)
-
-	descEntry := widget.NewEntry()
-	descEntry.SetText(p.Description)
-
-	groupEntry := widget.NewEntry()
-	groupEntry.SetText(p.Group)
-
-	tagsEntry := widget.NewEntry()
-	tagsEntry.SetText(strings.Join(p.Tags, ", "))
-
-	inputSelect := widget.NewSelect([]string{"None", "Optional", "Required"}, nil)
-	switch p.Input {
-	case "optional":
-		inputSelect.SetSelected("Optional")
-	case "required":
-		inputSelect.SetSelected("Required")
-	default:
-		inputSelect.SetSelected("None")
+// ShowEditPromptDialog shows the prompt editor with pre-populated values.
+// The custom variables are offered for completion and used in the preview.
+// onSaved is called with the title the prompt had before editing and the
+// file as it was just before it was saved over, or nil if the editor closed
+// by reloading the file instead.
+func ShowEditPromptDialog(window fyne.Window, p *prompt.Prompt, variables map[string]string, onSaved func(oldTitle string, before []byte)) {
+	oldTitle := p.Title
+	saved := func(before []byte) {
+		if onSaved != nil {
+			onSaved(oldTitle, before)
+		}
 	}
-
-	inputHintEntry := widget.NewEntry()
-	inputHintEntry.SetText(p.InputHint)
-
-	contentEntry := widget.NewMultiLineEntry()
-	contentEntry.SetText(p.Content)
-	contentEntry.Wrapping = fyne.TextWrapWord
-
-	// Build frontmatter form (fixed size at top)
-	frontmatterForm := widget.NewForm(
-		widget.NewFormItem("Title", titleEntry),
-		widget.NewFormItem("Description", descEntry),
-		widget.NewFormItem("Group", groupEntry),
-		widget.NewFormItem("Tags", tagsEntry),
-		widget.NewFormItem("Input", inputSelect),
-		widget.NewFormItem("Input Hint", inputHintEntry),
-	)
-
-	// Content label
-	contentLabel := widget.NewLabel("Content")
-	contentLabel.TextStyle = fyne.TextStyle{Bold: true}
-
-	// Layout: frontmatter at top, content expands to fill rest
-	content := container.NewBorder(
-		container.NewVBox(frontmatterForm, contentLabel),
-		nil, nil, nil,
-		contentEntry,
-	)
-
-	// Get window size for dialog
-	windowSize := window.Canvas().Size()
-
-	saveFunc := func() {
-		// Parse tags
-		var tags []string
-		if tagsEntry.Text != "" {
-			for _, t := range strings.Split(tagsEntry.Text, ",") {
-				t = strings.TrimSpace(t)
-				if t != "" {
-					tags = append(tags, t)
-				}
-			}
+	showPromptEditorDialog(window, "Edit Prompt", "Save", p, variables, func(ed *editorDialog) error {
+		edited := ed.Prompt()
+		before, err := saveOver(p, func() error { return p.Update(edited) })
+		var conflict *prompt.ConflictError
+		if errors.As(err, &conflict) {
+			showConflictDialog(window, p, edited, ed, saved)
+			return nil
 		}
-
-		// Determine input value
-		inputValue := ""
-		switch inputSelect.Selected {
-		case "Optional":
-			inputValue = "optional"
-		case "Required":
-			inputValue = "required"
+		if err != nil {
+			return fmt.Errorf("failed to save prompt: %w", err)
 		}
 
-		// Update the prompt
-		p.Title = titleEntry.Text
-		p.Description = descEntry.Text
-		p.Group = groupEntry.Text
-		p.Tags = tags
-		p.Input = inputValue
-		p.InputHint = inputHintEntry.Text
-		p.Content = contentEntry.Text
-
-		// Write to existing file
-		markdown := p.ToMarkdown()
-		if err := os.WriteFile(p.FilePath, []byte(markdown), 0644); err != nil {
-			dialog.ShowError(err, window)
-			return
-		}
+		ed.Close()
+		saved(before)
+		return nil
+	})
+}
 
-		if onSaved != nil {
-			onSaved()
-		}
+// saveOver runs save, which writes p's file, and returns the file as it was
+// just before. Reading it here rather than when editing starts means undo
+// and history keep any changes made on disk in the meantime.
+func saveOver(p *prompt.Prompt, save func() error) ([]byte, error) {
+	before, err := os.ReadFile(p.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt: %w", err)
 	}
-
-	d := dialog.NewCustomConfirm("Edit Prompt", "Save", "Cancel", content, func(save bool) {
-		if save {
-			saveFunc()
-		}
-	}, window)
-	d.Resize(fyne.NewSize(windowSize.Width-40, windowSize.Height-40))
-	d.Show()
+	return before, save()
 }
 
-// ShowNewPromptDialog shows the new prompt creation dialog
-func ShowNewPromptDialog(window fyne.Window, promptsDir string, onCreated func()) {
-	titleEntry := widget.NewEntry()
-	titleEntry.SetPlaceHolder("Prompt title")
-
-	descEntry := widget.NewEntry()
-	descEntry.SetPlaceHolder("Brief description (optional)")
-
-	groupEntry := widget.NewEntry()
-	groupEntry.SetPlaceHolder("Group name (optional)")
-
-	tagsEntry := widget.NewEntry()
-	tagsEntry.SetPlaceHolder("tag1, tag2, tag3 (optional)")
-
-	inputSelect := widget.NewSelect([]string{"None", "Optional", "Required"}, nil)
-	inputSelect.SetSelected("None")
-
-	inputHintEntry := widget.NewEntry()
-	inputHintEntry.SetPlaceHolder("Input field hint (optional)")
-
-	contentEntry := widget.NewMultiLineEntry()
-	contentEntry.SetPlaceHolder("Prompt content...\n\nUse ${INPUT} for user input, ${DATE}, ${DATETIME}, ${CLIPBOARD}, ${FILE}")
-	contentEntry.Wrapping = fyne.TextWrapWord
-
-	// Build frontmatter form (fixed size at top)
-	frontmatterForm := widget.NewForm(
-		widget.NewFormItem("Title", titleEntry),
-		widget.NewFormItem("Description", descEntry),
-		widget.NewFormItem("Group", groupEntry),
-		widget.NewFormItem("Tags", tagsEntry),
-		widget.NewFormItem("Input", inputSelect),
-		widget.NewFormItem("Input Hint", inputHintEntry),
-	)
-
-	// Content label
-	contentLabel := widget.NewLabel("Content")
-	contentLabel.TextStyle = fyne.TextStyle{Bold: true}
-
-	// Layout: frontmatter at top, content expands to fill rest
-	content := container.NewBorder(
-		container.NewVBox(frontmatterForm, contentLabel),
-		nil, nil, nil,
-		contentEntry,
-	)
-
-	// Get window size for dialog
-	windowSize := window.Canvas().Size()
-
-	createFunc := func() {
-		// Parse tags
-		var tags []string
-		if tagsEntry.Text != "" {
-			for _, t := range strings.Split(tagsEntry.Text, ",") {
-				t = strings.TrimSpace(t)
-				if t != "" {
-					tags = append(tags, t)
-				}
-			}
-		}
-
-		// Determine input value
-		inputValue := ""
-		switch inputSelect.Selected {
-		case "Optional":
-			inputValue = "optional"
-		case "Required":
-			inputValue = "required"
-		}
-
-		p := &prompt.Prompt{
-			Title:       titleEntry.Text,
-			Description: descEntry.Text,
-			Group:       groupEntry.Text,
-			Tags:        tags,
-			Input:       inputValue,
-			InputHint:   inputHintEntry.Text,
-			Content:     contentEntry.Text,
+// ShowNewPromptDialog shows the prompt editor for a new prompt, starting
+// with the fields set in p. With groupFolders set the prompt is created in
+// its group's folder. onCreated is called with the path of the created
+// prompt.
+func ShowNewPromptDialog(window fyne.Window, p *prompt.Prompt, promptsDir string, groupFolders bool, variables map[string]string, onCreated func(path string)) {
+	showPromptEditorDialog(window, "New Prompt", "Create", p, variables, func(ed *editorDialog) error {
+		p := ed.Prompt()
+		dir := prompt.PromptDir(promptsDir, p.Group, groupFolders)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create group folder: %w", err)
 		}
-
-		if _, err := prompt.CreatePromptFile(promptsDir, p); err != nil {
-			dialog.ShowError(err, window)
-			return
+		path, err := prompt.CreatePromptFile(dir, p)
+		if err != nil {
+			return err
 		}
 
+		ed.Close()
 		if onCreated != nil {
-			onCreated()
+			onCreated(path)
 		}
-	}
-
-	d := dialog.NewCustomConfirm("New Prompt", "Create", "Cancel", content, func(create bool) {
-		if create {
-			createFunc()
-		}
-	}, window)
-	d.Resize(fyne.NewSize(windowSize.Width-40, windowSize.Height-40))
-	d.Show()
+		return nil
+	})
 }
 
-// ShowImportFileDialog shows the file import dialog
-func ShowImportFileDialog(window fyne.Window, promptsDir string, onImported func()) {
+// ShowImportFileDialog asks for a single prompt or text file to import.
+// onChosen is called with its path.
+func ShowImportFileDialog(window fyne.Window, onChosen func(path string)) {
 	fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
 		if err != nil {
 			dialog.ShowError(err, window)
@@ -229,98 +92,82 @@ func ShowImportFileDialog(window fyne.Window, promptsDir string, onImported func
 		if reader == nil {
 			return // Cancelled
 		}
-		defer reader.Close()
-
-		// Read file content
-		data := make([]byte, 0)
-		buf := make([]byte, 1024)
-		for {
-			n, err := reader.Read(buf)
-			if n > 0 {
-				data = append(data, buf[:n]...)
-			}
-			if err != nil {
-				break
-			}
-		}
-
-		content := string(data)
-
-		// Check if it has valid frontmatter
-		if prompt.HasFrontmatter(content) {
-			p, err := prompt.Parse(content)
-			if err != nil {
-				dialog.ShowError(fmt.Errorf("invalid frontmatter: %w", err), window)
-				return
-			}
-
-			// Clear favorite for imports
-			p.Favorite = false
-			p.Content = content[strings.Index(content, "---\n")+4:]
-			if idx := strings.Index(p.Content, "---\n"); idx >= 0 {
-				p.Content = strings.TrimSpace(p.Content[idx+4:])
-			}
-
-			// Create the file
-			if _, err := prompt.CreatePromptFile(promptsDir, p); err != nil {
-				dialog.ShowError(err, window)
-				return
-			}
-
-			if onImported != nil {
-				onImported()
-			}
-		} else {
-			// Show form to add metadata
-			ShowImportFormDialog(window, promptsDir, content, onImported)
-		}
+		reader.Close()
+		onChosen(reader.URI().Path())
 	}, window)
 
-	fd.SetFilter(storage.NewExtensionFileFilter([]string{".md"}))
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{".md", ".txt"}))
 	fd.Show()
 }
 
-// ShowImportFormDialog shows a form to add metadata to imported content
-func ShowImportFormDialog(window fyne.Window, promptsDir string, content string, onImported func()) {
-	titleEntry := widget.NewEntry()
-	titleEntry.SetPlaceHolder("Prompt title")
-
-	groupEntry := widget.NewEntry()
-	groupEntry.SetPlaceHolder("Group name (optional)")
-
-	form := &widget.Form{
-		Items: []*widget.FormItem{
-			{Text: "Title", Widget: titleEntry},
-			{Text: "Group", Widget: groupEntry},
-		},
-		OnSubmit: func() {
-			p := &prompt.Prompt{
-				Title:   titleEntry.Text,
-				Group:   groupEntry.Text,
-				Content: content,
-			}
+// ShowImportPromptDialog imports the prompt file at src. A file with
+// frontmatter is imported as is; for one without, the prompt editor opens
+// with its text so the metadata can be filled in. Either way the user can
+// keep the source's file name. A non-empty group replaces the prompt's.
+// onImported is called with the created path.
+func ShowImportPromptDialog(window fyne.Window, src, group, promptsDir string, groupFolders bool, variables map[string]string, onImported func(paths []string)) {
+	p, hasFrontmatter, err := prompt.ReadImportFile(src)
+	if err != nil {
+		dialog.ShowError(err, window)
+		return
+	}
+	if group != "" {
+		p.Group = group
+	}
+	srcName := filepath.Base(src)
 
-			if _, err := prompt.CreatePromptFile(promptsDir, p); err != nil {
-				dialog.ShowError(err, window)
-				return
-			}
+	create := func(p *prompt.Prompt, keepName bool) (string, error) {
+		dir := prompt.PromptDir(promptsDir, p.Group, groupFolders)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create group folder: %w", err)
+		}
+		return prompt.ImportFile(dir, p, srcName, keepName)
+	}
+	done := func(path string) {
+		if onImported != nil {
+			onImported([]string{path})
+		}
+	}
 
-			if onImported != nil {
-				onImported()
-			}
-		},
+	keepCheck := widget.NewCheck(fmt.Sprintf("Keep the file name (%s.md)",
+		strings.TrimSuffix(srcName, filepath.Ext(srcName))), nil)
+	summary := widget.NewLabel(fmt.Sprintf("Import %q from %s.", p.Title, srcName))
+	confirm := "Import"
+	if !hasFrontmatter {
+		summary.SetText(fmt.Sprintf("%s has no frontmatter. Fill in the prompt's details next.", srcName))
+		confirm = "Edit Details..."
 	}
+	summary.Wrapping = fyne.TextWrapWord
 
-	d := dialog.NewForm("Import Prompt", "Import", "Cancel", form.Items, func(submitted bool) {
-		if submitted {
-			form.OnSubmit()
+	dialog.ShowCustomConfirm("Import Prompt", confirm, "Cancel", container.NewVBox(summary, keepCheck), func(ok bool) {
+		if !ok {
+			return
+		}
+		keepName := keepCheck.Checked
+		if hasFrontmatter {
+			path, err := create(p, keepName)
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			done(path)
+			return
 		}
+		showPromptEditorDialog(window, "Import Prompt", "Import", p, variables, func(ed *editorDialog) error {
+			path, err := create(ed.Prompt(), keepName)
+			if err != nil {
+				return err
+			}
+			ed.Close()
+			done(path)
+			return nil
+		})
 	}, window)
-	d.Show()
 }
 
-// ShowImportDirectoryDialog shows the directory import dialog
-func ShowImportDirectoryDialog(window fyne.Window, promptsDir string, onImported func()) {
+// ShowImportFolderDialog asks for a folder to import, such as a directory
+// of prompts or Fabric patterns. onChosen is called with its path.
+func ShowImportFolderDialog(window fyne.Window, onChosen func(path string)) {
 	fd := dialog.NewFolderOpen(func(uri fyne.ListableURI, err error) {
 		if err != nil {
 			dialog.ShowError(err, window)
@@ -329,81 +176,34 @@ func ShowImportDirectoryDialog(window fyne.Window, promptsDir string, onImported
 		if uri == nil {
 			return // Cancelled
 		}
+		onChosen(uri.Path())
+	}, window)
 
-		importDir := uri.Path()
-		imported := 0
-		skipped := 0
-		var errors []string
+	fd.Show()
+}
 
-		entries, err := os.ReadDir(importDir)
+// ShowImportSourceDialog asks for a file to import in any supported format,
+// such as an export bundle or a CSV file. onChosen is called with its path.
+func ShowImportSourceDialog(window fyne.Window, onChosen func(path string)) {
+	fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
 		if err != nil {
 			dialog.ShowError(err, window)
 			return
 		}
-
-		for _, entry := range entries {
-			if entry.IsDir() {
-				continue
-			}
-
-			name := entry.Name()
-			if !strings.HasSuffix(strings.ToLower(name), ".md") {
-				continue
-			}
-			if strings.EqualFold(name, "readme.md") {
-				skipped++
-				continue
-			}
-
-			path := filepath.Join(importDir, name)
-			data, err := os.ReadFile(path)
-			if err != nil {
-				errors = append(errors, fmt.Sprintf("%s: read error", name))
-				continue
-			}
-
-			content := string(data)
-			if !prompt.HasFrontmatter(content) {
-				skipped++
-				continue
-			}
-
-			p, err := prompt.Parse(content)
-			if err != nil {
-				errors = append(errors, fmt.Sprintf("%s: parse error", name))
-				continue
-			}
-
-			// Clear favorite for imports
-			p.Favorite = false
-
-			if _, err := prompt.CreatePromptFile(promptsDir, p); err != nil {
-				errors = append(errors, fmt.Sprintf("%s: create error", name))
-				continue
-			}
-
-			imported++
-		}
-
-		// Show summary
-		summary := fmt.Sprintf("Imported: %d\nSkipped: %d", imported, skipped)
-		if len(errors) > 0 {
-			summary += fmt.Sprintf("\nErrors: %d\n\n%s", len(errors), strings.Join(errors, "\n"))
-		}
-
-		dialog.ShowInformation("Import Complete", summary, window)
-
-		if onImported != nil && imported > 0 {
-			onImported()
+		if reader == nil {
+			return // Cancelled
 		}
+		reader.Close()
+		onChosen(reader.URI().Path())
 	}, window)
 
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{".zip", ".json", ".csv", ".md", ".txt"}))
 	fd.Show()
 }
 
 // ShowValidationDialog shows the validation results
-func ShowValidationDialog(window fyne.Window, promptsDir string) {
-	valid, warnings, errors := prompt.ValidateDirectory(promptsDir)
+func ShowValidationDialog(window fyne.Window, promptsDir string, groupFolders bool) {
+	valid, warnings, errors := prompt.ValidateDirectory(promptsDir, groupFolders)
 
 	var content strings.Builder
 	content.WriteString(fmt.Sprintf("%d prompts valid\n", valid))
@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/grantcarthew/cuecard/internal/bundle"
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+// Bundle formats offered for export
+const (
+	exportZip  = "Zip archive (.zip)"
+	exportJSON = "JSON bundle (.json)"
+)
+
+// ShowExportDialog lets the user pick prompts, by group or one by one, and
+// exports them to a bundle file. The prompts in preselected start checked;
+// with none every prompt does. onExported is called with the manifest.
+func ShowExportDialog(window fyne.Window, prompts, preselected []*prompt.Prompt, root, profile string, onExported func(bundle.Manifest, string)) {
+	checked := make(map[*prompt.Prompt]bool)
+	for _, p := range preselected {
+		checked[p] = true
+	}
+	if len(preselected) == 0 {
+		for _, p := range prompts {
+			checked[p] = true
+		}
+	}
+
+	countLabel := widget.NewLabel("")
+	exportBtn := widget.NewButton("Export...", nil)
+	exportBtn.Importance = widget.HighImportance
+
+	var promptChecks []*widget.Check
+	var groupChecks []func()
+	updating := false
+	update := func() {
+		n := 0
+		for _, v := range checked {
+			if v {
+				n++
+			}
+		}
+		countLabel.SetText(fmt.Sprintf("%d of %d prompts selected", n, len(prompts)))
+		if n == 0 {
+			exportBtn.Disable()
+		} else {
+			exportBtn.Enable()
+		}
+		updating = true
+		for _, sync := range groupChecks {
+			sync()
+		}
+		updating = false
+	}
+
+	list := container.NewVBox()
+	_, groups, ungrouped := prompt.GroupPrompts(prompts)
+	names := prompt.SortedGroupNames(groups)
+	if len(ungrouped) > 0 {
+		groups[""] = ungrouped
+		names = append(names, "")
+	}
+	for _, name := range names {
+		members := groups[name]
+		var checks []*widget.Check
+		for _, p := range members {
+			check := widget.NewCheck(p.Title, func(on bool) {
+				checked[p] = on
+				if !updating {
+					update()
+				}
+			})
+			check.Checked = checked[p]
+			checks = append(checks, check)
+			promptChecks = append(promptChecks, check)
+		}
+
+		label := name
+		if label == "" {
+			label = "Ungrouped"
+		}
+		groupCheck := widget.NewCheck(fmt.Sprintf("%s (%d)", label, len(members)), nil)
+		groupCheck.OnChanged = func(on bool) {
+			if updating {
+				return
+			}
+			updating = true
+			for _, check := range checks {
+				check.SetChecked(on)
+			}
+			updating = false
+			update()
+		}
+		groupChecks = append(groupChecks, func() {
+			all := true
+			for _, p := range members {
+				all = all && checked[p]
+			}
+			groupCheck.SetChecked(all)
+		})
+
+		list.Add(groupCheck)
+		list.Add(container.NewPadded(container.NewVBox(toObjects(checks)...)))
+	}
+
+	setAll := func(on bool) {
+		updating = true
+		for _, check := range promptChecks {
+			check.SetChecked(on)
+		}
+		updating = false
+		update()
+	}
+	allBtn := widget.NewButton("Select All", func() { setAll(true) })
+	noneBtn := widget.NewButton("Select None", func() { setAll(false) })
+
+	formatSelect := widget.NewSelect([]string{exportZip, exportJSON}, nil)
+	formatSelect.SetSelected(exportZip)
+
+	var d *dialog.CustomDialog
+	exportBtn.OnTapped = func() {
+		var selected []*prompt.Prompt
+		for _, p := range prompts {
+			if checked[p] {
+				selected = append(selected, p)
+			}
+		}
+		ext := ".zip"
+		if formatSelect.Selected == exportJSON {
+			ext = ".json"
+		}
+
+		fd := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			if writer == nil {
+				return // Cancelled
+			}
+			writer.Close()
+
+			path := writer.URI().Path()
+			if _, err := bundle.FormatForPath(path); err != nil {
+				// The save dialog created the file without an extension
+				os.Remove(path)
+				path += ext
+			}
+			m, err := bundle.ExportFile(path, selected, root, profile)
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			d.Hide()
+			if onExported != nil {
+				onExported(m, path)
+			}
+		}, window)
+		fd.SetFileName("cuecard-prompts" + ext)
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{ext}))
+		fd.Show()
+	}
+
+	scroll := container.NewVScroll(list)
+	content := container.NewBorder(
+		container.NewHBox(allBtn, noneBtn, layout.NewSpacer(), countLabel),
+		widget.NewForm(widget.NewFormItem("Format", formatSelect)),
+		nil, nil,
+		scroll,
+	)
+
+	update()
+	cancelBtn := widget.NewButton("Cancel", func() { d.Hide() })
+	d = dialog.NewCustomWithoutButtons("Export Prompts", content, window)
+	d.SetButtons([]fyne.CanvasObject{cancelBtn, exportBtn})
+	d.Resize(fyne.NewSize(480, 520))
+	d.Show()
+}
+
+// toObjects converts checks to canvas objects for a container
+func toObjects(checks []*widget.Check) []fyne.CanvasObject {
+	objects := make([]fyne.CanvasObject, len(checks))
+	for i, check := range checks {
+		objects[i] = check
+	}
+	return objects
+}
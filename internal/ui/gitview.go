@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/grantcarthew/cuecard/internal/git"
+)
+
+// setupGit opens the git repo containing the prompts directory, if any, and
+// updates the menus to match. Without git or a repo the app stays read-only
+// with respect to git and shows no git actions.
+func (a *App) setupGit() {
+	repo, err := git.Open(a.config.PromptsDir)
+	if err != nil && !errors.Is(err, git.ErrNotInstalled) && !errors.Is(err, git.ErrNotRepo) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	a.git = repo
+	a.gitStatus = nil
+	a.refreshGitStatus()
+}
+
+// refreshGitStatus reads the git status in the background and refreshes the
+// cards' badges if it changed. It must be called on the main thread.
+func (a *App) refreshGitStatus() {
+	repo := a.git
+	if repo == nil {
+		return
+	}
+	go func() {
+		status, err := repo.Status()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			return
+		}
+		fyne.Do(func() {
+			if a.git != repo {
+				return // Prompts directory changed meanwhile
+			}
+			if maps.Equal(a.gitStatus, status) {
+				return // The badges are already current
+			}
+			a.gitStatus = status
+			a.mainView.Refresh(a.GetPrompts())
+		})
+	}()
+}
+
+// GitStatus returns the git status of the prompt file at path
+func (a *App) GitStatus(path string) git.Status {
+	return a.gitStatus[path]
+}
+
+// runGit runs a git action in the background, showing a toast while it runs
+// and when it's done. The prompts are reloaded afterwards since a pull can
+// change them.
+func (a *App) runGit(label, doneMsg string, action func(*git.Repo) error) {
+	repo := a.git
+	if repo == nil {
+		return
+	}
+	a.showToast(label+"...", false)
+	go func() {
+		err := action(repo)
+		fyne.Do(func() {
+			if err != nil {
+				if a.toast != nil {
+					a.toast.Hide()
+				}
+				dialog.ShowError(err, a.window)
+			} else {
+				a.showToast(doneMsg, false)
+			}
+			a.refresh()
+			a.refreshGitStatus()
+		})
+	}()
+}
+
+func (a *App) gitPull() {
+	a.runGit("Pulling", "Pulled latest prompts", (*git.Repo).Pull)
+}
+
+func (a *App) gitPush() {
+	a.runGit("Pushing", "Pushed prompts", (*git.Repo).Push)
+}
+
+func (a *App) gitCommit() {
+	if a.git == nil {
+		return
+	}
+	ShowCommitDialog(a.window, a.config.PromptsDir, a.gitStatus, func(message string) {
+		a.runGit("Committing", "Committed prompts", func(r *git.Repo) error {
+			return r.Commit(message)
+		})
+	})
+}
+
+// gitMenu returns the Git menu, or nil when the prompts directory is not in
+// a git work tree
+func (a *App) gitMenu() *fyne.Menu {
+	if a.git == nil {
+		return nil
+	}
+	return fyne.NewMenu("Git",
+		fyne.NewMenuItem("Pull", a.gitPull),
+		fyne.NewMenuItem("Commit...", a.gitCommit),
+		fyne.NewMenuItem("Push", a.gitPush),
+	)
+}
+
+// ShowCommitDialog lists the changed prompt files and asks for a commit
+// message. onCommit is called with the message.
+func ShowCommitDialog(window fyne.Window, promptsDir string, status map[string]git.Status, onCommit func(message string)) {
+	var changes []string
+	for path, s := range status {
+		rel, err := filepath.Rel(promptsDir, path)
+		if err != nil {
+			rel = path
+		}
+		changes = append(changes, fmt.Sprintf("%s (%s)", rel, s))
+	}
+	sort.Strings(changes)
+
+	summary := "No changes found. Commit will check again."
+	if len(changes) > 0 {
+		summary = strings.Join(changes, "\n")
+	}
+	changesLabel := widget.NewLabel(summary)
+	changesScroll := container.NewVScroll(changesLabel)
+	changesScroll.SetMinSize(fyne.NewSize(0, 120))
+
+	messageEntry := widget.NewMultiLineEntry()
+	messageEntry.SetPlaceHolder("Describe your changes")
+	messageEntry.SetMinRowsVisible(3)
+	messageEntry.Validator = func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return errors.New("commit message is required")
+		}
+		return nil
+	}
+
+	items := []*widget.FormItem{
+		{Text: "Changes", Widget: changesScroll},
+		{Text: "Message", Widget: messageEntry},
+	}
+	d := dialog.NewForm("Commit Changes", "Commit", "Cancel", items, func(submitted bool) {
+		if submitted {
+			onCommit(strings.TrimSpace(messageEntry.Text))
+		}
+	}, window)
+	d.Resize(fyne.NewSize(520, 0))
+	d.Show()
+	window.Canvas().Focus(messageEntry)
+}
+
+// newGitBadge returns a small label showing a file's git status, or nil for
+// a clean file
+func newGitBadge(s git.Status) fyne.CanvasObject {
+	if s == git.Clean {
+		return nil
+	}
+	badge := widget.NewLabel(s.String())
+	badge.TextStyle = fyne.TextStyle{Italic: true}
+	switch s {
+	case git.Conflicted:
+		badge.Importance = widget.DangerImportance
+	case git.Modified:
+		badge.Importance = widget.WarningImportance
+	default:
+		badge.Importance = widget.LowImportance
+	}
+	return badge
+}
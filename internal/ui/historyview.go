@@ -0,0 +1,230 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/grantcarthew/cuecard/internal/diff"
+	"github.com/grantcarthew/cuecard/internal/history"
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+// Diff line backgrounds, translucent so they suit light and dark themes
+var (
+	removedColor = color.NRGBA{R: 220, G: 60, B: 60, A: 60}
+	addedColor   = color.NRGBA{R: 60, G: 180, B: 60, A: 60}
+)
+
+// Versions to compare the selected version with
+const (
+	compareCurrent  = "Current file"
+	comparePrevious = "Previous version"
+)
+
+// sourceLabels describe how each version came about
+var sourceLabels = map[history.Source]string{
+	history.Saved:    "Saved in Cuecard",
+	history.External: "Changed outside Cuecard",
+	history.Restored: "Restored from history",
+	history.Baseline: "Before the first save in Cuecard",
+}
+
+// ShowHistoryDialog shows a timeline of p's saved versions with a
+// side-by-side diff of the selected version against the current file or the
+// version before it. onRestore is called to restore the selected version.
+func ShowHistoryDialog(window fyne.Window, store *history.Store, p *prompt.Prompt, onRestore func(history.Version) error) {
+	var versions []history.Version
+	var rows []diff.Row
+	selected := -1
+
+	empty := widget.NewLabel("No history yet")
+	empty.Alignment = fyne.TextAlignCenter
+
+	timeline := widget.NewList(
+		func() int { return len(versions) },
+		func() fyne.CanvasObject {
+			when := widget.NewLabel("")
+			when.TextStyle = fyne.TextStyle{Bold: true}
+			detail := widget.NewLabel("")
+			detail.Importance = widget.LowImportance
+			return container.NewVBox(when, detail)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			v := versions[id]
+			box := obj.(*fyne.Container)
+			box.Objects[0].(*widget.Label).SetText(v.Time.Format("2006-01-02 15:04:05"))
+			box.Objects[1].(*widget.Label).SetText(fmt.Sprintf("%s, %d bytes", sourceLabels[v.Source], v.Size))
+		},
+	)
+
+	leftHeader := widget.NewLabel("")
+	leftHeader.TextStyle = fyne.TextStyle{Bold: true}
+	rightHeader := widget.NewLabel("")
+	rightHeader.TextStyle = fyne.TextStyle{Bold: true}
+	stats := widget.NewLabel("")
+	stats.Importance = widget.LowImportance
+
+	diffList := widget.NewList(
+		func() int { return len(rows) },
+		func() fyne.CanvasObject {
+			return container.NewGridWithColumns(2, newDiffCell(), newDiffCell())
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			row := rows[id]
+			grid := obj.(*fyne.Container)
+			setDiffCell(grid.Objects[0].(*fyne.Container), row.Left, row.HasLeft, row.Changed, removedColor)
+			setDiffCell(grid.Objects[1].(*fyne.Container), row.Right, row.HasRight, row.Changed, addedColor)
+		},
+	)
+
+	compareSelect := widget.NewSelect([]string{compareCurrent, comparePrevious}, nil)
+	compareSelect.SetSelected(compareCurrent)
+
+	restoreBtn := widget.NewButton("Restore This Version", nil)
+	restoreBtn.Importance = widget.HighImportance
+
+	// showDiff compares the selected version with the chosen version
+	showDiff := func() {
+		rows = nil
+		leftHeader.SetText("")
+		rightHeader.SetText("")
+		stats.SetText("")
+		defer diffList.Refresh()
+		if selected < 0 || selected >= len(versions) {
+			return
+		}
+
+		v := versions[selected]
+		content, err := store.Read(p.FilePath, v.ID)
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		old, cur := "", string(content)
+		oldName, curName := "Empty", "Version of "+v.Time.Format("2006-01-02 15:04")
+
+		if compareSelect.Selected == comparePrevious {
+			if selected+1 < len(versions) {
+				prev := versions[selected+1]
+				data, err := store.Read(p.FilePath, prev.ID)
+				if err != nil {
+					dialog.ShowError(err, window)
+					return
+				}
+				old, oldName = string(data), "Version of "+prev.Time.Format("2006-01-02 15:04")
+			}
+		} else {
+			data, err := os.ReadFile(p.FilePath)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("failed to read prompt: %w", err), window)
+				return
+			}
+			// Show the change restoring would make to the current file
+			old, oldName = string(data), compareCurrent
+		}
+
+		lines := diff.Lines(old, cur)
+		rows = diff.SideBySide(lines)
+		leftHeader.SetText(oldName)
+		rightHeader.SetText(curName)
+		inserted, deleted := diff.Stats(lines)
+		if inserted == 0 && deleted == 0 {
+			stats.SetText("No differences")
+		} else {
+			stats.SetText(fmt.Sprintf("%d lines added, %d removed", inserted, deleted))
+		}
+	}
+
+	reload := func() {
+		var err error
+		versions, err = store.List(p.FilePath)
+		if err != nil {
+			dialog.ShowError(err, window)
+		}
+		selected = -1
+		timeline.UnselectAll()
+		timeline.Refresh()
+		if len(versions) == 0 {
+			empty.Show()
+		} else {
+			empty.Hide()
+		}
+		restoreBtn.Disable()
+		showDiff()
+	}
+
+	timeline.OnSelected = func(id widget.ListItemID) {
+		selected = id
+		restoreBtn.Enable()
+		showDiff()
+	}
+	compareSelect.OnChanged = func(string) {
+		showDiff()
+	}
+
+	restoreBtn.OnTapped = func() {
+		if selected < 0 || selected >= len(versions) {
+			return
+		}
+		v := versions[selected]
+		dialog.ShowConfirm("Restore Version",
+			fmt.Sprintf("Replace %s with the version of %s?", p.FileName, v.Time.Format("2006-01-02 15:04:05")),
+			func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				if err := onRestore(v); err != nil {
+					dialog.ShowError(err, window)
+				}
+				reload()
+			}, window)
+	}
+
+	diffPane := container.NewBorder(
+		container.NewVBox(
+			container.NewHBox(widget.NewLabel("Compare with:"), compareSelect, layout.NewSpacer(), stats),
+			container.NewGridWithColumns(2, leftHeader, rightHeader),
+		),
+		container.NewHBox(layout.NewSpacer(), restoreBtn),
+		nil, nil,
+		diffList,
+	)
+
+	split := container.NewHSplit(container.NewStack(timeline, container.NewCenter(empty)), diffPane)
+	split.SetOffset(0.28)
+
+	reload()
+	d := dialog.NewCustom("History: "+p.Title, "Close", split, window)
+	d.Resize(fyne.NewSize(1000, 600))
+	d.Show()
+}
+
+// newDiffCell returns a diff line on a background showing whether it changed
+func newDiffCell() *fyne.Container {
+	label := widget.NewLabel("")
+	label.TextStyle = fyne.TextStyle{Monospace: true}
+	label.Truncation = fyne.TextTruncateEllipsis
+	return container.NewStack(canvas.NewRectangle(color.Transparent), label)
+}
+
+// setDiffCell shows text in a diff cell. Changed lines get the change color
+// and a missing side is left blank.
+func setDiffCell(cell *fyne.Container, text string, present, changed bool, changeColor color.Color) {
+	bg := cell.Objects[0].(*canvas.Rectangle)
+	label := cell.Objects[1].(*widget.Label)
+
+	bg.FillColor = color.Transparent
+	if present && changed {
+		bg.FillColor = changeColor
+	}
+	bg.Refresh()
+	label.SetText(text)
+}
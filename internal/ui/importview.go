@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/grantcarthew/cuecard/internal/importer"
+)
+
+// ShowImportPreviewDialog lists what importing will do with each incoming
+// prompt and lets the user change the action per prompt. format names the
+// source's format and skipped lists entries that are not prompts. onImport is called with the plan once the
+// user confirms.
+func ShowImportPreviewDialog(window fyne.Window, format string, plan *importer.Plan, skipped []string, onImport func(*importer.Plan)) {
+	importBtn := widget.NewButton("Import", nil)
+	importBtn.Importance = widget.HighImportance
+	summary := widget.NewLabel("")
+
+	update := func() {
+		writes := 0
+		for _, item := range plan.Items {
+			if item.Action != importer.Skip {
+				writes++
+			}
+		}
+		summary.SetText(fmt.Sprintf("%d new, %d changed, %d title clashes, %d identical; %d to import",
+			plan.Count(importer.New), plan.Count(importer.Changed),
+			plan.Count(importer.TitleClash), plan.Count(importer.Identical), writes))
+		if writes == 0 {
+			importBtn.Disable()
+		} else {
+			importBtn.Enable()
+		}
+	}
+
+	formatLabel := widget.NewLabel("Format: " + format)
+	formatLabel.Importance = widget.LowImportance
+	header := container.NewVBox(formatLabel, container.NewGridWithColumns(4,
+		boldLabel("Title"), boldLabel("Source"), boldLabel("Status"), boldLabel("Action")))
+
+	rows := container.NewVBox()
+	for _, item := range plan.Items {
+		actions := item.Actions()
+		names := make([]string, len(actions))
+		for i, a := range actions {
+			names[i] = a.String()
+		}
+		actionSelect := widget.NewSelect(names, nil)
+		actionSelect.SetSelected(item.Action.String())
+		actionSelect.OnChanged = func(name string) {
+			for _, a := range actions {
+				if a.String() == name {
+					item.SetAction(a)
+				}
+			}
+			update()
+		}
+
+		title := widget.NewLabel(item.Prompt.Title)
+		title.Truncation = fyne.TextTruncateEllipsis
+		source := widget.NewLabel(item.Source)
+		source.Truncation = fyne.TextTruncateEllipsis
+		source.Importance = widget.LowImportance
+		status := widget.NewLabel(item.Status.String())
+		switch item.Status {
+		case importer.TitleClash:
+			status.Importance = widget.WarningImportance
+		case importer.Changed:
+			status.Importance = widget.HighImportance
+		case importer.Identical:
+			status.Importance = widget.LowImportance
+		}
+		rows.Add(container.NewGridWithColumns(4, title, source, status, actionSelect))
+	}
+
+	var bottom []fyne.CanvasObject
+	if len(skipped) > 0 {
+		note := widget.NewLabel(fmt.Sprintf("Skipped: %s", strings.Join(skipped, ", ")))
+		note.Wrapping = fyne.TextWrapWord
+		note.Importance = widget.LowImportance
+		bottom = append(bottom, note)
+	}
+	bottom = append(bottom, summary)
+
+	var body fyne.CanvasObject = container.NewVScroll(rows)
+	if len(plan.Items) == 0 {
+		body = container.NewCenter(widget.NewLabel("No prompts found to import"))
+	}
+	content := container.NewBorder(header, container.NewVBox(bottom...), nil, nil, body)
+
+	var d *dialog.CustomDialog
+	importBtn.OnTapped = func() {
+		d.Hide()
+		onImport(plan)
+	}
+	cancelBtn := widget.NewButton("Cancel", func() { d.Hide() })
+
+	update()
+	d = dialog.NewCustomWithoutButtons("Import Preview", content, window)
+	d.SetButtons([]fyne.CanvasObject{cancelBtn, importBtn})
+	d.Resize(fyne.NewSize(820, 520))
+	d.Show()
+}
+
+// boldLabel returns a label in bold, for column headers
+func boldLabel(text string) *widget.Label {
+	label := widget.NewLabel(text)
+	label.TextStyle = fyne.TextStyle{Bold: true}
+	return label
+}
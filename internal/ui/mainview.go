@@ -1,30 +1,83 @@
 package ui
 
 import (
+	"errors"
+	"slices"
+	"strings"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/grantcarthew/cuecard/internal/bulk"
+	"github.com/grantcarthew/cuecard/internal/config"
+	"github.com/grantcarthew/cuecard/internal/drop"
 	"github.com/grantcarthew/cuecard/internal/prompt"
+	"github.com/grantcarthew/cuecard/internal/state"
+	"github.com/grantcarthew/cuecard/internal/watcher"
 )
 
+// noProfileLabel is shown in profile switchers for the base config
+const noProfileLabel = config.BaseProfileName
+
 // MainView is the main content view
 type MainView struct {
-	app           *App
-	container     *fyne.Container
-	searchEntry   *widget.Entry
-	cardsScroll   *container.Scroll
-	cardsContent  *fyne.Container
-	compactMode   bool
-	listView      bool
-	alwaysOnTop   bool
-	currentFilter string
+	app            *App
+	prompts        []*prompt.Prompt
+	container      *fyne.Container
+	searchEntry    *widget.Entry
+	profileSelect  *widget.Select
+	compactToggle  *widget.Check
+	listToggle     *widget.Check
+	sortSelect     *widget.Select
+	sidebarToggle  *widget.Check
+	sidebar        *container.Scroll
+	sidebarContent *fyne.Container
+	cardsScroll    *container.Scroll
+	cardsContent   *fyne.Container
+	groupHeaders   []groupHeader
+	sections       []section
+	selection      bulk.Selection
+	visible        []string // paths of the prompts shown, in order
+	bulkBar        *fyne.Container
+	selectedLabel  *widget.Label
+	statusLabel    *widget.Label
+	watchStatus    string
+	configStatus   string
+	compactMode    bool
+	listView       bool
+	sortMode       prompt.SortMode
+	tagFilter      prompt.TagFilter
+	alwaysOnTop    bool
+	currentFilter  string
+}
+
+// groupHeader is a group's header in the cards, for dropping onto
+type groupHeader struct {
+	group  string
+	object fyne.CanvasObject
+}
+
+// section is a run of cards that can be reordered by dragging
+type section struct {
+	prompts []*prompt.Prompt
+	cards   []fyne.CanvasObject
+}
+
+// sortLabels names the sort modes in the sort menu
+var sortLabels = map[prompt.SortMode]string{
+	prompt.SortAlphabetical: "Alphabetical",
+	prompt.SortManual:       "Manual",
+	prompt.SortMostUsed:     "Most Used",
+	prompt.SortRecent:       "Recently Modified",
 }
 
 // NewMainView creates a new main view
 func NewMainView(app *App) *MainView {
 	mv := &MainView{
-		app: app,
+		app:      app,
+		prompts:  app.GetPrompts(),
+		sortMode: prompt.SortAlphabetical,
 	}
 	mv.build()
 	return mv
@@ -37,29 +90,69 @@ func (mv *MainView) build() {
 	mv.searchEntry.OnChanged = mv.onSearch
 
 	// View toggles
-	compactToggle := widget.NewCheck("Compact", func(checked bool) {
+	mv.compactToggle = widget.NewCheck("Compact", func(checked bool) {
 		mv.compactMode = checked
 		mv.rebuildCards()
 	})
 
-	listToggle := widget.NewCheck("List", func(checked bool) {
+	mv.listToggle = widget.NewCheck("List", func(checked bool) {
 		mv.listView = checked
 		mv.rebuildCards()
 	})
 
+	// Sort menu
+	var options []string
+	for _, mode := range prompt.SortModes {
+		options = append(options, sortLabels[mode])
+	}
+	mv.sortSelect = widget.NewSelect(options, func(selected string) {
+		for mode, label := range sortLabels {
+			if label == selected {
+				mv.sortMode = mode
+			}
+		}
+		mv.rebuildCards()
+	})
+	mv.sortSelect.Selected = sortLabels[mv.sortMode]
+
+	mv.sidebarToggle = widget.NewCheck("Tags", func(checked bool) {
+		if checked {
+			mv.sidebar.Show()
+		} else {
+			mv.sidebar.Hide()
+		}
+		mv.container.Refresh()
+	})
+
 	topToggle := widget.NewCheck("Always on Top", func(checked bool) {
 		mv.alwaysOnTop = checked
 		// Note: Fyne doesn't have direct always-on-top support
 		// This would require platform-specific code
 	})
 
+	// Profile switcher, shown when the config defines profiles
+	mv.profileSelect = widget.NewSelect(nil, func(selected string) {
+		name := selected
+		if name == noProfileLabel {
+			name = ""
+		}
+		mv.app.SwitchProfile(name)
+	})
+	mv.UpdateProfiles()
+
 	toolbar := container.NewBorder(
 		nil, nil,
 		nil,
-		container.NewHBox(compactToggle, listToggle, topToggle),
+		container.NewHBox(mv.profileSelect, mv.sortSelect, mv.sidebarToggle, mv.compactToggle, mv.listToggle, topToggle),
 		mv.searchEntry,
 	)
 
+	// Actions on the selected prompts
+	mv.bulkBar = mv.newBulkBar()
+
+	// Tag and group browser
+	mv.sidebar = mv.newSidebar()
+
 	// Cards container
 	mv.cardsContent = container.NewVBox()
 	mv.cardsScroll = container.NewVScroll(mv.cardsContent)
@@ -67,10 +160,17 @@ func (mv *MainView) build() {
 	// Build the cards
 	mv.rebuildCards()
 
+	// Status line for watcher problems, hidden while all is well
+	mv.statusLabel = widget.NewLabel("")
+	mv.statusLabel.Importance = widget.WarningImportance
+	mv.statusLabel.Truncation = fyne.TextTruncateEllipsis
+	mv.statusLabel.Hide()
+
 	// Main layout
 	mv.container = container.NewBorder(
-		toolbar,
-		nil, nil, nil,
+		container.NewVBox(toolbar, mv.bulkBar),
+		mv.statusLabel,
+		mv.sidebar, nil,
 		mv.cardsScroll,
 	)
 }
@@ -82,33 +182,41 @@ func (mv *MainView) onSearch(query string) {
 
 func (mv *MainView) rebuildCards() {
 	mv.cardsContent.RemoveAll()
+	mv.groupHeaders = nil
+	mv.sections = nil
+	mv.visible = nil
 
-	prompts := mv.app.GetPrompts()
+	prompts := mv.prompts
 
-	// Apply filter if any
+	// Apply filter if any, and the tags and groups picked in the sidebar
 	if mv.currentFilter != "" {
 		prompts = prompt.Filter(prompts, mv.currentFilter)
 	}
+	prompts = mv.tagFilter.Apply(prompts)
 
-	// Group prompts
-	favorites, groups, ungrouped := prompt.GroupPrompts(prompts)
+	// Group and sort prompts. Cards can be dragged into a new order within
+	// their group, but not while filtering, which hides some of the group.
+	ordering := mv.ordering()
+	favorites, groups, ungrouped := prompt.GroupPromptsOrdered(prompts, ordering)
+	reorderable := mv.currentFilter == "" && !mv.tagFilter.Active()
 
 	// Add favorites section
 	if len(favorites) > 0 {
 		header := NewGroupHeader("Favorites", true)
 		mv.cardsContent.Add(header)
 
-		cardsContainer := mv.createCardsContainer(favorites)
+		cardsContainer := mv.createCardsContainer(favorites, false)
 		mv.cardsContent.Add(cardsContainer)
 	}
 
 	// Add groups
-	groupNames := prompt.SortedGroupNames(groups)
+	groupNames := ordering.GroupNames(groups)
 	for _, name := range groupNames {
 		header := NewGroupHeader(name, false)
 		mv.cardsContent.Add(header)
+		mv.groupHeaders = append(mv.groupHeaders, groupHeader{group: name, object: header})
 
-		cardsContainer := mv.createCardsContainer(groups[name])
+		cardsContainer := mv.createCardsContainer(groups[name], reorderable)
 		mv.cardsContent.Add(cardsContainer)
 	}
 
@@ -119,43 +227,175 @@ func (mv *MainView) rebuildCards() {
 			mv.cardsContent.Add(widget.NewSeparator())
 		}
 
-		cardsContainer := mv.createCardsContainer(ungrouped)
+		cardsContainer := mv.createCardsContainer(ungrouped, reorderable)
 		mv.cardsContent.Add(cardsContainer)
 	}
 
 	mv.cardsContent.Refresh()
+	mv.updateBulkBar()
+	mv.updateSidebar()
 }
 
-func (mv *MainView) createCardsContainer(prompts []*prompt.Prompt) fyne.CanvasObject {
-	if mv.listView {
-		return mv.createListView(prompts)
+// GroupAt returns the group whose header is at pos in the window, if any
+func (mv *MainView) GroupAt(pos fyne.Position) (string, bool) {
+	headers := make([]drop.Header, len(mv.groupHeaders))
+	for i, h := range mv.groupHeaders {
+		headers[i] = drop.Header{Group: h.group, Rect: windowRect(h.object)}
 	}
-	return mv.createGridView(prompts)
+	return drop.GroupAt(headers, windowRect(mv.cardsScroll), pos.X, pos.Y)
 }
 
-func (mv *MainView) createGridView(prompts []*prompt.Prompt) fyne.CanvasObject {
-	cardSize := fyne.NewSize(250, 120)
+// windowRect returns where obj is drawn in the window
+func windowRect(obj fyne.CanvasObject) drop.Rect {
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(obj)
+	size := obj.Size()
+	return drop.Rect{X: pos.X, Y: pos.Y, Width: size.Width, Height: size.Height}
+}
+
+// createCardsContainer lays out cards for prompts. A reorderable section's
+// cards can be dragged into a new order.
+func (mv *MainView) createCardsContainer(prompts []*prompt.Prompt, reorderable bool) fyne.CanvasObject {
+	cards := make([]fyne.CanvasObject, len(prompts))
+	for i, p := range prompts {
+		mv.visible = append(mv.visible, p.FilePath)
+		if mv.listView {
+			cards[i] = NewPromptListItem(p, mv.app, mv.IsSelected(p))
+		} else {
+			cards[i] = NewPromptCard(p, mv.app, mv.compactMode, mv.IsSelected(p), mv.tagFilter)
+		}
+	}
+	if reorderable {
+		mv.sections = append(mv.sections, section{prompts: prompts, cards: cards})
+	}
+
+	if mv.listView {
+		return container.NewVBox(cards...)
+	}
+	cardSize := fyne.NewSize(260, 170)
 	if mv.compactMode {
 		cardSize = fyne.NewSize(200, 80)
 	}
+	return container.NewGridWrap(cardSize, cards...)
+}
+
+// ordering returns how prompts are sorted in the current sort mode
+func (mv *MainView) ordering() prompt.Ordering {
+	return prompt.Ordering{Mode: mv.sortMode, Uses: mv.app.state.Usage}
+}
 
-	var cards []fyne.CanvasObject
-	for _, p := range prompts {
-		card := NewPromptCard(p, mv.app, mv.compactMode)
-		cards = append(cards, card)
+// SetSortMode changes the sort mode, rebuilding the cards
+func (mv *MainView) SetSortMode(mode prompt.SortMode) {
+	label, ok := sortLabels[mode]
+	if !ok {
+		label = sortLabels[prompt.SortAlphabetical]
 	}
+	mv.sortSelect.SetSelected(label)
+}
 
-	grid := container.NewGridWrap(cardSize, cards...)
-	return grid
+// DropCard moves p's card to wherever it was dragged to in its group, at
+// pos in the window. Cards dropped outside their group are left alone.
+func (mv *MainView) DropCard(p *prompt.Prompt, pos fyne.Position) {
+	for _, sec := range mv.sections {
+		from := slices.Index(sec.prompts, p)
+		if from < 0 {
+			continue
+		}
+		rects := make([]drop.Rect, len(sec.cards))
+		for i, card := range sec.cards {
+			rects[i] = windowRect(card)
+		}
+		to := drop.CardAt(rects, windowRect(mv.cardsScroll), pos.X, pos.Y)
+		if to < 0 || to == from {
+			return
+		}
+		mv.app.ReorderPrompts(prompt.Reorder(sec.prompts, from, to))
+		return
+	}
+}
+
+// ViewMode returns the current view toggles, sort mode and sidebar
+func (mv *MainView) ViewMode() state.View {
+	return state.View{
+		Compact: mv.compactMode,
+		List:    mv.listView,
+		Sort:    string(mv.sortMode),
+		Sidebar: mv.sidebarToggle.Checked,
+	}
 }
 
-func (mv *MainView) createListView(prompts []*prompt.Prompt) fyne.CanvasObject {
-	var items []fyne.CanvasObject
-	for _, p := range prompts {
-		item := NewPromptListItem(p, mv.app)
-		items = append(items, item)
+// SetViewMode sets the view toggles, sort mode and sidebar, rebuilding the
+// cards
+func (mv *MainView) SetViewMode(v state.View) {
+	mv.compactToggle.SetChecked(v.Compact)
+	mv.listToggle.SetChecked(v.List)
+	mv.SetSortMode(prompt.SortMode(v.Sort))
+	mv.SetSidebarVisible(v.Sidebar)
+}
+
+// FocusSearch moves keyboard focus to the search box
+func (mv *MainView) FocusSearch() {
+	mv.app.window.Canvas().Focus(mv.searchEntry)
+}
+
+// UpdateProfiles refreshes the profile switcher from the app's config. It
+// must be called on the main thread.
+func (mv *MainView) UpdateProfiles() {
+	names := mv.app.ProfileNames()
+	if len(names) == 0 {
+		mv.profileSelect.Hide()
+		return
+	}
+
+	mv.profileSelect.Options = append([]string{noProfileLabel}, names...)
+	selected := mv.app.ActiveProfile()
+	if selected == "" {
+		selected = noProfileLabel
+	}
+	// Set directly to avoid triggering a switch back to the same profile
+	mv.profileSelect.Selected = selected
+	mv.profileSelect.Refresh()
+	mv.profileSelect.Show()
+}
+
+// SetWatchStatus shows or clears the watcher status line. It must be called
+// on the main thread.
+func (mv *MainView) SetWatchStatus(status watcher.Status) {
+	switch {
+	case status.State == watcher.StatePaused && errors.Is(status.Err, watcher.ErrDirRemoved):
+		mv.watchStatus = "Watching paused: prompts directory is missing. Waiting for it to return..."
+	case status.State == watcher.StatePaused:
+		mv.watchStatus = "Watching paused: " + status.Err.Error()
+	case status.Err != nil:
+		mv.watchStatus = "Watch error: " + status.Err.Error()
+	default:
+		mv.watchStatus = ""
+	}
+	mv.updateStatus()
+}
+
+// SetConfigError shows a config reload error, or clears it when err is nil.
+// It must be called on the main thread.
+func (mv *MainView) SetConfigError(err error) {
+	mv.configStatus = ""
+	if err != nil {
+		mv.configStatus = "Config not applied, using last good config: " + err.Error()
+	}
+	mv.updateStatus()
+}
+
+func (mv *MainView) updateStatus() {
+	var lines []string
+	for _, msg := range []string{mv.configStatus, mv.watchStatus} {
+		if msg != "" {
+			lines = append(lines, msg)
+		}
+	}
+	if len(lines) == 0 {
+		mv.statusLabel.Hide()
+		return
 	}
-	return container.NewVBox(items...)
+	mv.statusLabel.SetText(strings.Join(lines, "\n"))
+	mv.statusLabel.Show()
 }
 
 // Container returns the main container
@@ -163,7 +403,9 @@ func (mv *MainView) Container() fyne.CanvasObject {
 	return mv.container
 }
 
-// Refresh rebuilds the view with new prompts
+// Refresh rebuilds the view with new prompts. It must be called on the
+// main thread.
 func (mv *MainView) Refresh(prompts []*prompt.Prompt) {
+	mv.prompts = prompts
 	mv.rebuildCards()
 }
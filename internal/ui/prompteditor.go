@@ -0,0 +1,527 @@
+package ui
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+// sampleVariables need a sample value in the preview because they are
+// normally supplied when the prompt is copied
+var sampleVariables = []string{"INPUT", "CLIPBOARD", "FILE"}
+
+// PromptEditor edits a prompt's frontmatter and body side by side with a
+// live preview of the rendered markdown and the substituted output
+type PromptEditor struct {
+	window    fyne.Window
+	variables map[string]string // custom variables from the config
+
+	titleEntry     *widget.Entry
+	descEntry      *widget.Entry
+	groupEntry     *widget.Entry
+	tagsEntry      *widget.Entry
+	aliasEntry     *widget.Entry
+	inputSelect    *widget.Select
+	inputHintEntry *widget.Entry
+	favoriteCheck  *widget.Check
+	bodyEntry      *widget.Entry
+	order          int // kept as loaded, since it is set by dragging cards
+
+	markdown      *widget.RichText
+	output        *widget.Label
+	samplesForm   *widget.Form
+	sampleEntries map[string]*widget.Entry
+	sampleNames   []string
+	validation    *widget.Label
+	completion    *widget.PopUpMenu
+	loading       bool
+
+	content fyne.CanvasObject
+}
+
+// NewPromptEditor creates an editor populated from p. The custom variables
+// are treated as known and substituted in the preview.
+func NewPromptEditor(window fyne.Window, p *prompt.Prompt, variables map[string]string) *PromptEditor {
+	e := &PromptEditor{
+		window:        window,
+		variables:     variables,
+		sampleEntries: make(map[string]*widget.Entry),
+	}
+	e.build()
+	e.SetPrompt(p)
+	return e
+}
+
+// SetPrompt replaces the editor's fields with p's
+func (e *PromptEditor) SetPrompt(p *prompt.Prompt) {
+	// Refresh once at the end rather than after every field
+	e.loading = true
+	e.titleEntry.SetText(p.Title)
+	e.descEntry.SetText(p.Description)
+	e.groupEntry.SetText(p.Group)
+	e.tagsEntry.SetText(strings.Join(p.Tags, ", "))
+	e.aliasEntry.SetText(p.Alias)
+	switch p.Input {
+	case "optional":
+		e.inputSelect.SetSelected("Optional")
+	case "required":
+		e.inputSelect.SetSelected("Required")
+	default:
+		e.inputSelect.SetSelected("None")
+	}
+	e.inputHintEntry.SetText(p.InputHint)
+	e.favoriteCheck.SetChecked(p.Favorite)
+	e.order = p.Order
+	e.bodyEntry.SetText(p.Content)
+	e.loading = false
+
+	e.update()
+}
+
+// Content returns the editor's content
+func (e *PromptEditor) Content() fyne.CanvasObject {
+	return e.content
+}
+
+func (e *PromptEditor) build() {
+	onChanged := func(string) { e.update() }
+
+	e.titleEntry = widget.NewEntry()
+	e.titleEntry.SetPlaceHolder("Prompt title")
+	e.titleEntry.OnChanged = onChanged
+
+	e.descEntry = widget.NewEntry()
+	e.descEntry.SetPlaceHolder("Brief description (optional)")
+	e.descEntry.OnChanged = onChanged
+
+	e.groupEntry = widget.NewEntry()
+	e.groupEntry.SetPlaceHolder("Group name (optional)")
+
+	e.tagsEntry = widget.NewEntry()
+	e.tagsEntry.SetPlaceHolder("tag1, tag2, tag3 (optional)")
+
+	e.aliasEntry = widget.NewEntry()
+	e.aliasEntry.SetPlaceHolder("Short name (optional)")
+
+	e.inputSelect = widget.NewSelect([]string{"None", "Optional", "Required"}, nil)
+	e.inputSelect.OnChanged = func(string) { e.update() }
+
+	e.inputHintEntry = widget.NewEntry()
+	e.inputHintEntry.SetPlaceHolder("Input field hint (optional)")
+
+	e.favoriteCheck = widget.NewCheck("Pin to top", nil)
+
+	// Two columns keep the form short so the body gets the space
+	left := widget.NewForm(
+		widget.NewFormItem("Title", e.titleEntry),
+		widget.NewFormItem("Description", e.descEntry),
+		widget.NewFormItem("Group", e.groupEntry),
+		widget.NewFormItem("Tags", e.tagsEntry),
+	)
+	right := widget.NewForm(
+		widget.NewFormItem("Alias", e.aliasEntry),
+		widget.NewFormItem("Input", e.inputSelect),
+		widget.NewFormItem("Input Hint", e.inputHintEntry),
+		widget.NewFormItem("Favorite", e.favoriteCheck),
+	)
+	frontmatter := container.NewGridWithColumns(2, left, right)
+
+	e.bodyEntry = widget.NewMultiLineEntry()
+	e.bodyEntry.Wrapping = fyne.TextWrapWord
+	e.bodyEntry.TextStyle = fyne.TextStyle{Monospace: true}
+	e.bodyEntry.SetPlaceHolder("Prompt content...\n\nType ${ to insert a variable")
+	e.bodyEntry.OnChanged = func(string) {
+		e.update()
+		e.showCompletion()
+	}
+
+	e.markdown = widget.NewRichText()
+	e.markdown.Wrapping = fyne.TextWrapWord
+
+	e.output = widget.NewLabel("")
+	e.output.Wrapping = fyne.TextWrapWord
+	e.output.TextStyle = fyne.TextStyle{Monospace: true}
+
+	e.samplesForm = widget.NewForm()
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem("Markdown", container.NewVScroll(e.markdown)),
+		container.NewTabItem("Output", container.NewBorder(
+			e.samplesForm, nil, nil, nil,
+			container.NewVScroll(e.output),
+		)),
+	)
+
+	bodyLabel := widget.NewLabel("Content")
+	bodyLabel.TextStyle = fyne.TextStyle{Bold: true}
+	previewLabel := widget.NewLabel("Preview")
+	previewLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	split := container.NewHSplit(
+		container.NewBorder(bodyLabel, nil, nil, nil, e.bodyEntry),
+		container.NewBorder(previewLabel, nil, nil, nil, tabs),
+	)
+	split.SetOffset(0.5)
+
+	e.validation = widget.NewLabel("")
+	e.validation.Wrapping = fyne.TextWrapWord
+	e.validation.Hide()
+
+	e.content = container.NewBorder(frontmatter, e.validation, nil, nil, split)
+}
+
+// Prompt returns a new prompt built from the editor's fields
+func (e *PromptEditor) Prompt() *prompt.Prompt {
+	var tags []string
+	for _, t := range strings.Split(e.tagsEntry.Text, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+
+	input := ""
+	switch e.inputSelect.Selected {
+	case "Optional":
+		input = "optional"
+	case "Required":
+		input = "required"
+	}
+
+	return &prompt.Prompt{
+		Title:       strings.TrimSpace(e.titleEntry.Text),
+		Description: strings.TrimSpace(e.descEntry.Text),
+		Group:       strings.TrimSpace(e.groupEntry.Text),
+		Tags:        tags,
+		Alias:       strings.TrimSpace(e.aliasEntry.Text),
+		Input:       input,
+		InputHint:   e.inputHintEntry.Text,
+		Favorite:    e.favoriteCheck.Checked,
+		Order:       e.order,
+		Content:     e.bodyEntry.Text,
+	}
+}
+
+// Validate returns an error if the prompt has validation errors. Warnings
+// are shown but don't block saving.
+func (e *PromptEditor) Validate() error {
+	for _, r := range e.Prompt().Validate() {
+		if r.Level == prompt.ValidationError {
+			return fmt.Errorf("%s", r.Message)
+		}
+	}
+	return nil
+}
+
+// update refreshes the preview and validation after an edit
+func (e *PromptEditor) update() {
+	if e.loading {
+		return
+	}
+	body := e.bodyEntry.Text
+
+	e.markdown.ParseMarkdown(body)
+	e.markdown.Segments = e.highlightVariables(e.markdown.Segments)
+	e.markdown.Refresh()
+	e.updateSamples(body)
+	e.output.SetText(e.substitute(body))
+	e.updateValidation()
+}
+
+// knownVariables returns the builtin and configured variable names
+func (e *PromptEditor) knownVariables() []string {
+	return append(slices.Clone(prompt.BuiltinVariables), slices.Collect(maps.Keys(e.variables))...)
+}
+
+// highlightVariables colours the variables in the rendered markdown, known
+// ones in the primary colour and unknown ones in the error colour. The body
+// entry can't style its text, so this is only shown in the preview.
+func (e *PromptEditor) highlightVariables(segments []widget.RichTextSegment) []widget.RichTextSegment {
+	known := e.knownVariables()
+	var out []widget.RichTextSegment
+	for _, seg := range segments {
+		switch s := seg.(type) {
+		case *widget.TextSegment:
+			out = append(out, highlightText(s, known)...)
+		case *widget.ParagraphSegment:
+			s.Texts = e.highlightVariables(s.Texts)
+			out = append(out, s)
+		case *widget.ListSegment:
+			s.Items = e.highlightVariables(s.Items)
+			out = append(out, s)
+		default:
+			out = append(out, seg)
+		}
+	}
+	return out
+}
+
+// highlightText splits seg around its variables. All but the last piece are
+// inline, so a heading or code block still renders as one block.
+func highlightText(seg *widget.TextSegment, known []string) []widget.RichTextSegment {
+	spans := prompt.VariableSpans(seg.Text)
+	if len(spans) == 0 {
+		return []widget.RichTextSegment{seg}
+	}
+
+	var pieces []*widget.TextSegment
+	last := 0
+	for _, span := range spans {
+		if span.Start > last {
+			pieces = append(pieces, &widget.TextSegment{Text: seg.Text[last:span.Start], Style: seg.Style})
+		}
+		style := seg.Style
+		style.TextStyle.Bold = true
+		if slices.Contains(known, span.Name) {
+			style.ColorName = theme.ColorNamePrimary
+		} else {
+			style.ColorName = theme.ColorNameError
+		}
+		pieces = append(pieces, &widget.TextSegment{Text: seg.Text[span.Start:span.End], Style: style})
+		last = span.End
+	}
+	if last < len(seg.Text) {
+		pieces = append(pieces, &widget.TextSegment{Text: seg.Text[last:], Style: seg.Style})
+	}
+
+	out := make([]widget.RichTextSegment, len(pieces))
+	for i, piece := range pieces {
+		if i < len(pieces)-1 {
+			piece.Style.Inline = true
+		}
+		out[i] = piece
+	}
+	return out
+}
+
+// updateSamples shows a sample input for each variable in the body that is
+// only known when the prompt is copied
+func (e *PromptEditor) updateSamples(body string) {
+	var names []string
+	for _, name := range prompt.ExtractVariables(body) {
+		_, configured := e.variables[name]
+		needsSample := slices.Contains(sampleVariables, name) ||
+			(!configured && !slices.Contains(prompt.BuiltinVariables, name))
+		if needsSample {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	if slices.Equal(names, e.sampleNames) {
+		return
+	}
+	e.sampleNames = names
+
+	e.samplesForm.Items = nil
+	for _, name := range names {
+		entry, ok := e.sampleEntries[name]
+		if !ok {
+			entry = widget.NewEntry()
+			entry.SetPlaceHolder("Sample " + strings.ToLower(name))
+			entry.OnChanged = func(string) {
+				e.output.SetText(e.substitute(e.bodyEntry.Text))
+			}
+			e.sampleEntries[name] = entry
+		}
+		e.samplesForm.Append(name, entry)
+	}
+	e.samplesForm.Refresh()
+}
+
+// substitute fills the body's variables with the sample inputs and the
+// configured values
+func (e *PromptEditor) substitute(body string) string {
+	sample := func(name string) string {
+		if entry, ok := e.sampleEntries[name]; ok {
+			return entry.Text
+		}
+		return ""
+	}
+
+	resolver := &prompt.VariableResolver{
+		Input:     sample("INPUT"),
+		Clipboard: sample("CLIPBOARD"),
+		FileSelector: func() string {
+			return sample("FILE")
+		},
+	}
+	result := prompt.Substitute(body, resolver)
+	result = prompt.SubstituteWithValues(result, e.variables)
+
+	samples := make(map[string]string)
+	for _, name := range e.sampleNames {
+		samples[name] = sample(name)
+	}
+	return prompt.SubstituteWithValues(result, samples)
+}
+
+// updateValidation shows the prompt's validation results and any unknown
+// variables
+func (e *PromptEditor) updateValidation() {
+	var errs, warnings []string
+	for _, r := range e.Prompt().Validate() {
+		if r.Level == prompt.ValidationError {
+			errs = append(errs, r.Message)
+		} else {
+			warnings = append(warnings, r.Message)
+		}
+	}
+	custom := slices.Collect(maps.Keys(e.variables))
+	for _, name := range prompt.ValidateVariables(e.bodyEntry.Text, custom...) {
+		warnings = append(warnings, "unknown variable ${"+name+"} will be copied as is")
+	}
+
+	if len(errs) == 0 && len(warnings) == 0 {
+		e.validation.Hide()
+		return
+	}
+
+	if len(errs) > 0 {
+		e.validation.Importance = widget.DangerImportance
+	} else {
+		e.validation.Importance = widget.WarningImportance
+	}
+	e.validation.SetText(strings.Join(append(errs, warnings...), "\n"))
+	e.validation.Show()
+}
+
+// showCompletion offers the known variables matching a partly typed ${NAME}
+// at the cursor
+func (e *PromptEditor) showCompletion() {
+	if e.loading {
+		return
+	}
+	if e.completion != nil {
+		e.completion.Hide()
+		e.completion = nil
+	}
+
+	text := e.bodyEntry.Text
+	offset := cursorOffset(text, e.bodyEntry.CursorRow, e.bodyEntry.CursorColumn)
+	prefix, start, ok := prompt.VariablePrefix(text, offset)
+	if !ok {
+		return
+	}
+	matches := prompt.CompleteVariable(prefix, e.knownVariables())
+	if len(matches) == 0 || (len(matches) == 1 && matches[0] == prefix && strings.HasPrefix(text[offset:], "}")) {
+		return
+	}
+
+	var items []*fyne.MenuItem
+	for _, name := range matches {
+		items = append(items, fyne.NewMenuItem("${"+name+"}", func() {
+			e.insertVariable(start, offset, name)
+		}))
+	}
+
+	c := e.window.Canvas()
+	e.completion = widget.NewPopUpMenu(fyne.NewMenu("", items...), c)
+
+	// Place the menu just below the cursor
+	charSize := fyne.MeasureText("M", theme.TextSize(), e.bodyEntry.TextStyle)
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(e.bodyEntry)
+	pos = pos.Add(fyne.NewPos(
+		theme.InnerPadding()+charSize.Width*float32(e.bodyEntry.CursorColumn),
+		theme.InnerPadding()+charSize.Height*float32(e.bodyEntry.CursorRow+1),
+	))
+	e.completion.ShowAtPosition(pos)
+}
+
+// insertVariable replaces text[start:end] with a complete variable reference
+// and moves the cursor after it
+func (e *PromptEditor) insertVariable(start, end int, name string) {
+	text := e.bodyEntry.Text
+	rest := text[end:]
+	// Drop the rest of a name being edited inside an existing reference
+	if i := strings.IndexFunc(rest, func(r rune) bool { return r != '_' && (r < 'A' || r > 'Z') }); i >= 0 && rest[i] == '}' {
+		rest = rest[i+1:]
+	}
+
+	ref := "${" + name + "}"
+	e.bodyEntry.SetText(text[:start] + ref + rest)
+
+	row, col := cursorPosition(e.bodyEntry.Text, start+len(ref))
+	e.bodyEntry.CursorRow = row
+	e.bodyEntry.CursorColumn = col
+	e.bodyEntry.Refresh()
+	e.window.Canvas().Focus(e.bodyEntry)
+}
+
+// cursorOffset converts an entry's cursor row and rune column to a byte
+// offset in text
+func cursorOffset(text string, row, col int) int {
+	offset := 0
+	lines := strings.SplitAfter(text, "\n")
+	for i := 0; i < row && i < len(lines); i++ {
+		offset += len(lines[i])
+	}
+	if row >= len(lines) {
+		return len(text)
+	}
+	line := strings.TrimSuffix(lines[row], "\n")
+	for i := 0; i < col && len(line) > 0; i++ {
+		_, size := utf8.DecodeRuneInString(line)
+		offset += size
+		line = line[size:]
+	}
+	return offset
+}
+
+// cursorPosition converts a byte offset in text to a cursor row and rune
+// column
+func cursorPosition(text string, offset int) (row, col int) {
+	before := text[:offset]
+	row = strings.Count(before, "\n")
+	if i := strings.LastIndex(before, "\n"); i >= 0 {
+		before = before[i+1:]
+	}
+	return row, utf8.RuneCountInString(before)
+}
+
+// editorDialog is a PromptEditor shown in a dialog
+type editorDialog struct {
+	*PromptEditor
+	dialog *dialog.CustomDialog
+}
+
+// Close hides the dialog
+func (d *editorDialog) Close() {
+	d.dialog.Hide()
+}
+
+// showPromptEditorDialog shows a PromptEditor in a dialog. save is called
+// once the prompt validates and closes the dialog when done; if it returns
+// an error the dialog stays open.
+func showPromptEditorDialog(window fyne.Window, title, confirm string, p *prompt.Prompt, variables map[string]string, save func(*editorDialog) error) {
+	ed := &editorDialog{PromptEditor: NewPromptEditor(window, p, variables)}
+
+	saveBtn := widget.NewButton(confirm, func() {
+		err := ed.Validate()
+		if err == nil {
+			err = save(ed)
+		}
+		if err != nil {
+			dialog.ShowError(err, window)
+		}
+	})
+	saveBtn.Importance = widget.HighImportance
+	cancelBtn := widget.NewButton("Cancel", func() {
+		ed.Close()
+	})
+
+	ed.dialog = dialog.NewCustomWithoutButtons(title, ed.Content(), window)
+	ed.dialog.SetButtons([]fyne.CanvasObject{cancelBtn, saveBtn})
+
+	windowSize := window.Canvas().Size()
+	ed.dialog.Resize(fyne.NewSize(windowSize.Width-40, windowSize.Height-40))
+	ed.dialog.Show()
+}
@@ -0,0 +1,189 @@
+package ui
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/grantcarthew/cuecard/internal/config"
+)
+
+// ShowSettingsDialog shows the settings editor for cfg. The edited copy is
+// validated and passed to onSave; cfg itself is not modified.
+func ShowSettingsDialog(window fyne.Window, cfg *config.Config, onSave func(*config.Config) error) {
+	promptsDirEntry := widget.NewEntry()
+	promptsDirEntry.SetText(cfg.PromptsDir)
+
+	browseBtn := widget.NewButton("Browse...", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			promptsDirEntry.SetText(uri.Path())
+		}, window)
+	})
+
+	groupFoldersCheck := widget.NewCheck("Keep each group in its own subfolder", nil)
+	groupFoldersCheck.SetChecked(cfg.GroupFolders)
+
+	historyCheck := widget.NewCheck("Also record changes made outside Cuecard", nil)
+	historyCheck.SetChecked(cfg.HistoryExternal)
+
+	editorEntry := widget.NewEntry()
+	editorEntry.SetText(cfg.Editor)
+	editorEntry.SetPlaceHolder("$VISUAL or $EDITOR, e.g. code --wait {file}")
+
+	terminalEntry := widget.NewEntry()
+	terminalEntry.SetText(cfg.Terminal)
+	terminalEntry.SetPlaceHolder("For terminal editors, e.g. alacritty -e")
+
+	themeSelect := widget.NewSelect([]string{"System", "Light", "Dark"}, nil)
+	switch cfg.Theme {
+	case "light":
+		themeSelect.SetSelected("Light")
+	case "dark":
+		themeSelect.SetSelected("Dark")
+	default:
+		themeSelect.SetSelected("System")
+	}
+
+	widthEntry := widget.NewEntry()
+	widthEntry.SetText(strconv.Itoa(cfg.Window.Width))
+	heightEntry := widget.NewEntry()
+	heightEntry.SetText(strconv.Itoa(cfg.Window.Height))
+
+	positionSelect := widget.NewSelect([]string{"Center", "Remember"}, nil)
+	if cfg.Window.Position == "remember" {
+		positionSelect.SetSelected("Remember")
+	} else {
+		positionSelect.SetSelected("Center")
+	}
+
+	hotkeyEntry := widget.NewEntry()
+	hotkeyEntry.SetText(cfg.Hotkey)
+	hotkeyEntry.SetPlaceHolder("e.g. Ctrl+Shift+Space")
+
+	// One NAME=value pair per line
+	variablesEntry := widget.NewMultiLineEntry()
+	variablesEntry.SetText(formatVariables(cfg.Variables))
+	variablesEntry.SetPlaceHolder("AUTHOR=Jane Doe")
+	variablesEntry.SetMinRowsVisible(4)
+
+	errorLabel := widget.NewLabel("")
+	errorLabel.Importance = widget.DangerImportance
+	errorLabel.Wrapping = fyne.TextWrapWord
+	errorLabel.Hide()
+
+	form := widget.NewForm(
+		widget.NewFormItem("Prompts Directory", container.NewBorder(nil, nil, nil, browseBtn, promptsDirEntry)),
+		widget.NewFormItem("Group Folders", groupFoldersCheck),
+		widget.NewFormItem("History", historyCheck),
+		widget.NewFormItem("Editor", editorEntry),
+		widget.NewFormItem("Terminal", terminalEntry),
+		widget.NewFormItem("Theme", themeSelect),
+		widget.NewFormItem("Window Width", widthEntry),
+		widget.NewFormItem("Window Height", heightEntry),
+		widget.NewFormItem("Window Position", positionSelect),
+		widget.NewFormItem("Hotkey", hotkeyEntry),
+		widget.NewFormItem("Variables", variablesEntry),
+	)
+
+	content := container.NewVBox(form, errorLabel)
+
+	var d *dialog.CustomDialog
+
+	save := func() {
+		updated, err := func() (*config.Config, error) {
+			updated := *cfg
+			updated.Profiles = maps.Clone(cfg.Profiles)
+			updated.PromptsDir = strings.TrimSpace(promptsDirEntry.Text)
+			updated.GroupFolders = groupFoldersCheck.Checked
+			updated.HistoryExternal = historyCheck.Checked
+			updated.Editor = strings.TrimSpace(editorEntry.Text)
+			updated.Terminal = strings.TrimSpace(terminalEntry.Text)
+			updated.Theme = strings.ToLower(themeSelect.Selected)
+			updated.Window.Position = strings.ToLower(positionSelect.Selected)
+			updated.Hotkey = strings.TrimSpace(hotkeyEntry.Text)
+
+			width, err := strconv.Atoi(strings.TrimSpace(widthEntry.Text))
+			if err != nil || width <= 0 {
+				return nil, fmt.Errorf("window width must be a positive number")
+			}
+			height, err := strconv.Atoi(strings.TrimSpace(heightEntry.Text))
+			if err != nil || height <= 0 {
+				return nil, fmt.Errorf("window height must be a positive number")
+			}
+			updated.Window.Width = width
+			updated.Window.Height = height
+
+			updated.Variables, err = parseVariables(variablesEntry.Text)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := updated.Validate(); err != nil {
+				return nil, err
+			}
+			return &updated, nil
+		}()
+		if err == nil {
+			err = onSave(updated)
+		}
+		if err != nil {
+			errorLabel.SetText(err.Error())
+			errorLabel.Show()
+			return
+		}
+		d.Hide()
+	}
+
+	saveBtn := widget.NewButton("Save", save)
+	saveBtn.Importance = widget.HighImportance
+	cancelBtn := widget.NewButton("Cancel", func() {
+		d.Hide()
+	})
+
+	// Buttons are handled here rather than by the dialog so that validation
+	// errors keep it open
+	d = dialog.NewCustomWithoutButtons("Settings", content, window)
+	d.SetButtons([]fyne.CanvasObject{cancelBtn, saveBtn})
+
+	windowSize := window.Canvas().Size()
+	d.Resize(fyne.NewSize(windowSize.Width*0.8, windowSize.Height*0.8))
+	d.Show()
+}
+
+// formatVariables lists variables as sorted NAME=value lines
+func formatVariables(vars map[string]string) string {
+	var lines []string
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		lines = append(lines, name+"="+vars[name])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseVariables reads NAME=value lines, skipping blank lines
+func parseVariables(text string) (map[string]string, error) {
+	vars := make(map[string]string)
+	for i, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("variables line %d: expected NAME=value", i+1)
+		}
+		vars[strings.TrimSpace(name)] = value
+	}
+	if len(vars) == 0 {
+		return nil, nil
+	}
+	return vars, nil
+}
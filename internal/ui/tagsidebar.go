@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/grantcarthew/cuecard/internal/bulk"
+	"github.com/grantcarthew/cuecard/internal/prompt"
+)
+
+// Labels for how the sidebar's tags combine
+const (
+	matchAnyLabel = "Any"
+	matchAllLabel = "All"
+)
+
+// newSidebar returns the tag and group browser. It is hidden until toggled
+// on from the toolbar.
+func (mv *MainView) newSidebar() *container.Scroll {
+	mv.sidebarContent = container.NewVBox()
+	scroll := container.NewVScroll(mv.sidebarContent)
+	scroll.SetMinSize(fyne.NewSize(200, 0))
+	scroll.Hide()
+	return scroll
+}
+
+// SetSidebarVisible shows or hides the tag and group browser
+func (mv *MainView) SetSidebarVisible(visible bool) {
+	mv.sidebarToggle.SetChecked(visible)
+}
+
+// updateSidebar lists the library's tags, groups and variables with their
+// counts. Clicking one toggles it in the filter.
+func (mv *MainView) updateSidebar() {
+	mv.sidebarContent.RemoveAll()
+
+	match := widget.NewRadioGroup([]string{matchAnyLabel, matchAllLabel}, func(selected string) {
+		if matchAll := selected == matchAllLabel; matchAll != mv.tagFilter.MatchAll {
+			mv.tagFilter.MatchAll = matchAll
+			mv.rebuildCards()
+		}
+	})
+	match.Horizontal = true
+	match.Required = true
+	match.Selected = matchAnyLabel
+	if mv.tagFilter.MatchAll {
+		match.Selected = matchAllLabel
+	}
+	mv.sidebarContent.Add(container.NewHBox(widget.NewLabel("Match"), match))
+
+	if mv.tagFilter.Active() {
+		mv.sidebarContent.Add(widget.NewButton("Clear Filter", func() {
+			mv.tagFilter = prompt.TagFilter{MatchAll: mv.tagFilter.MatchAll}
+			mv.rebuildCards()
+		}))
+	}
+
+	mv.sidebarContent.Add(sidebarHeading("Tags"))
+	tags := prompt.TagCounts(mv.prompts)
+	if len(tags) == 0 {
+		none := widget.NewLabel("No tags")
+		none.Importance = widget.LowImportance
+		mv.sidebarContent.Add(none)
+	}
+	for _, tag := range tags {
+		name := tag.Name
+		btn := sidebarButton(tag, mv.tagFilter.HasTag(name), func() {
+			mv.ToggleTagFilter(name)
+		})
+		rename := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), func() {
+			mv.app.renameTag(name)
+		})
+		rename.Importance = widget.LowImportance
+		mv.sidebarContent.Add(container.NewBorder(nil, nil, nil, rename, btn))
+	}
+
+	groups := prompt.GroupCounts(mv.prompts)
+	if len(groups) > 0 {
+		mv.sidebarContent.Add(sidebarHeading("Groups"))
+	}
+	for _, group := range groups {
+		name := group.Name
+		active := slices.Contains(mv.tagFilter.Groups, name)
+		mv.sidebarContent.Add(sidebarButton(group, active, func() {
+			mv.tagFilter.ToggleGroup(name)
+			mv.rebuildCards()
+		}))
+	}
+
+	variables := prompt.VariableCounts(mv.prompts)
+	if len(variables) > 0 {
+		mv.sidebarContent.Add(sidebarHeading("Variables"))
+	}
+	for _, v := range variables {
+		name := v.Name
+		mv.sidebarContent.Add(sidebarButton(v, mv.tagFilter.HasVariable(name), func() {
+			mv.ToggleVariableFilter(name)
+		}))
+	}
+
+	mv.sidebarContent.Refresh()
+}
+
+// ToggleTagFilter adds tag to the filter, or removes it if it is there
+func (mv *MainView) ToggleTagFilter(tag string) {
+	mv.tagFilter.ToggleTag(tag)
+	mv.rebuildCards()
+}
+
+// ToggleVariableFilter adds variable to the filter, or removes it if it is
+// there
+func (mv *MainView) ToggleVariableFilter(variable string) {
+	mv.tagFilter.ToggleVariable(variable)
+	mv.rebuildCards()
+}
+
+// sidebarHeading returns a bold section label for the sidebar
+func sidebarHeading(text string) fyne.CanvasObject {
+	label := widget.NewLabel(text)
+	label.TextStyle = fyne.TextStyle{Bold: true}
+	return label
+}
+
+// sidebarButton returns a button for a tag or group and its count,
+// highlighted while it is in the filter
+func sidebarButton(c prompt.Count, active bool, onTapped func()) *widget.Button {
+	btn := widget.NewButton(fmt.Sprintf("%s (%d)", c.Name, c.Count), onTapped)
+	btn.Alignment = widget.ButtonAlignLeading
+	btn.Importance = widget.LowImportance
+	if active {
+		btn.Importance = widget.HighImportance
+	}
+	return btn
+}
+
+// renameTag asks for a new name for tag and renames it across the library
+func (a *App) renameTag(tag string) {
+	ShowRenameTagDialog(a.window, tag, allTags(a.GetPrompts()), func(to string) {
+		a.RenameTag(tag, to)
+	})
+}
+
+// RenameTag renames the tag from to in every prompt tagged with it, as one
+// undoable action. Renaming to a tag already in use merges the two.
+func (a *App) RenameTag(from, to string) {
+	var tagged []*prompt.Prompt
+	for _, p := range a.GetPrompts() {
+		if p.HasTag(from) {
+			tagged = append(tagged, p)
+		}
+	}
+	a.mainView.tagFilter.RenameTag(from, to)
+	a.editPrompts(tagged, "Rename tag on %d prompts", bulk.RenameTag(from, to))
+}
+
+// ShowRenameTagDialog asks for a new name for tag. Picking one of the other
+// tags merges tag into it. onRename is called with the new name.
+func ShowRenameTagDialog(window fyne.Window, tag string, tags []string, onRename func(to string)) {
+	var others []string
+	for _, t := range tags {
+		if !strings.EqualFold(t, tag) {
+			others = append(others, t)
+		}
+	}
+	entry := widget.NewSelectEntry(others)
+	entry.SetText(tag)
+	hint := widget.NewLabel("Choose an existing tag to merge into it.")
+	hint.Importance = widget.LowImportance
+	items := []*widget.FormItem{
+		widget.NewFormItem("New Name", entry),
+		widget.NewFormItem("", hint),
+	}
+	d := dialog.NewForm(fmt.Sprintf("Rename Tag %q", tag), "Rename", "Cancel", items, func(ok bool) {
+		to := strings.TrimSpace(entry.Text)
+		if ok && to != "" && to != tag {
+			onRename(to)
+		}
+	}, window)
+	d.Resize(fyne.NewSize(400, d.MinSize().Height))
+	d.Show()
+}
@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/grantcarthew/cuecard/internal/trash"
+)
+
+// ShowTrashDialog lists trashed prompts so they can be restored or deleted
+// permanently. onRestored is called after a prompt is restored.
+func ShowTrashDialog(window fyne.Window, t *trash.Trash, onRestored func()) {
+	var items []trash.Item
+	selected := -1
+
+	empty := widget.NewLabel("The trash is empty")
+	empty.Alignment = fyne.TextAlignCenter
+
+	list := widget.NewList(
+		func() int { return len(items) },
+		func() fyne.CanvasObject {
+			name := widget.NewLabel("")
+			name.TextStyle = fyne.TextStyle{Bold: true}
+			detail := widget.NewLabel("")
+			detail.Importance = widget.LowImportance
+			detail.Truncation = fyne.TextTruncateEllipsis
+			return container.NewVBox(name, detail)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			item := items[id]
+			box := obj.(*fyne.Container)
+			box.Objects[0].(*widget.Label).SetText(item.Name())
+			box.Objects[1].(*widget.Label).SetText(fmt.Sprintf("Deleted %s from %s",
+				item.DeletedAt.Format("2006-01-02 15:04"), item.OriginalPath))
+		},
+	)
+
+	restoreBtn := widget.NewButton("Restore", nil)
+	deleteBtn := widget.NewButton("Delete Permanently", nil)
+	deleteBtn.Importance = widget.DangerImportance
+	emptyBtn := widget.NewButton("Empty Trash", nil)
+
+	reload := func() {
+		var err error
+		items, err = t.List()
+		if err != nil {
+			dialog.ShowError(err, window)
+		}
+		selected = -1
+		list.UnselectAll()
+		list.Refresh()
+
+		if len(items) == 0 {
+			empty.Show()
+			emptyBtn.Disable()
+		} else {
+			empty.Hide()
+			emptyBtn.Enable()
+		}
+		restoreBtn.Disable()
+		deleteBtn.Disable()
+	}
+
+	list.OnSelected = func(id widget.ListItemID) {
+		selected = id
+		restoreBtn.Enable()
+		deleteBtn.Enable()
+	}
+
+	restoreBtn.OnTapped = func() {
+		if selected < 0 || selected >= len(items) {
+			return
+		}
+		if _, err := t.Restore(items[selected].ID); err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		reload()
+		if onRestored != nil {
+			onRestored()
+		}
+	}
+
+	deleteBtn.OnTapped = func() {
+		if selected < 0 || selected >= len(items) {
+			return
+		}
+		item := items[selected]
+		dialog.ShowConfirm("Delete Permanently",
+			fmt.Sprintf("Permanently delete %q? This can't be undone.", item.Name()),
+			func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				if err := t.Delete(item.ID); err != nil {
+					dialog.ShowError(err, window)
+				}
+				reload()
+			}, window)
+	}
+
+	emptyBtn.OnTapped = func() {
+		dialog.ShowConfirm("Empty Trash",
+			fmt.Sprintf("Permanently delete all %d prompts in the trash? This can't be undone.", len(items)),
+			func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				if err := t.Empty(); err != nil {
+					dialog.ShowError(err, window)
+				}
+				reload()
+			}, window)
+	}
+
+	note := widget.NewLabel(fmt.Sprintf("Prompts are deleted permanently after %d days in the trash.",
+		int(trash.DefaultRetention.Hours()/24)))
+	note.Importance = widget.LowImportance
+
+	content := container.NewBorder(
+		nil,
+		container.NewVBox(note, container.NewHBox(emptyBtn, layout.NewSpacer(), restoreBtn, deleteBtn)),
+		nil, nil,
+		container.NewStack(list, container.NewCenter(empty)),
+	)
+
+	reload()
+	d := dialog.NewCustom("Trash", "Close", content, window)
+	d.Resize(fyne.NewSize(600, 450))
+	d.Show()
+}
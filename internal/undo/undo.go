@@ -0,0 +1,95 @@
+package undo
+
+import (
+	"errors"
+	"sync"
+)
+
+// DefaultLimit is the number of actions kept by a stack created with a
+// limit below 1
+const DefaultLimit = 50
+
+// ErrEmpty is returned by Undo when there is nothing to undo
+var ErrEmpty = errors.New("nothing to undo")
+
+// Action is a completed change that can be reverted
+type Action struct {
+	Label string       // e.g. "Delete Code Review", shown as "Undo Delete Code Review"
+	Undo  func() error // reverts the change
+}
+
+// Stack holds recent actions, most recent last. It is safe for concurrent
+// use.
+type Stack struct {
+	mu       sync.Mutex
+	actions  []Action
+	limit    int
+	onChange func()
+}
+
+// New creates a stack that keeps at most limit actions
+func New(limit int) *Stack {
+	if limit < 1 {
+		limit = DefaultLimit
+	}
+	return &Stack{limit: limit}
+}
+
+// SetOnChange sets a callback run after the stack changes
+func (s *Stack) SetOnChange(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = fn
+}
+
+// Push records an action, dropping the oldest if the stack is full
+func (s *Stack) Push(a Action) {
+	s.mu.Lock()
+	s.actions = append(s.actions, a)
+	if len(s.actions) > s.limit {
+		s.actions = s.actions[len(s.actions)-s.limit:]
+	}
+	fn := s.onChange
+	s.mu.Unlock()
+
+	if fn != nil {
+		fn()
+	}
+}
+
+// Peek returns the most recent action without removing it
+func (s *Stack) Peek() (Action, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.actions) == 0 {
+		return Action{}, false
+	}
+	return s.actions[len(s.actions)-1], true
+}
+
+// Len returns the number of actions that can be undone
+func (s *Stack) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.actions)
+}
+
+// Undo reverts the most recent action and returns it. The action is removed
+// even if reverting fails, so a broken action can't block older ones.
+func (s *Stack) Undo() (Action, error) {
+	s.mu.Lock()
+	if len(s.actions) == 0 {
+		s.mu.Unlock()
+		return Action{}, ErrEmpty
+	}
+	a := s.actions[len(s.actions)-1]
+	s.actions = s.actions[:len(s.actions)-1]
+	fn := s.onChange
+	s.mu.Unlock()
+
+	err := a.Undo()
+	if fn != nil {
+		fn()
+	}
+	return a, err
+}
@@ -0,0 +1,58 @@
+package undo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStack(t *testing.T) {
+	s := New(2)
+	var log []string
+	push := func(label string, err error) {
+		s.Push(Action{Label: label, Undo: func() error {
+			log = append(log, label)
+			return err
+		}})
+	}
+
+	if _, err := s.Undo(); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Undo() on empty stack error = %v, want ErrEmpty", err)
+	}
+
+	push("first", nil)
+	push("second", errors.New("failed"))
+	push("third", nil)
+	if s.Len() != 2 {
+		t.Errorf("Len() = %d, want limit 2", s.Len())
+	}
+	if a, ok := s.Peek(); !ok || a.Label != "third" {
+		t.Errorf("Peek() = %q, %v", a.Label, ok)
+	}
+
+	if a, err := s.Undo(); err != nil || a.Label != "third" {
+		t.Errorf("Undo() = %q, %v", a.Label, err)
+	}
+	// A failed undo is still removed
+	if a, err := s.Undo(); err == nil || a.Label != "second" {
+		t.Errorf("Undo() = %q, %v, want error", a.Label, err)
+	}
+	if _, err := s.Undo(); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Undo() error = %v, want ErrEmpty after oldest dropped", err)
+	}
+
+	if len(log) != 2 || log[0] != "third" || log[1] != "second" {
+		t.Errorf("undo order = %v", log)
+	}
+}
+
+func TestStack_OnChange(t *testing.T) {
+	s := New(0)
+	changes := 0
+	s.SetOnChange(func() { changes++ })
+
+	s.Push(Action{Label: "a", Undo: func() error { return nil }})
+	s.Undo()
+	if changes != 2 {
+		t.Errorf("onChange called %d times, want 2", changes)
+	}
+}
@@ -0,0 +1,66 @@
+package watcher
+
+import (
+	"path/filepath"
+)
+
+// DefaultIgnorePatterns match editor files that pass the markdown filter.
+// Swap, backup and temp files such as ".a.md.swp", "a.md~" and
+// "a.md___jb_tmp___" don't end in .md, so the filter already drops them;
+// emacs lock files keep the name and only add a prefix. Patterns are
+// matched against the file's base name.
+var DefaultIgnorePatterns = []string{
+	".#*", // emacs lock files
+}
+
+// isIgnored reports whether path matches any of the ignore patterns
+func isIgnored(path string, patterns []string) bool {
+	name := filepath.Base(path)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// coalesce reduces a burst of events to one net event per path, in order of
+// first appearance. Editors that save atomically rename the original away
+// and move a temp file into place, which arrives as Rename followed by
+// Create on the same path; that is reported as a single Write. Files that
+// are created and removed within the burst produce no event.
+func coalesce(events []Event) []Event {
+	type history struct {
+		first Operation
+		last  Operation
+	}
+
+	var order []string
+	paths := make(map[string]*history)
+	for _, ev := range events {
+		h, ok := paths[ev.Path]
+		if !ok {
+			h = &history{first: ev.Op}
+			paths[ev.Path] = h
+			order = append(order, ev.Path)
+		}
+		h.last = ev.Op
+	}
+
+	var result []Event
+	for _, path := range order {
+		h := paths[path]
+		existedBefore := h.first != Create
+		existsAfter := h.last == Create || h.last == Write
+
+		switch {
+		case existedBefore && existsAfter:
+			result = append(result, Event{Path: path, Op: Write})
+		case !existedBefore && existsAfter:
+			result = append(result, Event{Path: path, Op: Create})
+		case existedBefore && !existsAfter:
+			result = append(result, Event{Path: path, Op: Remove})
+		}
+	}
+	return result
+}
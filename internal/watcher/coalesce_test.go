@@ -0,0 +1,165 @@
+package watcher
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCoalesce(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []Event
+		want   []Event
+	}{
+		{
+			name:   "single write",
+			events: []Event{{"a.md", Write}},
+			want:   []Event{{"a.md", Write}},
+		},
+		{
+			name:   "repeated writes",
+			events: []Event{{"a.md", Write}, {"a.md", Write}, {"a.md", Write}},
+			want:   []Event{{"a.md", Write}},
+		},
+		{
+			name:   "create then write",
+			events: []Event{{"a.md", Create}, {"a.md", Write}},
+			want:   []Event{{"a.md", Create}},
+		},
+		{
+			name:   "rename over existing",
+			events: []Event{{"a.md", Rename}, {"a.md", Create}},
+			want:   []Event{{"a.md", Write}},
+		},
+		{
+			name:   "remove then recreate",
+			events: []Event{{"a.md", Remove}, {"a.md", Create}, {"a.md", Write}},
+			want:   []Event{{"a.md", Write}},
+		},
+		{
+			name:   "temp file created and removed",
+			events: []Event{{"a.md.tmp", Create}, {"a.md.tmp", Write}, {"a.md.tmp", Remove}},
+			want:   nil,
+		},
+		{
+			name:   "removed",
+			events: []Event{{"a.md", Write}, {"a.md", Remove}},
+			want:   []Event{{"a.md", Remove}},
+		},
+		{
+			name:   "renamed away",
+			events: []Event{{"a.md", Rename}, {"b.md", Create}},
+			want:   []Event{{"a.md", Remove}, {"b.md", Create}},
+		},
+		{
+			name: "vim save with writebackup",
+			events: []Event{
+				{"4913", Create}, {"4913", Remove},
+				{"a.md", Rename}, {"a.md~", Create},
+				{"a.md", Create}, {"a.md", Write},
+				{"a.md~", Remove},
+			},
+			want: []Event{{"a.md", Write}},
+		},
+		{
+			name: "jetbrains safe write",
+			events: []Event{
+				{"a.md___jb_tmp___", Create}, {"a.md___jb_tmp___", Write},
+				{"a.md", Rename}, {"a.md___jb_old___", Create},
+				{"a.md___jb_tmp___", Rename}, {"a.md", Create},
+				{"a.md___jb_old___", Remove},
+			},
+			want: []Event{{"a.md", Write}},
+		},
+		{
+			name: "emacs save",
+			events: []Event{
+				{".#a.md", Create},
+				{"a.md", Rename}, {"a.md~", Create},
+				{"a.md", Create}, {"a.md", Write},
+				{".#a.md", Remove},
+			},
+			want: []Event{{"a.md", Write}, {"a.md~", Create}},
+		},
+		{
+			name: "atomic rename from temp file",
+			events: []Event{
+				{".a.md.tmp", Create}, {".a.md.tmp", Write},
+				{".a.md.tmp", Rename}, {"a.md", Create},
+			},
+			want: []Event{{"a.md", Create}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := coalesce(tt.events)
+			if len(got) != len(tt.want) {
+				t.Fatalf("coalesce() = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("coalesce()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIsIgnored(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/prompts/a.md", false},
+		{"/prompts/.#a.md", true},
+		{"/prompts/notes.md", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := isIgnored(tt.path, DefaultIgnorePatterns); got != tt.want {
+				t.Errorf("isIgnored(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	if !isIgnored("/prompts/draft-a.md", []string{"draft-*"}) {
+		t.Error("custom pattern draft-* should match draft-a.md")
+	}
+}
+
+func TestHandle_EditorFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"a.md", true},
+		{".#a.md", false},    // emacs lock, ignored by pattern
+		{".a.md.swp", false}, // vim swap, dropped by the markdown filter
+		{"a.md~", false},
+		{"#a.md#", false},
+		{"a.md___jb_tmp___", false},
+		{"4913", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := New(t.TempDir(), func() {})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.Stop()
+			w.SetDebounce(time.Hour)
+			w.handle(Event{Path: filepath.Join(w.dir, tt.name), Op: Create})
+
+			w.mu.Lock()
+			got := len(w.pending) > 0
+			w.mu.Unlock()
+			if got != tt.want {
+				t.Errorf("handle(%q) queued = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package watcher
 
 import (
+	"errors"
+	"maps"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -9,6 +12,9 @@ import (
 	"github.com/fsnotify/fsnotify"
 )
 
+// ErrDirRemoved is reported when the watched directory disappears
+var ErrDirRemoved = errors.New("watched directory was removed")
+
 // Event represents a file system event
 type Event struct {
 	Path string
@@ -25,29 +31,78 @@ const (
 	Rename
 )
 
+// Mode selects how the watcher detects changes
+type Mode int
+
+const (
+	// ModeNotify uses native file system notifications
+	ModeNotify Mode = iota
+	// ModePoll scans the directory for mtime and size changes at an interval.
+	// Use it on network and FUSE mounts where notifications are unreliable.
+	ModePoll
+)
+
+// State represents whether the watcher is currently able to watch
+type State int
+
+const (
+	StateWatching State = iota
+	StatePaused
+)
+
+// Status describes the watcher's health. Err is set when the watcher is
+// paused, or when a non-fatal error occurred while watching.
+type Status struct {
+	State State
+	Err   error
+}
+
 // Watcher watches a directory for file changes
 type Watcher struct {
-	fsWatcher *fsnotify.Watcher
-	dir       string
-	onChange  func()
-	done      chan struct{}
-	mu        sync.Mutex
-	debounce  time.Duration
+	fsWatcher    *fsnotify.Watcher
+	dir          string
+	onChange     func()
+	onEvents     func([]Event)
+	onStatus     func(Status)
+	done         chan struct{}
+	mu           sync.Mutex
+	callMu       sync.Mutex
+	timer        *time.Timer
+	debounce     time.Duration
+	mode         Mode
+	pollInterval time.Duration
+	state        State
+	ignore       []string
+	filter       func(path string) bool
+	pending      []Event
+	forced       bool
+	settles      int
+	dirInfo      os.FileInfo
+	subdirs      bool
+	watchedDirs  map[string]bool
+	addWatch     func(path string) error // adds the directory to fsWatcher
 }
 
+// maxSettles bounds how often a batch is postponed while files are still
+// being written
+const maxSettles = 10
+
+// maxRetryInterval caps the backoff between attempts to watch a directory
+// that came back
+const maxRetryInterval = 30 * time.Second
+
 // New creates a new file watcher for the given directory
 func New(dir string, onChange func()) (*Watcher, error) {
-	fsWatcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return nil, err
-	}
-
 	w := &Watcher{
-		fsWatcher: fsWatcher,
-		dir:       dir,
-		onChange:  onChange,
-		done:      make(chan struct{}),
-		debounce:  100 * time.Millisecond,
+		dir:          dir,
+		onChange:     onChange,
+		done:         make(chan struct{}),
+		debounce:     100 * time.Millisecond,
+		mode:         ModeNotify,
+		pollInterval: 2 * time.Second,
+		ignore:       DefaultIgnorePatterns,
+		filter:       isMarkdownFile,
+		watchedDirs:  make(map[string]bool),
 	}
 
 	return w, nil
@@ -55,9 +110,35 @@ func New(dir string, onChange func()) (*Watcher, error) {
 
 // Start begins watching the directory
 func (w *Watcher) Start() error {
-	if err := w.fsWatcher.Add(w.dir); err != nil {
+	info, err := os.Stat(w.dir)
+	if err != nil {
+		return err
+	}
+	w.dirInfo = info
+
+	if w.mode == ModePoll {
+		snapshot, err := scan(w.dir, w.subdirs)
+		if err != nil {
+			return err
+		}
+		go w.poll(snapshot)
+		return nil
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	w.fsWatcher = fsWatcher
+	if w.addWatch == nil {
+		w.addWatch = fsWatcher.Add
+	}
+	if err := w.addWatch(w.dir); err != nil {
+		fsWatcher.Close()
+		w.fsWatcher = nil
 		return err
 	}
+	w.addSubdirs()
 
 	go w.watch()
 	return nil
@@ -66,13 +147,20 @@ func (w *Watcher) Start() error {
 // Stop stops watching the directory
 func (w *Watcher) Stop() error {
 	close(w.done)
-	return w.fsWatcher.Close()
+
+	w.mu.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+
+	if w.fsWatcher != nil {
+		return w.fsWatcher.Close()
+	}
+	return nil
 }
 
 func (w *Watcher) watch() {
-	var timer *time.Timer
-	var timerMu sync.Mutex
-
 	for {
 		select {
 		case <-w.done:
@@ -82,34 +170,404 @@ func (w *Watcher) watch() {
 				return
 			}
 
-			// Only watch markdown files
-			if !isMarkdownFile(event.Name) {
+			// The directory itself went away; wait for it to come back
+			if filepath.Clean(event.Name) == filepath.Clean(w.dir) && event.Has(fsnotify.Remove|fsnotify.Rename) {
+				if !w.recoverDir() {
+					return
+				}
+				continue
+			}
+
+			if w.subdirEvent(event) {
 				continue
 			}
 
-			// Debounce events
-			timerMu.Lock()
-			if timer != nil {
-				timer.Stop()
+			if ev, ok := convertEvent(event); ok {
+				w.handle(ev)
 			}
-			timer = time.AfterFunc(w.debounce, func() {
-				w.mu.Lock()
-				defer w.mu.Unlock()
-				if w.onChange != nil {
-					w.onChange()
-				}
-			})
-			timerMu.Unlock()
 
-		case _, ok := <-w.fsWatcher.Errors:
+		case err, ok := <-w.fsWatcher.Errors:
 			if !ok {
 				return
 			}
-			// Log errors but continue watching
+			// Queue overflow means events were lost, so force a reload
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				w.force()
+			}
+			w.setStatus(Status{State: StateWatching, Err: err})
+		}
+	}
+}
+
+// rewatch replaces the directory watch, picking up a new inode if the
+// directory was replaced
+func (w *Watcher) rewatch() error {
+	info, err := os.Stat(w.dir)
+	if err != nil {
+		return err
+	}
+	// Removing a watch on a deleted directory fails; that's expected
+	_ = w.fsWatcher.Remove(w.dir)
+	if err := w.addWatch(w.dir); err != nil {
+		return err
+	}
+	w.addSubdirs()
+
+	w.mu.Lock()
+	w.dirInfo = info
+	w.mu.Unlock()
+	return nil
+}
+
+// recoverDir pauses until the removed directory exists again and is watched
+// once more. Watching it can fail, for example while its permissions are
+// still being set, so it is retried with backoff until it succeeds. It
+// returns false if the watcher was stopped first.
+func (w *Watcher) recoverDir() bool {
+	w.setStatus(Status{State: StatePaused, Err: ErrDirRemoved})
+	delay := w.pollInterval
+	for {
+		if !w.waitForDir() {
+			return false
+		}
+		err := w.rewatch()
+		if err == nil {
+			w.setStatus(Status{State: StateWatching})
+			w.force()
+			return true
+		}
+		w.setStatus(Status{State: StatePaused, Err: err})
+
+		select {
+		case <-w.done:
+			return false
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, maxRetryInterval)
+	}
+}
+
+// dirReplaced reports whether the watched path now refers to a different
+// directory than the one being watched
+func (w *Watcher) dirReplaced() bool {
+	info, err := os.Stat(w.dir)
+	if err != nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.dirInfo != nil && !os.SameFile(w.dirInfo, info)
+}
+
+// waitForDir blocks until the watched directory exists again. It returns
+// false if the watcher was stopped while waiting.
+func (w *Watcher) waitForDir() bool {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return false
+		case <-ticker.C:
+			if info, err := os.Stat(w.dir); err == nil && info.IsDir() {
+				return true
+			}
 		}
 	}
 }
 
+func (w *Watcher) poll(snapshot map[string]fileState) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			current, err := scan(w.dir, w.subdirs)
+			if err != nil {
+				if os.IsNotExist(err) {
+					err = ErrDirRemoved
+				}
+				w.setStatus(Status{State: StatePaused, Err: err})
+				continue
+			}
+
+			w.setStatus(Status{State: StateWatching})
+			for _, ev := range diffStates(snapshot, current) {
+				w.handle(ev)
+			}
+			snapshot = current
+		}
+	}
+}
+
+// fileState is the part of a file's metadata compared between polls
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// scan returns the state of the files in dir, and in its immediate
+// subdirectories if subdirs is set
+func scan(dir string, subdirs bool) (map[string]fileState, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]fileState, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			if subdirs && !isHidden(entry.Name()) {
+				// A subdirectory may vanish between the two reads
+				sub, _ := scan(filepath.Join(dir, entry.Name()), false)
+				maps.Copy(states, sub)
+			}
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// Removed between ReadDir and Info
+			continue
+		}
+		states[filepath.Join(dir, entry.Name())] = fileState{
+			modTime: info.ModTime(),
+			size:    info.Size(),
+		}
+	}
+	return states, nil
+}
+
+// diffStates returns the events that turn old into current
+func diffStates(old, current map[string]fileState) []Event {
+	var events []Event
+	for path, state := range current {
+		prev, ok := old[path]
+		switch {
+		case !ok:
+			events = append(events, Event{Path: path, Op: Create})
+		case prev != state:
+			events = append(events, Event{Path: path, Op: Write})
+		}
+	}
+	for path := range old {
+		if _, ok := current[path]; !ok {
+			events = append(events, Event{Path: path, Op: Remove})
+		}
+	}
+	return events
+}
+
+func convertEvent(event fsnotify.Event) (Event, bool) {
+	ev := Event{Path: event.Name}
+	switch {
+	case event.Has(fsnotify.Create):
+		ev.Op = Create
+	case event.Has(fsnotify.Write):
+		ev.Op = Write
+	case event.Has(fsnotify.Remove):
+		ev.Op = Remove
+	case event.Has(fsnotify.Rename):
+		ev.Op = Rename
+	default:
+		// Permission changes don't affect prompt content
+		return ev, false
+	}
+	return ev, true
+}
+
+// handle filters an event and queues it for the next debounced batch
+func (w *Watcher) handle(ev Event) {
+	// Only watch matching files, skipping editor temp files
+	w.mu.Lock()
+	if !w.filter(ev.Path) {
+		w.mu.Unlock()
+		return
+	}
+	ignored := isIgnored(ev.Path, w.ignore)
+	if !ignored {
+		w.pending = append(w.pending, ev)
+	}
+	w.mu.Unlock()
+
+	if !ignored {
+		w.trigger()
+	}
+}
+
+// force schedules onChange even if no file events are pending
+func (w *Watcher) force() {
+	w.mu.Lock()
+	w.forced = true
+	w.mu.Unlock()
+	w.trigger()
+}
+
+// trigger schedules a flush once events have been quiet for the debounce period
+func (w *Watcher) trigger() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.settles = 0
+	w.timer = time.AfterFunc(w.debounce, w.flush)
+}
+
+// flush coalesces the pending events and calls onChange if anything changed.
+// If a file in the batch is still missing or being written, the flush is
+// postponed so a reload never sees a half-saved file.
+func (w *Watcher) flush() {
+	select {
+	case <-w.done:
+		return
+	default:
+	}
+
+	w.mu.Lock()
+	events := coalesce(w.pending)
+	if !w.settled(events) && w.settles < maxSettles {
+		w.settles++
+		w.timer = time.AfterFunc(w.debounce, w.flush)
+		w.mu.Unlock()
+		return
+	}
+	forced := w.forced
+	w.pending = nil
+	w.forced = false
+	w.mu.Unlock()
+
+	// A directory swapped out from under the watch needs a fresh watch
+	if w.fsWatcher != nil && w.dirReplaced() {
+		if err := w.rewatch(); err == nil {
+			forced = true
+		}
+	}
+
+	if len(events) == 0 && !forced {
+		return
+	}
+
+	w.mu.Lock()
+	onEvents := w.onEvents
+	w.mu.Unlock()
+
+	w.callMu.Lock()
+	defer w.callMu.Unlock()
+	if onEvents != nil && len(events) > 0 {
+		onEvents(events)
+	}
+	if w.onChange != nil {
+		w.onChange()
+	}
+}
+
+// settled reports whether every created or written file in events exists
+// and has not been modified within the debounce period
+func (w *Watcher) settled(events []Event) bool {
+	for _, ev := range events {
+		if ev.Op != Create && ev.Op != Write {
+			continue
+		}
+		info, err := os.Stat(ev.Path)
+		if err != nil {
+			return false
+		}
+		if time.Since(info.ModTime()) < w.debounce {
+			return false
+		}
+	}
+	return true
+}
+
+// setStatus reports status changes and errors to the status handler
+func (w *Watcher) setStatus(status Status) {
+	w.mu.Lock()
+	changed := status.State != w.state
+	w.state = status.State
+	handler := w.onStatus
+	w.mu.Unlock()
+
+	// Repeated pause reports are dropped; non-fatal errors always get through
+	if handler != nil && (changed || (status.State == StateWatching && status.Err != nil)) {
+		handler(status)
+	}
+}
+
+// addSubdirs watches the immediate subdirectories when enabled. Failures are
+// reported but don't stop the main directory being watched.
+func (w *Watcher) addSubdirs() {
+	if !w.subdirs {
+		return
+	}
+
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return
+	}
+	dirs := make(map[string]bool)
+	for _, entry := range entries {
+		if !entry.IsDir() || isHidden(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(w.dir, entry.Name())
+		if err := w.fsWatcher.Add(path); err != nil {
+			w.setStatus(Status{State: StateWatching, Err: err})
+			continue
+		}
+		dirs[path] = true
+	}
+
+	w.mu.Lock()
+	w.watchedDirs = dirs
+	w.mu.Unlock()
+}
+
+// subdirEvent handles subdirectories being created, removed or renamed and
+// reports whether event was for one. A new subdirectory is watched, and
+// either change forces a reload since files move with the directory.
+func (w *Watcher) subdirEvent(event fsnotify.Event) bool {
+	if !w.subdirs || filepath.Dir(filepath.Clean(event.Name)) != filepath.Clean(w.dir) {
+		return false
+	}
+	path := filepath.Clean(event.Name)
+
+	if event.Has(fsnotify.Create) {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() || isHidden(info.Name()) {
+			return false
+		}
+		if err := w.fsWatcher.Add(path); err != nil {
+			w.setStatus(Status{State: StateWatching, Err: err})
+		}
+		w.mu.Lock()
+		w.watchedDirs[path] = true
+		w.mu.Unlock()
+		w.force()
+		return true
+	}
+
+	if event.Has(fsnotify.Remove | fsnotify.Rename) {
+		w.mu.Lock()
+		watched := w.watchedDirs[path]
+		delete(w.watchedDirs, path)
+		w.mu.Unlock()
+		if watched {
+			w.force()
+		}
+		return watched
+	}
+	return false
+}
+
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
 func isMarkdownFile(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
 	return ext == ".md"
@@ -119,3 +577,57 @@ func isMarkdownFile(path string) bool {
 func (w *Watcher) SetDebounce(d time.Duration) {
 	w.debounce = d
 }
+
+// SetIgnorePatterns replaces the base-name glob patterns for files whose
+// events are ignored. DefaultIgnorePatterns is used until this is called.
+func (w *Watcher) SetIgnorePatterns(patterns []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.ignore = patterns
+}
+
+// SetFilter sets which files are watched. By default only markdown files
+// are; a filter can select other files such as a config file.
+func (w *Watcher) SetFilter(filter func(path string) bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.filter = filter
+}
+
+// SetSubdirs also watches the directory's immediate subdirectories, skipping
+// hidden ones. It must be called before Start.
+func (w *Watcher) SetSubdirs(subdirs bool) {
+	w.subdirs = subdirs
+}
+
+// SetPolling switches the watcher to polling mode with the given interval.
+// It must be called before Start.
+func (w *Watcher) SetPolling(interval time.Duration) {
+	w.mode = ModePoll
+	if interval > 0 {
+		w.pollInterval = interval
+	}
+}
+
+// SetStatusHandler sets a callback for state changes and watch errors. It is
+// called from the watcher's goroutine.
+func (w *Watcher) SetStatusHandler(fn func(Status)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onStatus = fn
+}
+
+// SetEventHandler sets a callback for the coalesced events of each change,
+// called just before onChange. It is called from the watcher's goroutine.
+func (w *Watcher) SetEventHandler(fn func([]Event)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onEvents = fn
+}
+
+// State returns the watcher's current state
+func (w *Watcher) State() State {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.state
+}
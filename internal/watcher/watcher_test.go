@@ -0,0 +1,544 @@
+package watcher
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it returns true or the timeout expires
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return cond()
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiffStates(t *testing.T) {
+	now := time.Now()
+	old := map[string]fileState{
+		"same.md":    {modTime: now, size: 10},
+		"changed.md": {modTime: now, size: 10},
+		"grown.md":   {modTime: now, size: 10},
+		"removed.md": {modTime: now, size: 10},
+	}
+	current := map[string]fileState{
+		"same.md":    {modTime: now, size: 10},
+		"changed.md": {modTime: now.Add(time.Second), size: 10},
+		"grown.md":   {modTime: now, size: 20},
+		"created.md": {modTime: now, size: 5},
+	}
+
+	events := diffStates(old, current)
+	sort.Slice(events, func(i, j int) bool { return events[i].Path < events[j].Path })
+
+	want := []Event{
+		{Path: "changed.md", Op: Write},
+		{Path: "created.md", Op: Create},
+		{Path: "grown.md", Op: Write},
+		{Path: "removed.md", Op: Remove},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("diffStates() = %v, want %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event[%d] = %v, want %v", i, events[i], want[i])
+		}
+	}
+}
+
+func TestPolling_DetectsChanges(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.md"), "a")
+
+	var changes atomic.Int32
+	w, err := New(dir, func() { changes.Add(1) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetDebounce(10 * time.Millisecond)
+	w.SetPolling(20 * time.Millisecond)
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer w.Stop()
+
+	writeFile(t, filepath.Join(dir, "b.md"), "b")
+	if !waitFor(t, 2*time.Second, func() bool { return changes.Load() >= 1 }) {
+		t.Fatal("create not detected")
+	}
+
+	before := changes.Load()
+	writeFile(t, filepath.Join(dir, "a.md"), "longer content")
+	if !waitFor(t, 2*time.Second, func() bool { return changes.Load() > before }) {
+		t.Fatal("write not detected")
+	}
+
+	before = changes.Load()
+	if err := os.Remove(filepath.Join(dir, "b.md")); err != nil {
+		t.Fatal(err)
+	}
+	if !waitFor(t, 2*time.Second, func() bool { return changes.Load() > before }) {
+		t.Fatal("remove not detected")
+	}
+}
+
+func TestPolling_IgnoresNonMarkdown(t *testing.T) {
+	dir := t.TempDir()
+
+	var changes atomic.Int32
+	w, _ := New(dir, func() { changes.Add(1) })
+	w.SetDebounce(10 * time.Millisecond)
+	w.SetPolling(20 * time.Millisecond)
+	if err := w.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	writeFile(t, filepath.Join(dir, "notes.txt"), "text")
+	time.Sleep(150 * time.Millisecond)
+
+	if n := changes.Load(); n != 0 {
+		t.Errorf("onChange called %d times for non-markdown file, want 0", n)
+	}
+}
+
+func testDirRecovery(t *testing.T, polling bool) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "prompts")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes atomic.Int32
+	var paused, resumed atomic.Bool
+	w, _ := New(dir, func() { changes.Add(1) })
+	w.SetDebounce(10 * time.Millisecond)
+	if polling {
+		w.SetPolling(20 * time.Millisecond)
+	} else {
+		w.pollInterval = 20 * time.Millisecond
+	}
+	w.SetStatusHandler(func(s Status) {
+		switch s.State {
+		case StatePaused:
+			paused.Store(true)
+		case StateWatching:
+			if paused.Load() && s.Err == nil {
+				resumed.Store(true)
+			}
+		}
+	})
+	if err := w.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if !waitFor(t, 2*time.Second, paused.Load) {
+		t.Fatal("watcher did not report paused state")
+	}
+	if w.State() != StatePaused {
+		t.Errorf("State() = %v, want StatePaused", w.State())
+	}
+
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !waitFor(t, 2*time.Second, resumed.Load) {
+		t.Fatal("watcher did not resume after directory was recreated")
+	}
+
+	before := changes.Load()
+	writeFile(t, filepath.Join(dir, "new.md"), "new")
+	if !waitFor(t, 2*time.Second, func() bool { return changes.Load() > before }) {
+		t.Fatal("change in recreated directory not detected")
+	}
+}
+
+func TestPolling_RecoversDeletedDir(t *testing.T) {
+	testDirRecovery(t, true)
+}
+
+func TestNotify_RecoversDeletedDir(t *testing.T) {
+	testDirRecovery(t, false)
+}
+
+func TestNotify_RetriesRewatch(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "prompts")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes, adds atomic.Int32
+	w, _ := New(dir, func() { changes.Add(1) })
+	w.SetDebounce(10 * time.Millisecond)
+	w.pollInterval = 20 * time.Millisecond
+	w.addWatch = func(path string) error {
+		// The first add is Start's; fail the first attempt after recovery
+		if adds.Add(1) == 2 {
+			return errors.New("watch failed")
+		}
+		return w.fsWatcher.Add(path)
+	}
+	if err := w.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if !waitFor(t, 2*time.Second, func() bool { return w.State() == StatePaused }) {
+		t.Fatal("watcher did not pause")
+	}
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !waitFor(t, 2*time.Second, func() bool { return adds.Load() >= 3 && w.State() == StateWatching }) {
+		t.Fatalf("watcher did not resume after a failed rewatch (%d attempts)", adds.Load())
+	}
+
+	before := changes.Load()
+	writeFile(t, filepath.Join(dir, "new.md"), "new")
+	if !waitFor(t, 2*time.Second, func() bool { return changes.Load() > before }) {
+		t.Fatal("change in recreated directory not detected")
+	}
+}
+
+func TestStart_MissingDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+
+	for _, polling := range []bool{false, true} {
+		w, _ := New(dir, nil)
+		if polling {
+			w.SetPolling(time.Second)
+		}
+		if err := w.Start(); err == nil {
+			w.Stop()
+			t.Errorf("Start() polling=%v on missing dir should return error", polling)
+		}
+	}
+}
+
+// editorSaves simulate how each editor writes path with new content
+var editorSaves = map[string]func(t *testing.T, path, content string){
+	"in place": func(t *testing.T, path, content string) {
+		writeFile(t, path, content)
+	},
+	"vim": func(t *testing.T, path, content string) {
+		dir := filepath.Dir(path)
+		probe := filepath.Join(dir, "4913")
+		writeFile(t, probe, "")
+		os.Remove(probe)
+		writeFile(t, filepath.Join(dir, "."+filepath.Base(path)+".swp"), "swap")
+		backup := path + "~"
+		if err := os.Rename(path, backup); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Write in two chunks, as a large buffer flush would
+		half := len(content) / 2
+		f.WriteString(content[:half])
+		time.Sleep(20 * time.Millisecond)
+		f.WriteString(content[half:])
+		f.Close()
+		os.Remove(backup)
+	},
+	"jetbrains": func(t *testing.T, path, content string) {
+		tmp := path + "___jb_tmp___"
+		old := path + "___jb_old___"
+		writeFile(t, tmp, content)
+		if err := os.Rename(path, old); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatal(err)
+		}
+		os.Remove(old)
+	},
+	"emacs": func(t *testing.T, path, content string) {
+		dir := filepath.Dir(path)
+		lock := filepath.Join(dir, ".#"+filepath.Base(path))
+		if err := os.Symlink("user@host.1234", lock); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(path, path+"~"); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, path, content)
+		os.Remove(lock)
+	},
+	"atomic rename": func(t *testing.T, path, content string) {
+		tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+		writeFile(t, tmp, content)
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatal(err)
+		}
+	},
+}
+
+func TestNotify_EditorSaveSequences(t *testing.T) {
+	for name, save := range editorSaves {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "prompt.md")
+			writeFile(t, path, "original")
+
+			final := "---\ntitle: Saved by " + name + "\n---\n\n" + strings.Repeat("content ", 200)
+
+			var mu sync.Mutex
+			var seen []string
+			w, _ := New(dir, func() {
+				data, err := os.ReadFile(path)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					seen = append(seen, "error: "+err.Error())
+					return
+				}
+				seen = append(seen, string(data))
+			})
+			w.SetDebounce(50 * time.Millisecond)
+			if err := w.Start(); err != nil {
+				t.Fatal(err)
+			}
+			defer w.Stop()
+
+			save(t, path, final)
+
+			waitFor(t, 2*time.Second, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(seen) > 0
+			})
+			// Allow any straggling reloads to arrive
+			time.Sleep(300 * time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(seen) != 1 {
+				t.Fatalf("onChange called %d times, want 1", len(seen))
+			}
+			if seen[0] != final {
+				t.Errorf("onChange saw incomplete file: %.60q", seen[0])
+			}
+		})
+	}
+}
+
+func TestNotify_IgnoresTempFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	var changes atomic.Int32
+	w, _ := New(dir, func() { changes.Add(1) })
+	w.SetDebounce(20 * time.Millisecond)
+	w.SetIgnorePatterns(append(DefaultIgnorePatterns, "draft-*"))
+	if err := w.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	writeFile(t, filepath.Join(dir, ".#lock.md"), "lock")
+	writeFile(t, filepath.Join(dir, "draft-idea.md"), "draft")
+	time.Sleep(200 * time.Millisecond)
+
+	if n := changes.Load(); n != 0 {
+		t.Errorf("onChange called %d times for ignored files, want 0", n)
+	}
+}
+
+func TestNotify_DirectoryReplaced(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "prompts")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes atomic.Int32
+	w, _ := New(dir, func() { changes.Add(1) })
+	w.SetDebounce(20 * time.Millisecond)
+	w.pollInterval = 20 * time.Millisecond
+	if err := w.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// Swap in a new directory the way sync tools and checkouts do
+	staged := filepath.Join(parent, "prompts.new")
+	if err := os.Mkdir(staged, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(staged, "a.md"), "a")
+	if err := os.Rename(dir, filepath.Join(parent, "prompts.old")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(staged, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if !waitFor(t, 2*time.Second, func() bool { return changes.Load() >= 1 }) {
+		t.Fatal("directory replacement not detected")
+	}
+
+	// Changes in the new directory must be seen, not the old inode
+	before := changes.Load()
+	writeFile(t, filepath.Join(dir, "b.md"), "b")
+	if !waitFor(t, 2*time.Second, func() bool { return changes.Load() > before }) {
+		t.Fatal("change in replaced directory not detected")
+	}
+
+	before = changes.Load()
+	writeFile(t, filepath.Join(parent, "prompts.old", "c.md"), "c")
+	time.Sleep(200 * time.Millisecond)
+	if changes.Load() != before {
+		t.Error("change in old directory should not be reported")
+	}
+}
+
+func TestSetFilter(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.cue")
+	writeFile(t, configPath, "theme: \"light\"")
+
+	var changes atomic.Int32
+	w, _ := New(dir, func() { changes.Add(1) })
+	w.SetDebounce(20 * time.Millisecond)
+	w.SetFilter(func(path string) bool {
+		return filepath.Base(path) == "config.cue"
+	})
+	if err := w.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	writeFile(t, filepath.Join(dir, "prompt.md"), "not watched")
+	time.Sleep(150 * time.Millisecond)
+	if n := changes.Load(); n != 0 {
+		t.Fatalf("onChange called %d times for filtered file, want 0", n)
+	}
+
+	writeFile(t, configPath, "theme: \"dark\"")
+	if !waitFor(t, 2*time.Second, func() bool { return changes.Load() == 1 }) {
+		t.Errorf("onChange called %d times for config change, want 1", changes.Load())
+	}
+}
+
+func testSubdirs(t *testing.T, polling bool) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "coding")
+	if err := os.Mkdir(existing, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes atomic.Int32
+	w, _ := New(dir, func() { changes.Add(1) })
+	w.SetDebounce(10 * time.Millisecond)
+	w.SetSubdirs(true)
+	if polling {
+		w.SetPolling(20 * time.Millisecond)
+	}
+	if err := w.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	writeFile(t, filepath.Join(existing, "a.md"), "a")
+	if !waitFor(t, 2*time.Second, func() bool { return changes.Load() >= 1 }) {
+		t.Fatal("change in existing subdirectory not detected")
+	}
+
+	// Files in a subdirectory created after Start are seen too
+	created := filepath.Join(dir, "writing")
+	if err := os.Mkdir(created, 0755); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	before := changes.Load()
+	writeFile(t, filepath.Join(created, "b.md"), "b")
+	if !waitFor(t, 2*time.Second, func() bool { return changes.Load() > before }) {
+		t.Fatal("change in new subdirectory not detected")
+	}
+
+	// Hidden directories such as .git are skipped
+	hidden := filepath.Join(dir, ".git")
+	if err := os.Mkdir(hidden, 0755); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	before = changes.Load()
+	writeFile(t, filepath.Join(hidden, "c.md"), "c")
+	time.Sleep(150 * time.Millisecond)
+	if n := changes.Load(); n != before {
+		t.Errorf("onChange called %d times for hidden directory, want 0", n-before)
+	}
+}
+
+func TestPolling_Subdirs(t *testing.T) {
+	testSubdirs(t, true)
+}
+
+func TestNotify_Subdirs(t *testing.T) {
+	testSubdirs(t, false)
+}
+
+func TestSetEventHandler(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "prompt.md")
+
+	var mu sync.Mutex
+	var got []Event
+	w, _ := New(dir, nil)
+	w.SetDebounce(20 * time.Millisecond)
+	w.SetEventHandler(func(events []Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, events...)
+	})
+	if err := w.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	writeFile(t, path, "content")
+	found := waitFor(t, 2*time.Second, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, ev := range got {
+			if ev.Path == path && (ev.Op == Create || ev.Op == Write) {
+				return true
+			}
+		}
+		return false
+	})
+	if !found {
+		mu.Lock()
+		defer mu.Unlock()
+		t.Errorf("events = %+v, want a create or write of %s", got, path)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build nowindowpos
+
+package window
+
+import (
+	"fyne.io/fyne/v2"
+
+	"github.com/grantcarthew/cuecard/internal/state"
+)
+
+// Position reports that the window's position is unknown
+func Position(fyne.Window) (x, y int, ok bool) {
+	return 0, 0, false
+}
+
+// Move does nothing and reports false
+func Move(fyne.Window, int, int) bool {
+	return false
+}
+
+// Screens returns no screens, so saved geometry isn't clamped
+func Screens() []state.Rect {
+	return nil
+}
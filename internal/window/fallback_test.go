@@ -0,0 +1,27 @@
+//go:build nowindowpos
+
+package window
+
+import (
+	"testing"
+
+	"github.com/grantcarthew/cuecard/internal/state"
+)
+
+func TestFallback(t *testing.T) {
+	if _, _, ok := Position(nil); ok {
+		t.Error("Position() reported a known position")
+	}
+	if Move(nil, 10, 20) {
+		t.Error("Move() reported moving the window")
+	}
+	if screens := Screens(); screens != nil {
+		t.Errorf("Screens() = %v, want none", screens)
+	}
+
+	// Without screens a saved window is restored as it was
+	w := state.Window{X: 5000, Y: 5000, Width: 800, Height: 600, HasPosition: true}
+	if got := state.Clamp(w, Screens()); got != w {
+		t.Errorf("Clamp() = %+v, want %+v", got, w)
+	}
+}
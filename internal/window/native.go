@@ -0,0 +1,70 @@
+//go:build !nowindowpos
+
+package window
+
+import (
+	"fyne.io/fyne/v2"
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"github.com/grantcarthew/cuecard/internal/state"
+)
+
+// These must be called on the main thread and do nothing on drivers other
+// than Fyne's desktop one.
+
+// contextRunner is implemented by Fyne's desktop windows
+type contextRunner interface {
+	RunWithContext(f func())
+}
+
+// withNativeWindow calls fn with the GLFW window behind w and reports
+// whether it was available
+func withNativeWindow(w fyne.Window, fn func(*glfw.Window)) bool {
+	runner, ok := w.(contextRunner)
+	if !ok {
+		return false
+	}
+
+	called := false
+	runner.RunWithContext(func() {
+		if win := glfw.GetCurrentContext(); win != nil {
+			fn(win)
+			called = true
+		}
+	})
+	return called
+}
+
+// Position returns the window's top-left corner in screen coordinates and
+// whether it is known
+func Position(w fyne.Window) (x, y int, ok bool) {
+	withNativeWindow(w, func(win *glfw.Window) {
+		x, y = win.GetPos()
+		ok = positionKnown(x, y)
+	})
+	return x, y, ok
+}
+
+// Move moves the window's top-left corner to x, y and reports whether it
+// could
+func Move(w fyne.Window, x, y int) bool {
+	return withNativeWindow(w, func(win *glfw.Window) {
+		win.SetPos(x, y)
+	})
+}
+
+// Screens returns the work area of each monitor, primary first
+func Screens() []state.Rect {
+	var screens []state.Rect
+	primary := glfw.GetPrimaryMonitor()
+	for _, m := range glfw.GetMonitors() {
+		x, y, width, height := m.GetWorkarea()
+		rect := state.Rect{X: x, Y: y, Width: width, Height: height}
+		if m == primary {
+			screens = append([]state.Rect{rect}, screens...)
+		} else {
+			screens = append(screens, rect)
+		}
+	}
+	return screens
+}
@@ -0,0 +1,6 @@
+//go:build !wayland
+
+package window
+
+// wayland is false for X11, macOS and Windows builds
+const wayland = false
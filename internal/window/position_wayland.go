@@ -0,0 +1,6 @@
+//go:build wayland
+
+package window
+
+// wayland is set when Fyne is built for Wayland
+const wayland = true
@@ -0,0 +1,13 @@
+// Package window reads and sets where a window sits on screen. Fyne has no
+// API for window position or monitor bounds, so the default build reaches
+// the GLFW window behind Fyne's desktop driver. Building with the
+// nowindowpos tag leaves GLFW out: positions are then unknown, windows open
+// wherever the system puts them and saved sizes are restored unclamped.
+package window
+
+// positionKnown reports whether x, y is a real window position. Wayland
+// doesn't tell clients where their windows are, and GLFW reports 0, 0 for
+// every window there.
+func positionKnown(x, y int) bool {
+	return !wayland || x != 0 || y != 0
+}
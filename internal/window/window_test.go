@@ -0,0 +1,23 @@
+package window
+
+import "testing"
+
+func TestPositionKnown(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y int
+		want bool
+	}{
+		{"origin", 0, 0, !wayland},
+		{"left edge", 0, 120, true},
+		{"top edge", 80, 0, true},
+		{"second monitor", -1920, 40, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := positionKnown(tt.x, tt.y); got != tt.want {
+				t.Errorf("positionKnown(%d, %d) = %v, want %v", tt.x, tt.y, got, tt.want)
+			}
+		})
+	}
+}